@@ -4,9 +4,12 @@ module github.com/kalexmills/haiku-enforcer
 go 1.16
 
 require (
-	github.com/bwmarrin/discordgo v0.23.2
-	github.com/jonbodner/proteus v0.14.0 // indirect
-	github.com/mattn/go-sqlite3 v1.14.8 // indirect
+	github.com/bwmarrin/discordgo v0.27.1
+	github.com/fsnotify/fsnotify v1.4.9
+	github.com/jonbodner/proteus v0.14.0
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.8
 	github.com/spf13/viper v1.8.1
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/text v0.3.5
 )
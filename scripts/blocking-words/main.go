@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// blocking-words prints the unknown words which most often stopped a message from being accepted as a haiku, across
+// every guild, along with the number of attempts each one blocked. Use it to decide which words to add to the dictionary
+// next.
+func main() {
+	dbPath := flag.String("db", "./haikuDB.sqlite3", "path to the HaikuHammer database")
+	limit := flag.Int("n", 100, "number of words to print")
+	flag.Parse()
+
+	DB, err := sql.Open("sqlite3", *dbPath+"?mode=ro")
+	FatalError(err)
+	defer DB.Close()
+
+	words, err := db.AttemptDAO.TopWordsGlobal(context.Background(), DB, *limit)
+	FatalError(err)
+	for _, word := range words {
+		fmt.Println(word.Word, word.Attempts)
+	}
+}
+
+func FatalError(err error) {
+	if err != nil {
+		fmt.Printf("encountered error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -5,47 +5,89 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 )
 
 //go:embed data/english-syllables.txt
 var syllablesFile string
 
-var syllableCounts map[string][]int
+// Dictionary is an immutable set of words, along with every number of syllables each word can be pronounced with.
+type Dictionary struct {
+	counts map[string][]int
+	root   *TrieNode
+}
 
-func SyllableCounts(word string) ([]int, bool) {
-	result, ok := syllableCounts[word]
+func (d *Dictionary) SyllableCounts(word string) ([]int, bool) {
+	result, ok := d.counts[word]
 	return result, ok
 }
 
-func IsWord(word string) bool {
-	_, ok := syllableCounts[word]
+func (d *Dictionary) IsWord(word string) bool {
+	_, ok := d.counts[word]
 	return ok
 }
 
-var TrieRoot *TrieNode
+// Root returns the root of a trie containing every word in the dictionary.
+func (d *Dictionary) Root() *TrieNode {
+	return d.root
+}
+
+// base holds the words embedded in the binary, which every loaded dictionary starts from.
+var base map[string][]int
+
+// current holds the *Dictionary in use, which is swapped out whenever the dictionary is reloaded.
+var current atomic.Value
+
+// Current returns the dictionary in use. Callers which look up several words should hold on to the result, so every
+// lookup sees the same words even if the dictionary is reloaded in the meantime.
+func Current() *Dictionary {
+	return current.Load().(*Dictionary)
+}
+
+func SyllableCounts(word string) ([]int, bool) {
+	return Current().SyllableCounts(word)
+}
+
+func IsWord(word string) bool {
+	return Current().IsWord(word)
+}
 
 func init() {
-	syllableCounts = make(map[string][]int)
-	TrieRoot = &TrieNode{}
+	base = make(map[string][]int)
+	err := parse(syllablesFile, base)
+	if err != nil {
+		panic(err)
+	}
+	current.Store(newDictionary(base))
+}
 
-	lines := strings.Split(syllablesFile, "\n")
+// parse reads lines of the form "WORD n m", adding each word and its syllable counts to counts. Lines without any
+// counts are skipped.
+func parse(text string, counts map[string][]int) error {
+	lines := strings.Split(text, "\n")
 	for lineNum, line := range lines {
-		tokens := strings.Split(line, " ")
-		word := tokens[0]
-		var counts []int
+		tokens := strings.Fields(line)
+		if len(tokens) < 2 {
+			continue
+		}
+		word := Fold(tokens[0])
+		var wordCounts []int
 		for _, token := range tokens[1:] {
 			count, err := strconv.Atoi(token)
 			if err != nil {
-				panic(fmt.Errorf("could not parse line %d: %w", lineNum, err))
+				return fmt.Errorf("could not parse line %d: %w", lineNum, err)
 			}
-			counts = append(counts, count)
+			wordCounts = append(wordCounts, count)
 		}
-		if len(counts) == 0 {
-			continue
-		}
-
-		syllableCounts[word] = counts
+		counts[word] = wordCounts
+	}
+	return nil
+}
 
-		TrieRoot.insert(word)
+func newDictionary(counts map[string][]int) *Dictionary {
+	result := &Dictionary{counts: counts, root: &TrieNode{}}
+	for word := range counts {
+		result.root.insert(word)
 	}
-}
\ No newline at end of file
+	return result
+}
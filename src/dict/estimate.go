@@ -0,0 +1,158 @@
+package dict
+
+import (
+	"strings"
+	"unicode"
+)
+
+// LowConfidence is the confidence below which an estimate should be treated with suspicion.
+const LowConfidence = 0.6
+
+// Estimate guesses the number of syllables in an uppercase word using phonics rules, for words which aren't in the
+// dictionary. It also returns a confidence between 0 and 1 that the guess is right; words which don't look like
+// English, such as those without vowels or with unpronounceable clusters of consonants, have low confidence.
+func Estimate(word string) (int, float64) {
+	word = strings.Trim(word, "'")
+	word = strings.ReplaceAll(word, "'", "")
+	if word == "" {
+		return 0, 0
+	}
+	if strings.IndexFunc(word, func(r rune) bool { return r > unicode.MaxASCII }) >= 0 {
+		return 1, 0 // letters which couldn't be folded to ASCII aren't from an English word
+	}
+	confidence := 0.9
+	count := 0
+	groups := vowelGroups(word)
+	if len(groups) == 0 {
+		return 1, 0.1 // probably an abbreviation or keyboard mash
+	}
+	for _, group := range groups {
+		count++
+		if splitsInTwo(group) {
+			count++
+			confidence -= 0.15 // "ia" in "dial", but not in "special"
+		}
+	}
+
+	n := len(word)
+	switch {
+	case strings.HasSuffix(word, "LE") && n > 2 && !isVowel(word, n-3):
+		// the "e" in a final consonant-le is silent, but the "le" is its own syllable, as in "table"; the vowel group
+		// for "e" has already been counted, so there's nothing to adjust
+	case strings.HasSuffix(word, "ED") && n > 3 && word[n-3] != 'T' && word[n-3] != 'D':
+		count-- // "jumped", but not "wanted"
+		confidence -= 0.05
+	case strings.HasSuffix(word, "ES") && n > 3 && !sibilantBefore(word, n-2):
+		count-- // "makes", but not "boxes"
+		confidence -= 0.1
+	case strings.HasSuffix(word, "E") && n > 2 && !isVowel(word, n-2) && count > 1:
+		count-- // silent e, as in "make"
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	if cluster := longestConsonantRun(word); cluster >= 4 {
+		confidence -= 0.3 * float64(cluster-3)
+	} else if end := trailingConsonants(word); end >= 3 && !commonEnding(word) {
+		confidence -= 0.4
+	}
+	if n > 12 {
+		confidence -= 0.1 // long words are more likely to be compounds of names or typos
+	}
+	if confidence < 0 {
+		confidence = 0
+	}
+	return count, confidence
+}
+
+// hiatus lists pairs of vowels which are usually pronounced as two syllables.
+var hiatus = []string{"IA", "IO", "EO", "UA", "UO", "II", "UI"}
+
+func splitsInTwo(group string) bool {
+	for _, pair := range hiatus {
+		if strings.Contains(group, pair) {
+			return true
+		}
+	}
+	return false
+}
+
+// vowelGroups returns each run of consecutive vowels in word. Y is treated as a vowel unless it starts the word.
+func vowelGroups(word string) []string {
+	var result []string
+	start := -1
+	for i := 0; i < len(word); i++ {
+		if isVowel(word, i) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			result = append(result, word[start:i])
+			start = -1
+		}
+	}
+	if start >= 0 {
+		result = append(result, word[start:])
+	}
+	return result
+}
+
+func isVowel(word string, i int) bool {
+	switch word[i] {
+	case 'A', 'E', 'I', 'O', 'U':
+		return true
+	case 'Y':
+		return i > 0
+	}
+	return false
+}
+
+func sibilantBefore(word string, i int) bool {
+	switch word[i-1] {
+	case 'S', 'X', 'Z':
+		return true
+	case 'H':
+		return i > 1 && (word[i-2] == 'C' || word[i-2] == 'S')
+	case 'C', 'G':
+		return true // "places", "pages"
+	}
+	return false
+}
+
+func longestConsonantRun(word string) int {
+	longest, run := 0, 0
+	for i := 0; i < len(word); i++ {
+		if isVowel(word, i) {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
+
+func trailingConsonants(word string) int {
+	count := 0
+	for i := len(word) - 1; i >= 0 && !isVowel(word, i); i-- {
+		count++
+	}
+	return count
+}
+
+// commonEndings are clusters of three consonants which often end English words.
+var commonEndings = []string{"NTS", "NDS", "NGS", "STS", "RTS", "RDS", "RST", "NCH", "RCH", "TCH", "GHT", "NKS", "LTS", "LDS", "MPS", "SKS", "CKS", "THS", "RLD", "RMS", "RNS", "LLS"}
+
+func commonEnding(word string) bool {
+	for _, ending := range commonEndings {
+		if strings.HasSuffix(word, ending) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package dict
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		word  string
+		count int
+	}{
+		{"BLORP", 1},
+		{"DOGGO", 2},
+		{"SKIBIDI", 3},
+		{"YEET", 1},
+		{"TABLE", 2},   // consonant-le
+		{"JUMPED", 1},  // silent -ed
+		{"WANTED", 2},  // -ed after t
+		{"MAKES", 1},   // silent e before s
+		{"BOXES", 2},   // -es after a sibilant
+		{"LION", 2},    // hiatus
+		{"STRIKE", 1},  // silent e
+		{"O'BRIEN", 2}, // apostrophes are ignored
+	}
+	for _, tt := range tests {
+		count, confidence := Estimate(tt.word)
+		assert.Equal(t, tt.count, count, tt.word)
+		assert.GreaterOrEqual(t, confidence, LowConfidence, tt.word)
+	}
+}
+
+func TestEstimate_LowConfidence(t *testing.T) {
+	for _, word := range []string{"ZXQV", "HMMM", "ASDF", "BCDFGH"} {
+		count, confidence := Estimate(word)
+		assert.Equal(t, 1, count, word)
+		assert.Less(t, confidence, LowConfidence, word)
+	}
+	count, confidence := Estimate("'")
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 0.0, confidence)
+}
@@ -0,0 +1,34 @@
+package dict
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+)
+
+// folds replaces letters which don't decompose into an ASCII letter and a diacritic, along with typographic quotes and
+// hyphens, with their closest ASCII spelling.
+var folds = map[rune]string{
+	'ß': "SS", 'Æ': "AE", 'æ': "AE", 'Œ': "OE", 'œ': "OE", 'Ø': "O", 'ø': "O", 'Ł': "L", 'ł': "L",
+	'Đ': "D", 'đ': "D", 'Þ': "TH", 'þ': "TH", 'Ð': "TH", 'ð': "TH", 'ı': "I",
+	'’': "'", '‘': "'", 'ʼ': "'", '′': "'", '`': "'",
+	'‐': "-",
+}
+
+// Fold upper-cases a word and folds it towards ASCII the way words in the dictionary are spelled: diacritics are
+// removed, so "café" becomes "CAFE", ligatures are spelled out, and curly quotes become apostrophes. Letters with no
+// ASCII equivalent are upper-cased and otherwise left alone.
+func Fold(word string) string {
+	var result strings.Builder
+	for _, r := range norm.NFKD.String(word) {
+		if unicode.Is(unicode.Mn, r) {
+			continue // a diacritic separated from its letter
+		}
+		if folded, ok := folds[r]; ok {
+			result.WriteString(folded)
+			continue
+		}
+		result.WriteRune(unicode.ToUpper(r))
+	}
+	return result.String()
+}
@@ -0,0 +1,42 @@
+package dict
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		{"haiku", "HAIKU"},
+		{"café", "CAFE"},
+		{"cafe\u0301", "CAFE"},
+		{"Pokémon", "POKEMON"},
+		{"naïve", "NAIVE"},
+		{"straße", "STRASSE"},
+		{"Œuvre", "OEUVRE"},
+		{"ﬁne", "FINE"},
+		{"don’t", "DON'T"},
+		{"well‐known", "WELL-KNOWN"},
+		{"日本", "日本"},
+		{"привет", "ПРИВЕТ"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Fold(tt.word), tt.word)
+	}
+}
+
+func TestTrie(t *testing.T) {
+	d := newDictionary(map[string][]int{"FROG": {1}, "DON'T": {1}, "ПРИВЕТ": {2}})
+
+	assert.True(t, d.Root().HasPrefix("FROG"))
+	assert.False(t, d.Root().HasPrefix("FRO"))
+	assert.True(t, d.Root().HasPrefix("DON'T"))
+	assert.True(t, d.Root().HasPrefix("ПРИВЕТ"))
+	assert.False(t, d.Root().HasPrefix("ПРИ"))
+	assert.False(t, d.Root().HasPrefix("日本"))
+	assert.Nil(t, d.Root().Child('ß'))
+	assert.NotNil(t, d.Root().Child('П'))
+}
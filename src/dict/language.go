@@ -0,0 +1,104 @@
+package dict
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Language splits lines written in a single language into words, counts the syllables in those words, and words the
+// explanations given when a message isn't a poem.
+type Language interface {
+	// Name identifies the language in settings and admin commands, e.g. "english".
+	Name() string
+	// Tokenize splits a line into words.
+	Tokenize(line string) []string
+	// CountSyllables returns every number of syllables a word could be pronounced with, fewest first, or false if it
+	// could not be counted.
+	CountSyllables(word string) ([]int, bool)
+	// Estimate guesses the syllables in an uppercase word which couldn't be counted, along with a confidence between 0
+	// and 1 that the guess is right. Returns 0 syllables if the language can't guess.
+	Estimate(word string) (int, float64)
+	// Phrases returns the sentences used to explain why a message is or isn't a poem.
+	Phrases() Phrases
+}
+
+// Phrases are the sentences used to explain why a message is or isn't a poem, in a single language.
+type Phrases struct {
+	NotAPoem     string // introduces an explanation; formatted with the title of the form, e.g. "Haiku"
+	UnknownWords string // followed by words whose syllables couldn't be counted
+	GuildWords   string // followed by words counted using a guild's own dictionary
+	GuessedWords string // followed by words whose syllables were estimated
+	ReadNumber   string // formatted with a number as it was written and as it was read aloud
+	Structure    string // formatted with the syllables counted on each line and the syllables expected
+	Teach        string // invites the author to teach words which couldn't be counted
+
+	ReadAs       string // introduces how a message written on one line was split into lines
+	FlaggedWords string // followed by words in a haiku whose syllables were guessed with low confidence
+	Deleted      string // introduces a deleted message in a DM to its author; formatted with the channel it was sent to
+
+	ThreeLines string // explains that a message checked only for haiku doesn't have three lines
+	LineCount  string // explains that a message has the wrong number of lines; formatted with the counts expected
+	Or         string // joins the line counts expected, as in "3 or 5"
+}
+
+// dashes separate words without spaces around them, as in "wait—what?"; they're never part of a word.
+var dashes = strings.NewReplacer("--", " ", "‒", " ", "–", " ", "—", " ", "―", " ")
+
+// Tokenize splits a line into words at any kind of whitespace or dash. Hyphens are left alone so hyphenated words are
+// counted together. It suits any language written with spaces between words.
+func Tokenize(line string) []string {
+	return strings.Fields(dashes.Replace(line))
+}
+
+// English counts words using the embedded dictionary, along with any dictionary files loaded by LoadDir.
+var English Language = english{}
+
+type english struct{}
+
+func (english) Name() string {
+	return "english"
+}
+
+func (english) Tokenize(line string) []string {
+	return Tokenize(line)
+}
+
+func (english) CountSyllables(word string) ([]int, bool) {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || r == '\'' || r == '-' {
+			return r
+		}
+		return -1
+	}, Fold(word))
+	counts, ok := SyllableCounts(strings.Trim(cleaned, "'-"))
+	if !ok || len(counts) == 0 {
+		return nil, false
+	}
+	result := append([]int(nil), counts...) // the dictionary's counts are shared, so sort a copy
+	sort.Ints(result)
+	return result, true
+}
+
+func (english) Estimate(word string) (int, float64) {
+	return Estimate(word)
+}
+
+func (english) Phrases() Phrases {
+	return Phrases{
+		NotAPoem:     "Hmmm, this doesn't seem like a traditional English %s; here's why:",
+		UnknownWords: "I don't know the words: ",
+		GuildWords:   "I used this server's dictionary for: ",
+		GuessedWords: "I had to guess the syllables in: ",
+		ReadNumber:   "I read %s as \"%s\"",
+		Structure:    "I counted a syllable structure of %s, but I expected %s",
+		Teach:        "If you know how many syllables a word has, you can teach me with `/haiku teach`.",
+		ReadAs:       "I read this as:",
+		FlaggedWords: "I had to guess how to say some of these words: ",
+		Deleted:      "I deleted the message you just sent to %s since I didn't think it was a proper Haiku:",
+
+		ThreeLines: "This doesn't seem to me like a traditional haiku; it doesn't have three lines.",
+		LineCount:  "This doesn't seem to me like a poem I know; it doesn't have %s lines.",
+		Or:         " or ",
+	}
+}
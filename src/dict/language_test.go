@@ -0,0 +1,80 @@
+package dict
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"an old silent pond", []string{"an", "old", "silent", "pond"}},
+		{"wait—what?", []string{"wait", "what?"}},
+		{"pond – splash", []string{"pond", "splash"}},
+		{"pond--splash", []string{"pond", "splash"}},
+		{"well-known frog\tpond", []string{"well-known", "frog", "pond"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Tokenize(tt.input), tt.input)
+	}
+}
+
+func TestEnglish_CountSyllables(t *testing.T) {
+	counts, ok := English.CountSyllables("Résumé,")
+	assert.True(t, ok)
+	assert.Equal(t, []int{2, 3}, counts)
+
+	counts, ok = English.CountSyllables("cooperate")
+	assert.True(t, ok)
+	assert.Equal(t, []int{3, 4}, counts)
+
+	counts, ok = English.CountSyllables("well-known")
+	assert.True(t, ok)
+	assert.Equal(t, []int{2}, counts)
+
+	_, ok = English.CountSyllables("zxqv")
+	assert.False(t, ok)
+}
+
+func TestSpanish_CountSyllables(t *testing.T) {
+	tests := []struct {
+		word  string
+		count int
+	}{
+		{"casa", 2},
+		{"¡Hola!", 2},
+		{"cuatro", 2},     // diphthong
+		{"ciudad", 2},     // two weak vowels
+		{"poeta", 3},      // hiatus between strong vowels
+		{"día", 2},        // an accent makes a weak vowel strong
+		{"país", 2},       // hiatus
+		{"aire", 2},       // diphthong
+		{"murciélago", 4}, // diphthong with an accent on the strong vowel
+		{"buey", 1},       // triphthong
+		{"que", 1},        // silent u
+		{"guitarra", 3},   // silent u
+		{"pingüino", 3},   // ü is pronounced
+		{"hoy", 1},        // y at the end of a word is a vowel
+		{"y", 1},
+		{"ayer", 2},     // y before a vowel is a consonant
+		{"prohibir", 3}, // h separates vowels
+		{"silencio", 3},
+		{"rana", 2},
+		{"estanque", 3},
+	}
+	for _, tt := range tests {
+		counts, ok := Spanish.CountSyllables(tt.word)
+		assert.True(t, ok, tt.word)
+		assert.Equal(t, []int{tt.count}, counts, tt.word)
+	}
+
+	for _, word := range []string{"pst", "5", "日本", "привет"} {
+		_, ok := Spanish.CountSyllables(word)
+		assert.False(t, ok, word)
+	}
+	counts, ok := Spanish.CountSyllables("—")
+	assert.True(t, ok)
+	assert.Equal(t, []int{0}, counts)
+}
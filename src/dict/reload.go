@@ -0,0 +1,87 @@
+package dict
+
+import (
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// reloadDelay is how long to wait after a file changes before reloading, so that a burst of changes, like an editor
+// saving a file, only causes a single reload.
+const reloadDelay = 500 * time.Millisecond
+
+// LoadDir replaces the dictionary with the embedded words plus the words in every *.txt file in dir, which use the same
+// "WORD n m" format. Words in dir take precedence over embedded words, and files are read in alphabetical order. If
+// any file can't be read, the dictionary is left unchanged.
+func LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return fmt.Errorf("could not list dictionary files in %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	counts := make(map[string][]int, len(base))
+	for word, wordCounts := range base {
+		counts[word] = wordCounts
+	}
+	for _, file := range files {
+		text, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("could not read dictionary file %s: %w", file, err)
+		}
+		err = parse(string(text), counts)
+		if err != nil {
+			return fmt.Errorf("could not load dictionary file %s: %w", file, err)
+		}
+	}
+	current.Store(newDictionary(counts))
+	log.Printf("loaded %d dictionary files from %s\n", len(files), dir)
+	return nil
+}
+
+// Watch reloads the dictionary from dir using LoadDir whenever a file in dir changes or the process receives SIGHUP,
+// until done is closed.
+func Watch(dir string, done <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("could not create file watcher: %w", err)
+	}
+	err = watcher.Add(dir)
+	if err != nil {
+		watcher.Close()
+		return fmt.Errorf("could not add watch for %s: %w", dir, err)
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		defer watcher.Close()
+		defer signal.Stop(hup)
+		var pending <-chan time.Time // fires when a reload is due after files have changed
+		for {
+			select {
+			case <-done:
+				return
+			case <-hup:
+				pending = time.After(0)
+			case event := <-watcher.Events:
+				if filepath.Ext(event.Name) == ".txt" {
+					pending = time.After(reloadDelay)
+				}
+			case err := <-watcher.Errors:
+				log.Println("error watching dictionary files,", err)
+			case <-pending:
+				pending = nil
+				if err := LoadDir(dir); err != nil {
+					log.Println("could not reload dictionary,", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,58 @@
+package dict
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	defer LoadDir(t.TempDir()) // restore the embedded dictionary
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("ZXQVORP 3\nHAIKU 3\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("HAIKU 2 3\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte("not a dictionary"), 0644))
+	require.NoError(t, LoadDir(dir))
+
+	counts, ok := SyllableCounts("ZXQVORP")
+	assert.True(t, ok)
+	assert.Equal(t, []int{3}, counts)
+	assert.True(t, Current().Root().children['Z'-'A'] != nil)
+
+	counts, _ = SyllableCounts("HAIKU")
+	assert.Equal(t, []int{2, 3}, counts, "later files should override earlier ones")
+	assert.True(t, IsWord("HOLOGRAPHIC"), "embedded words should still be loaded")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c.txt"), []byte("BROKEN x\n"), 0644))
+	assert.Error(t, LoadDir(dir))
+	assert.True(t, IsWord("ZXQVORP"), "a bad file should leave the dictionary unchanged")
+
+	require.NoError(t, LoadDir(t.TempDir()))
+	assert.False(t, IsWord("ZXQVORP"))
+	counts, _ = SyllableCounts("HAIKU")
+	assert.Equal(t, []int{2}, counts)
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	done := make(chan struct{})
+	defer LoadDir(t.TempDir())
+	defer close(done)
+
+	require.NoError(t, LoadDir(dir))
+	require.NoError(t, Watch(dir, done))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "words.txt"), []byte("GLORPTASTIC 3\n"), 0644))
+	assert.Eventually(t, func() bool { return IsWord("GLORPTASTIC") }, 5*time.Second, 50*time.Millisecond)
+
+	// replace the dictionary behind the watcher's back; SIGHUP should load dir again
+	require.NoError(t, LoadDir(t.TempDir()))
+	require.False(t, IsWord("GLORPTASTIC"))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool { return IsWord("GLORPTASTIC") }, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,98 @@
+package dict
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Spanish counts syllables using the rules of Spanish spelling, which are regular enough not to need a dictionary.
+var Spanish Language = spanish{}
+
+type spanish struct{}
+
+func (spanish) Name() string {
+	return "spanish"
+}
+
+func (spanish) Tokenize(line string) []string {
+	return Tokenize(line)
+}
+
+// CountSyllables counts the vowel sounds in a word. Neighbouring vowels are pronounced together as a diphthong or
+// triphthong unless they are both strong; a, e and o are strong, as are i and u when written with an accent. So
+// "cuatro" and "ciudad" have two syllables each, but "poeta" has three and "día" has two. Words which can't be spelled
+// with the Spanish alphabet, including numbers, aren't counted.
+func (spanish) CountSyllables(word string) ([]int, bool) {
+	var letters []rune
+	for _, r := range strings.ToLower(word) {
+		if unicode.IsDigit(r) {
+			return nil, false
+		}
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if !('a' <= r && r <= 'z') && !strings.ContainsRune("áéíóúüñ", r) {
+			return nil, false
+		}
+		letters = append(letters, r)
+	}
+	count := 0
+	prevVowel, prevStrong := false, false
+	for i, r := range letters {
+		if !isSpanishVowel(letters, i) {
+			prevVowel = false
+			continue
+		}
+		strong := strings.ContainsRune("aeoáéíóú", r)
+		if !prevVowel || strong && prevStrong {
+			count++ // a new syllable, either after a consonant or across a hiatus
+		}
+		prevVowel, prevStrong = true, strong
+	}
+	if count == 0 && len(letters) > 0 {
+		return nil, false // no vowels to pronounce, as in "pst"
+	}
+	return []int{count}, true
+}
+
+// Estimate never guesses, since every word spelled with the Spanish alphabet can be counted.
+func (spanish) Estimate(word string) (int, float64) {
+	return 0, 0
+}
+
+// isSpanishVowel reports whether the letter at i is pronounced as a vowel. The u in "que", "qui", "gue" and "gui" is
+// silent, and y is only a vowel on its own or at the end of a word after another vowel, as in "hoy" and "muy".
+func isSpanishVowel(letters []rune, i int) bool {
+	switch letters[i] {
+	case 'a', 'e', 'i', 'o', 'á', 'é', 'í', 'ó', 'ú', 'ü':
+		return true
+	case 'u':
+		if i == 0 || i+1 == len(letters) {
+			return true
+		}
+		prev, next := letters[i-1], letters[i+1]
+		return !(prev == 'q' || prev == 'g' && strings.ContainsRune("eiéí", next))
+	case 'y':
+		return len(letters) == 1 || i+1 == len(letters) && isSpanishVowel(letters, i-1)
+	}
+	return false
+}
+
+func (spanish) Phrases() Phrases {
+	return Phrases{
+		NotAPoem:     "Mmm, esto no parece un %s tradicional en español; te explico por qué:",
+		UnknownWords: "No conozco las palabras: ",
+		GuildWords:   "Usé el diccionario de este servidor para: ",
+		GuessedWords: "Tuve que adivinar las sílabas de: ",
+		ReadNumber:   "Leí %s como \"%s\"",
+		Structure:    "Conté una estructura de sílabas de %s, pero esperaba %s",
+		Teach:        "Si sabes cuántas sílabas tiene una palabra, puedes enseñármelo con `/haiku teach`.",
+		ReadAs:       "Lo leí así:",
+		FlaggedWords: "Tuve que adivinar cómo se dicen algunas de estas palabras: ",
+		Deleted:      "Borré el mensaje que acabas de enviar a %s porque no me pareció un Haiku de verdad:",
+
+		ThreeLines: "Esto no me parece un haiku tradicional; no tiene tres versos.",
+		LineCount:  "Esto no me parece ningún poema que conozca; no tiene %s versos.",
+		Or:         " o ",
+	}
+}
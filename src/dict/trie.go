@@ -3,44 +3,51 @@ package dict
 type TrieNode struct {
 	isWord bool
 	children [26]*TrieNode
+	others map[rune]*TrieNode // children for apostrophes, hyphens and letters outside A-Z, which are rare
 }
 
 func (n *TrieNode) insert(word string) {
-	if len(word) == 0 {
-		n.isWord = true
-		return
+	curr := n
+	for _, r := range word {
+		next := curr.Child(r)
+		if next == nil {
+			next = &TrieNode{}
+			curr.setChild(r, next)
+		}
+		curr = next
 	}
+	curr.isWord = true
+}
 
-	idx := word[0] - 'A'
-	if idx < 0 || idx > 26 {
+func (n *TrieNode) setChild(r rune, child *TrieNode) {
+	if 'A' <= r && r <= 'Z' {
+		n.children[r-'A'] = child
 		return
 	}
-
-	if child := n.children[idx]; child == nil {
-		n.children[idx] = &TrieNode{}
+	if n.others == nil {
+		n.others = make(map[rune]*TrieNode)
 	}
-	n.children[idx].insert(word[1:])
+	n.others[r] = child
 }
 
 func (n *TrieNode) HasPrefix(str string) bool {
-	if n == nil {
-		return false
+	curr := n
+	for _, r := range str {
+		if curr == nil {
+			return false
+		}
+		curr = curr.Child(r)
 	}
-	if len(str) == 0 {
-		return n.isWord
-	}
-
-	return n.Child(str[0]).HasPrefix(str[1:])
+	return curr != nil && curr.isWord
 }
 
-func (n *TrieNode) Child(ch byte) *TrieNode {
-	idx := ch - 'A'
-	if idx < 0 || idx > 26 {
-		return nil
+func (n *TrieNode) Child(r rune) *TrieNode {
+	if 'A' <= r && r <= 'Z' {
+		return n.children[r-'A']
 	}
-	return n.children[idx]
+	return n.others[r]
 }
 
 func (n *TrieNode) IsWord() bool {
 	return n.isWord
-}
\ No newline at end of file
+}
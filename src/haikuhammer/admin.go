@@ -4,117 +4,124 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/bwmarrin/discordgo"
 	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
 	"log"
 	"strconv"
 	"strings"
 )
 
-// adminCommandPerms is a bitmask for the min permissions required to send admin commands. If any flag is set, the
-// user can send HaikuHammer admin commands.
-const adminCommandPerms = discordgo.PermissionAdministrator | discordgo.PermissionManageChannels | discordgo.PermissionManageServer
-
-func (h *HaikuHammer) HandleAdminCommand(s *discordgo.Session, m *discordgo.Message) {
+func (h *HaikuHammer) HandleAdminCommand(m *Message) {
 	gid := m.GuildID // store original guild ID
-	m, err := s.ChannelMessage(m.ChannelID, m.ID)
+	m, err := h.platform.FetchMessage(m.ChannelID, m.ID)
 	if err != nil {
 		log.Println("could not look up message from channel", err)
 		return
 	}
 	m.GuildID = gid
 
-	perms, err := h.Permissions(s,m)
-	if err != nil {
-		log.Println("could not retrieve permissions for user, ignoring admin command,", err)
-		return
-	}
-	if perms & adminCommandPerms == 0 {
-		if h.config.Debug {
-			log.Printf("could not verify admin permissions, found perms %d, expected %d", perms, adminCommandPerms)
+	commandRaw := strings.TrimPrefix(m.Content, "!haiku ")
+	command, parseErr := parseCommand(commandRaw)
+	command.SenderID = m.AuthorID
+	if parseErr != nil || command.RequiresAdmin() {
+		isAdmin, err := h.platform.IsAdmin(m.GuildID, m.AuthorID)
+		if err != nil {
+			log.Println("could not retrieve permissions for user, ignoring admin command,", err)
+			return
+		}
+		if !isAdmin {
+			h.DM(m, fmt.Sprintf("You do not have permissions to manage HaikuHammer in <#%s>", m.ChannelID))
+			return
 		}
-		h.DM(s, m, fmt.Sprintf("You do not have permissions to manage HaikuHammer in <#%s>", m.ChannelID))
-		return
 	}
-	commandRaw := strings.TrimPrefix(m.Content, "!haiku ")
-	command, err := parseCommand(commandRaw)
-	if err != nil {
-		s.ChannelMessageSendReply(m.ChannelID, err.Error(), m.MessageReference)
+	if parseErr != nil {
+		h.reply(m, parseErr.Error())
 		return
 	}
+	h.reply(m, h.RunCommand(m.GuildID, command))
+}
 
+// RunCommand executes an admin command in the provided guild and returns the response to show the admin who sent it.
+// Callers are responsible for verifying the sender has permission to manage HaikuHammer.
+func (h *HaikuHammer) RunCommand(guildID string, command Command) string {
 	switch command.Operation {
 	case OpFeatureOn:
-		h.updateFeatures(m, command, EnableFeatures)
-		s.ChannelMessageSendReply(m.ChannelID, fmt.Sprintf("Enabled features %s for target %s", command.Features.String(), command.MentionTarget()), m.MessageReference)
+		h.updateFeatures(guildID, command, EnableFeatures)
+		return fmt.Sprintf("Enabled features %s for target %s", command.Features.String(), command.MentionTarget())
 	case OpFeatureOff:
-		h.updateFeatures(m, command, DisableFeatures)
-		s.ChannelMessageSendReply(m.ChannelID, fmt.Sprintf("Disabled features %s for target %s", command.Features.String(), command.MentionTarget()), m.MessageReference)
+		h.updateFeatures(guildID, command, DisableFeatures)
+		return fmt.Sprintf("Disabled features %s for target %s", command.Features.String(), command.MentionTarget())
 	case OpFeatureList:
-		h.handleFeatureList(s, m, command)
-	case OpHelp:
-		s.ChannelMessageSendReply(m.ChannelID, AdminHelp, m.MessageReference)
-	}
-}
-
-
-func (h *HaikuHammer) Permissions(s *discordgo.Session, m *discordgo.Message) (int64, error) {
-	g, err := s.Guild(m.GuildID)
-	if err != nil {
-		return 0, err
-	}
-	if g.OwnerID == m.Author.ID {
-		return discordgo.PermissionAll, nil
-	}
-	member, err := s.GuildMember(m.GuildID, m.Author.ID)
-	if err != nil {
-		return 0, err
-	}
-	roles, err := s.GuildRoles(m.GuildID)
-	if err != nil {
-		return 0, err
-	}
-	roleMap := make(map[string]int64)
-	for _, role := range roles {
-		roleMap[role.Name] = role.Permissions
-	}
-	permissions := roleMap["@everyone"]
-	for _, role := range member.Roles {
-		permissions |= roleMap[role]
-	}
-	if permissions & discordgo.PermissionAdministrator == discordgo.PermissionAdministrator {
-		return discordgo.PermissionAll, nil
+		return h.featureList(guildID, command)
+	case OpAwardsChannel:
+		return h.updateAwardChannel(guildID, command)
+	case OpAwardsList:
+		return h.awardList(guildID, command.Period)
+	case OpStats:
+		return h.stats(guildID, command)
+	case OpAttemptsList:
+		return h.attemptList(guildID)
+	case OpAttemptsWords:
+		return h.attemptWords(guildID)
+	case OpFormOn, OpEnforceOn:
+		return h.updateForms(guildID, command, true)
+	case OpFormOff, OpEnforceOff:
+		return h.updateForms(guildID, command, false)
+	case OpFormList, OpEnforceList:
+		return h.formList(guildID, command)
+	case OpEstimates:
+		return h.updateEstimateMode(guildID, command)
+	case OpLanguage:
+		return h.updateLanguage(guildID, command)
+	case OpDictionaryAdd:
+		return h.addGuildWord(guildID, command)
+	case OpDictionaryRemove:
+		return h.removeGuildWord(guildID, command)
+	case OpDictionaryList:
+		return h.guildWordList(guildID)
+	case OpTeach:
+		return h.teach(guildID, command)
+	case OpSuggestionsList:
+		return h.suggestionList(guildID)
+	case OpSuggestionsApprove:
+		return h.reviewSuggestion(guildID, command, true)
+	case OpSuggestionsReject:
+		return h.reviewSuggestion(guildID, command, false)
+	default:
+		if topic, err := findHelpTopic(command.HelpTopic); err == nil {
+			return topic.Text
+		}
+		return AdminHelp
 	}
-	return permissions, nil
 }
 
-func (h *HaikuHammer) handleFeatureList(s *discordgo.Session, m *discordgo.Message, command Command) {
+func (h *HaikuHammer) featureList(guildID string, command Command) string {
 	ctx := context.Background()
+	failure := fmt.Sprintf("I couldn't look up the features enabled for target %s, please try again later", command.MentionTarget())
 	switch command.Target {
 	case "global":
-		gid, err := strconv.Atoi(m.GuildID)
+		gid, err := strconv.Atoi(guildID)
 		if err != nil {
-			log.Println("could not parse guildID as integer,", m.GuildID)
-			return
+			log.Println("could not parse guildID as integer,", guildID)
+			return failure
 		}
-		currConfig, err := db.GuildConfigDAO.FindByID(ctx, h.db, gid)
+		currConfig, err := h.store.GuildConfig(ctx, gid)
 		if err != nil {
 			log.Println("could not read guild config from database,", err)
-			return
+			return failure
 		}
-		s.ChannelMessageSendReply(m.ChannelID, fmt.Sprintf("Features enabled for target %s: %s", command.MentionTarget(), currConfig.Flags), m.MessageReference)
+		return fmt.Sprintf("Features enabled for target %s: %s", command.MentionTarget(), currConfig.Flags)
 	default:
 		cid, err := strconv.Atoi(command.Target)
 		if err != nil {
-			log.Println("could not parse channelID as integer,", m.ChannelID)
-			return
+			log.Println("could not parse channelID as integer,", command.Target)
+			return failure
 		}
-		currConfig, err := db.ChannelConfigDAO.FindByID(ctx, h.db, cid)
+		currConfig, err := h.store.ChannelConfig(ctx, cid)
 		if err != nil {
 			log.Println("could not read channel config from database,", err)
-			return
+			return failure
 		}
-		s.ChannelMessageSendReply(m.ChannelID, fmt.Sprintf("Features enabled for target %s: %s", command.MentionTarget(), currConfig.Flags), m.MessageReference)
+		return fmt.Sprintf("Features enabled for target %s: %s", command.MentionTarget(), currConfig.Flags)
 	}
 }
 
@@ -128,16 +135,16 @@ func DisableFeatures(current db.ConfigFlag, feats db.ConfigFlag) db.ConfigFlag {
 	return current.And(^feats) // and with bitwise not
 }
 
-func (h *HaikuHammer) updateFeatures(m *discordgo.Message, command Command, mutator featureMutator) {
+func (h *HaikuHammer) updateFeatures(guildID string, command Command, mutator featureMutator) {
 	ctx := context.Background()
 	switch command.Target {
 	case "global":
-		gid, err := strconv.Atoi(m.GuildID)
+		gid, err := strconv.Atoi(guildID)
 		if err != nil {
-			log.Println("could not parse guildID as integer,", m.GuildID)
+			log.Println("could not parse guildID as integer,", guildID)
 			return
 		}
-		currConfig, err := db.GuildConfigDAO.FindByID(ctx, h.db, gid) // read
+		currConfig, err := h.store.GuildConfig(ctx, gid) // read
 		if err != nil {
 			log.Println("could not retrieve guild permissions,", err)
 		}
@@ -146,24 +153,25 @@ func (h *HaikuHammer) updateFeatures(m *discordgo.Message, command Command, muta
 		currConfig.GuildID = gid
 		currConfig.Flags = mutator(currConfig.Flags, command.Features)
 
-		_, err = db.GuildConfigDAO.Upsert(ctx, h.db, currConfig) // write
+		err = h.store.SaveGuildConfig(ctx, currConfig) // write
 		if err != nil {
 			log.Println("could not update guild permissions,", err)
 		}
 	default: // channel ID (target was verified by caller)
 		cid, err := strconv.Atoi(command.Target)
 		if err != nil {
-			log.Println("could not parse guildID as integer,", m.GuildID)
+			log.Println("could not parse channelID as integer,", command.Target)
 			return
 		}
-		currConfig, err := db.ChannelConfigDAO.FindByID(ctx, h.db, cid) // read
+		currConfig, err := h.store.ChannelConfig(ctx, cid) // read
 		if err != nil {
 			log.Println("could not retrieve channel permissions,", err)
 		}
 
+		currConfig.ChannelID = cid
 		currConfig.Flags = mutator(currConfig.Flags, command.Features)
 
-		_, err = db.ChannelConfigDAO.Upsert(ctx, h.db, cid, currConfig.Flags) // write
+		err = h.store.SaveChannelConfig(ctx, currConfig) // write
 		if err != nil {
 			log.Println("could not update guild permissions,", err)
 		}
@@ -178,12 +186,57 @@ const (
 	OpFeatureOff
 	OpFeatureList
 	OpHelp
+	OpAwardsChannel
+	OpAwardsList
+	OpStats
+	OpAttemptsList
+	OpAttemptsWords
+	OpFormOn
+	OpFormOff
+	OpFormList
+	OpEstimates
+	OpDictionaryAdd
+	OpDictionaryRemove
+	OpDictionaryList
+	OpTeach
+	OpSuggestionsList
+	OpSuggestionsApprove
+	OpSuggestionsReject
+	OpLanguage
+	OpEnforceOn
+	OpEnforceOff
+	OpEnforceList
 )
 
 type Command struct {
 	Operation Operation
 	Target string
 	Features db.ConfigFlag
+	Period AwardPeriod
+	UserID string
+	Forms []Form
+	Estimates EstimateMode
+	Word string
+	Syllables int
+	SuggestionID int
+	Global bool // if true, approved words are added to the dictionary for every guild
+	SenderID string // the user who sent the command
+	Language Language // nil if the command only shows the current language
+	HelpTopic string // empty to show AdminHelp
+}
+
+// RequiresAdmin returns true if only admins may run this command.
+func (c Command) RequiresAdmin() bool {
+	switch c.Operation {
+	case OpAwardsList, OpStats, OpDictionaryList, OpTeach:
+		return false
+	}
+	return true
+}
+
+// EnforcesForms returns true if this command configures the forms DeleteNonHaiku keeps, rather than those accepted.
+func (c Command) EnforcesForms() bool {
+	return c.Operation == OpEnforceOn || c.Operation == OpEnforceOff || c.Operation == OpEnforceList
 }
 
 func (c Command) MentionTarget() string {
@@ -195,16 +248,61 @@ func (c Command) MentionTarget() string {
 
 func parseCommand(content string) (Command, error) {
 	var err error
-	tokens := strings.Split(content, " ")
-	var trimmed []string
-	for _, token := range tokens {
-		if token != "" {
-			trimmed = append(trimmed, token)
-		}
-	}
+	tokens := strings.Fields(content)
 	if len(tokens) < 1 {
 		return Command{}, errors.New("expected a valid command after `!haiku`; send `!haiku help` for help")
 	}
+	if tokens[0] == "stats" {
+		result := Command{Operation: OpStats}
+		if len(tokens) > 1 {
+			result.UserID, err = parseUserMention(tokens[1])
+		}
+		return result, err
+	}
+	if tokens[0] == "teach" {
+		result := Command{Operation: OpTeach}
+		if len(tokens) < 3 {
+			return Command{}, errors.New("expected a word and number of syllables after `teach`; send `!haiku help` for help")
+		}
+		result.Word, err = parseGuildWord(tokens[1])
+		if err != nil {
+			return Command{}, err
+		}
+		result.Syllables, err = parseWordSyllables(tokens[2])
+		return result, err
+	}
+	if tokens[0] == "language" {
+		result := Command{Operation: OpLanguage, Target: "global"}
+		if len(tokens) > 1 {
+			result.Target = tokens[1]
+			if result.Target != "global" {
+				result.Target, err = parseChannelMention(result.Target)
+				if err != nil {
+					return Command{}, err
+				}
+			}
+		}
+		if len(tokens) > 2 {
+			result.Language, err = parseLanguage(tokens[2])
+		}
+		return result, err
+	}
+	if tokens[0] == "help" {
+		result := Command{Operation: OpHelp}
+		if len(tokens) > 1 {
+			var topic helpTopic
+			topic, err = findHelpTopic(tokens[1])
+			result.HelpTopic = topic.Name
+		}
+		return result, err
+	}
+	if tokens[0] == "estimates" {
+		result := Command{Operation: OpEstimates}
+		if len(tokens) > 1 {
+			result.Estimates, err = parseEstimateMode(tokens[1])
+		}
+		return result, err
+	}
 	command := tokens[0]
 	if len(tokens) > 1 {
 		command += " " + tokens[1]
@@ -226,32 +324,146 @@ func parseCommand(content string) (Command, error) {
 		if len(tokens) < 3 {
 			return Command{}, errors.New("expected a target after `feature list`; send `!haiku help` for help")
 		}
-	case "help":
-		result.Operation = OpHelp
+	case "awards channel":
+		result.Operation = OpAwardsChannel
+		if len(tokens) < 3 {
+			return Command{}, errors.New("expected a channel or `off` after `awards channel`; send `!haiku help` for help")
+		}
+		if tokens[2] == "off" {
+			return result, nil
+		}
+		result.Target, err = parseChannelMention(tokens[2])
+		return result, err
+	case "awards list":
+		result.Operation = OpAwardsList
+		result.Period = AwardWeek
+		if len(tokens) > 2 {
+			result.Period, err = ParseAwardPeriod(tokens[2])
+		}
+		return result, err
+	case "form on":
+		result.Operation = OpFormOn
+		if len(tokens) < 4 {
+			return Command{}, errors.New("expected a target and list of forms after `form on`; send `!haiku help` for help")
+		}
+	case "form off":
+		result.Operation = OpFormOff
+		if len(tokens) < 4 {
+			return Command{}, errors.New("expected a target and list of forms after `form off`; send `!haiku help` for help")
+		}
+	case "form list":
+		result.Operation = OpFormList
+		if len(tokens) < 3 {
+			return Command{}, errors.New("expected a target after `form list`; send `!haiku help` for help")
+		}
+	case "enforce on":
+		result.Operation = OpEnforceOn
+		if len(tokens) < 4 {
+			return Command{}, errors.New("expected a target and list of forms after `enforce on`; send `!haiku help` for help")
+		}
+	case "enforce off":
+		result.Operation = OpEnforceOff
+		if len(tokens) < 4 {
+			return Command{}, errors.New("expected a target and list of forms after `enforce off`; send `!haiku help` for help")
+		}
+	case "enforce list":
+		result.Operation = OpEnforceList
+		if len(tokens) < 3 {
+			return Command{}, errors.New("expected a target after `enforce list`; send `!haiku help` for help")
+		}
+	case "attempts list":
+		result.Operation = OpAttemptsList
+		return result, nil
+	case "attempts words":
+		result.Operation = OpAttemptsWords
 		return result, nil
+	case "dictionary add":
+		result.Operation = OpDictionaryAdd
+		if len(tokens) < 4 {
+			return Command{}, errors.New("expected a word and number of syllables after `dictionary add`; send `!haiku help` for help")
+		}
+		result.Word, err = parseGuildWord(tokens[2])
+		if err != nil {
+			return Command{}, err
+		}
+		result.Syllables, err = parseWordSyllables(tokens[3])
+		return result, err
+	case "dictionary remove":
+		result.Operation = OpDictionaryRemove
+		if len(tokens) < 3 {
+			return Command{}, errors.New("expected a word after `dictionary remove`; send `!haiku help` for help")
+		}
+		result.Word, err = parseGuildWord(tokens[2])
+		return result, err
+	case "dictionary list":
+		result.Operation = OpDictionaryList
+		return result, nil
+	case "suggestions list":
+		result.Operation = OpSuggestionsList
+		return result, nil
+	case "suggestions approve", "suggestions reject":
+		result.Operation = OpSuggestionsApprove
+		if tokens[1] == "reject" {
+			result.Operation = OpSuggestionsReject
+		}
+		if len(tokens) < 3 {
+			return Command{}, fmt.Errorf("expected a suggestion number after `%s`; send `!haiku help` for help", command)
+		}
+		result.SuggestionID, err = parseSuggestionID(tokens[2])
+		result.Global = len(tokens) > 3 && tokens[3] == "global" && result.Operation == OpSuggestionsApprove
+		return result, err
 	default:
 		return Command{}, fmt.Errorf("could not understand command %s", command)
 	}
 
 	// parse channel mention
 	result.Target = tokens[2]
-	if result.Target != "global" && strings.HasPrefix(result.Target, "<#") {
-		id, err := strconv.Atoi(strings.TrimSuffix(result.Target[2:], ">"))
+	if result.Target != "global" {
+		result.Target, err = parseChannelMention(result.Target)
 		if err != nil {
-			return Command{}, fmt.Errorf("couldn't parse target '%s' as valid channel mention", result.Target)
+			return Command{}, err
 		}
-		result.Target = fmt.Sprintf("%d", id)
-	} else if result.Target != "global" {
-		return Command{}, fmt.Errorf("couldn't parse target '%s' as valid target", result.Target)
 	}
 
-	result.Features, err = parseFeatures(tokens[3:])
+	switch result.Operation {
+	case OpFormOn, OpFormOff, OpFormList, OpEnforceOn, OpEnforceOff, OpEnforceList:
+		result.Forms, err = parseForms(tokens[3:])
+	default:
+		result.Features, err = parseFeatures(tokens[3:])
+	}
 	if err != nil {
 		return Command{}, err
 	}
 	return result, nil
 }
 
+// FeatureNames lists the name of every feature which can be configured by admins.
+var FeatureNames = []string{"ReactToHaiku", "ReactToNonHaiku", "DeleteNonHaiku", "ExplainNonHaiku", "ServeRandomHaiku", "InferLineBreaks", "CountInterjections", "ReplyToCopies"}
+
+// parseChannelMention returns the ID of the channel mentioned by target.
+func parseChannelMention(target string) (string, error) {
+	if !strings.HasPrefix(target, "<#") {
+		return "", fmt.Errorf("couldn't parse target '%s' as valid target", target)
+	}
+	id, err := strconv.Atoi(strings.TrimSuffix(target[2:], ">"))
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse target '%s' as valid channel mention", target)
+	}
+	return fmt.Sprintf("%d", id), nil
+}
+
+// parseUserMention returns the ID of the user mentioned by target.
+func parseUserMention(target string) (string, error) {
+	if !strings.HasPrefix(target, "<@") {
+		return "", fmt.Errorf("couldn't parse '%s' as a user mention", target)
+	}
+	id, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(target[2:], "!"), ">"))
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse '%s' as a user mention", target)
+	}
+	return fmt.Sprintf("%d", id), nil
+}
+
 func parseFeatures(features []string) (db.ConfigFlag, error) {
 	var result db.ConfigFlag
 	for _, feature := range features {
@@ -266,6 +478,12 @@ func parseFeatures(features []string) (db.ConfigFlag, error) {
 			result |= db.ConfigExplainNonHaiku
 		case "ServeRandomHaiku":
 			result |= db.ConfigServeRandomHaiku
+		case "InferLineBreaks":
+			result |= db.ConfigInferLineBreaks
+		case "CountInterjections":
+			result |= db.ConfigCountInterjections
+		case "ReplyToCopies":
+			result |= db.ConfigReplyToCopies
 		case "": // ignore
 		default:
 			return 0, fmt.Errorf("could not understand '%s' as a valid feature; send `!haiku help` for help", feature)
@@ -287,8 +505,108 @@ var AdminHelp = `All commands must be sent in the guild they are meant to apply
    - ~~~DeleteNonHaiku~~~ - deletes any messages which are not valid haiku -- requires MANAGE_MESSAGES permission
    - ~~~ExplainNonHaiku~~~ - respond publicly in channel with an explanation of why a message is not a haiku
    - ~~~ServeRandomHaiku~~~ - reacts to mentions by publicly quoting some haiku previously detected in the same guild.
+   - ~~~InferLineBreaks~~~ - finds haiku written on a single line by splitting it between words, and replies with how it was split.
+   - ~~~CountInterjections~~~ - counts chat interjections like ~~~lol~~~, ~~~omg~~~ and ~~~brb~~~ the way they're usually said.
+   - ~~~ReplyToCopies~~~ - replies to haiku which look a lot like one posted earlier in the guild, crediting the original author.
+
+  ~~~!haiku help [topic]~~~
+
+Send ~~~help~~~ with a topic to learn about the other commands; ~~~[topic]~~~ is one of ~~~form~~~, ~~~awards~~~,
+~~~stats~~~, ~~~attempts~~~, ~~~estimates~~~, ~~~language~~~, ~~~dictionary~~~ or ~~~suggestions~~~.
+
+Each command is also available as a slash command, e.g. ~~~/haiku feature on~~~, which only replies to you. Leave
+the channel option empty to target every channel in the guild.
 `
 
+// helpTopic explains a group of commands which don't fit in AdminHelp, since Discord messages are limited to 2000
+// characters.
+type helpTopic struct {
+	Name string
+	Text string
+}
+
+var helpTopics = []helpTopic{
+	{Name: "form", Text: `  ~~~!haiku form on [target] [form form...]~~~
+  ~~~!haiku form off [target] [form form...]~~~
+  ~~~!haiku form list [target]~~~
+
+~~~form~~~ chooses which poetic forms are accepted in addition to, or instead of, haiku. Messages matching any
+accepted form are treated like haiku by every feature. ~~~[form form...]~~~ is a space-separated list of
+~~~haiku~~~ (5/7/5), ~~~senryu~~~ (5/7/5), ~~~tanka~~~ (5/7/5/7/7), ~~~cinquain~~~ (2/4/6/8/2) or a custom pattern
+like ~~~4/6/4~~~, optionally followed by how many syllables each line may be off by, like ~~~5/7/5~1~~~.
+
+  ~~~!haiku enforce on [target] [form form...]~~~
+  ~~~!haiku enforce off [target] [form form...]~~~
+  ~~~!haiku enforce list [target]~~~
+
+~~~enforce~~~ chooses which forms ~~~DeleteNonHaiku~~~ keeps, so a channel can celebrate strict haiku while only
+deleting messages which don't fit a looser form like ~~~5/7/5~1~~~. Until it's used, the accepted forms are enforced.
+`},
+	{Name: "awards", Text: `  ~~~!haiku awards channel [channel]~~~
+  ~~~!haiku awards list [period]~~~
+
+~~~awards channel~~~ announces the Haiku of the Week, Month and Year in a channel, chosen by counting reactions to
+each haiku. Send ~~~off~~~ instead of a channel mention to stop announcing awards. Anyone can use ~~~awards list~~~
+to see past winners; ~~~[period]~~~ is one of ~~~week~~~, ~~~month~~~ or ~~~year~~~.
+`},
+	{Name: "stats", Text: `  ~~~!haiku stats [user]~~~
+
+Anyone can use ~~~stats~~~ to see the most prolific authors in the guild, or mention a user to see how many haiku
+they have written, how often their attempts are accepted and how many days in a row they have written a haiku.
+`},
+	{Name: "attempts", Text: `  ~~~!haiku attempts list~~~
+  ~~~!haiku attempts words~~~
+
+~~~attempts list~~~ shows recent three-line messages which weren't haiku, along with the syllables I counted and any
+words I didn't know. ~~~attempts words~~~ lists the words I didn't know which most often stopped a haiku.
+`},
+	{Name: "estimates", Text: `  ~~~!haiku estimates [mode]~~~
+
+When a word isn't in my dictionary, I guess how many syllables it has from its spelling. Guesses I'm confident in
+are always counted; ~~~estimates~~~ chooses what happens to the rest. ~~~[mode]~~~ is one of ~~~accept~~~ to count
+them, ~~~flag~~~ to count them and point them out when I react to a haiku, or ~~~reject~~~ (the default) to treat
+them as words I don't know. Leave ~~~[mode]~~~ empty to see the current mode.
+`},
+	{Name: "language", Text: `  ~~~!haiku language [target] [language]~~~
+
+~~~language~~~ chooses the language messages are written in, for the whole guild or a single channel. ~~~[language]~~~
+is one of ~~~english~~~ (the default) or ~~~spanish~~~, which is counted using the rules of Spanish spelling. Leave
+~~~[language]~~~ empty to see the current language.
+`},
+	{Name: "dictionary", Text: `  ~~~!haiku dictionary add [word] [syllables]~~~
+  ~~~!haiku dictionary remove [word]~~~
+  ~~~!haiku dictionary list~~~
+
+~~~dictionary~~~ teaches me how many syllables are in slang, names and inside jokes used in the guild. Words added
+here are counted before anything else, and I'll mention them when explaining why a message isn't a haiku. Anyone can
+use ~~~dictionary list~~~.
+`},
+	{Name: "suggestions", Text: `  ~~~!haiku teach [word] [syllables]~~~
+  ~~~!haiku suggestions list~~~
+  ~~~!haiku suggestions approve [number] [global]~~~
+  ~~~!haiku suggestions reject [number]~~~
+
+Anyone can use ~~~teach~~~ to suggest how many syllables a word has. Admins review suggestions with
+~~~suggestions list~~~, then approve or reject them by number. Approved words are added to the guild's dictionary, or
+to every guild's if ~~~global~~~ is added by a global moderator, and recent messages they blocked are checked again.
+`},
+}
+
+// findHelpTopic finds the helpTopic with the provided name, ignoring case.
+func findHelpTopic(name string) (helpTopic, error) {
+	var names []string
+	for _, topic := range helpTopics {
+		if strings.EqualFold(topic.Name, name) {
+			return topic, nil
+		}
+		names = append(names, topic.Name)
+	}
+	return helpTopic{}, fmt.Errorf("could not understand '%s' as a help topic; expected one of %s", name, strings.Join(names, ", "))
+}
+
 func init() {
 	AdminHelp = strings.ReplaceAll(AdminHelp, "~~~", "`")
+	for i := range helpTopics {
+		helpTopics[i].Text = strings.ReplaceAll(helpTopics[i].Text, "~~~", "`")
+	}
 }
\ No newline at end of file
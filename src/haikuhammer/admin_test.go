@@ -0,0 +1,157 @@
+package haikuhammer
+
+import (
+	"context"
+	"github.com/bwmarrin/discordgo"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestHandleAdminCommand_Permissions(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	modRole := h.addRole(gid, discordgo.PermissionManageChannels)
+	h.addMember(gid, "2", "member")
+	h.addMember(gid, "3", "moderator", modRole)
+
+	command := "!haiku feature on " + channelMention(cid) + " ReactToHaiku"
+
+	h.post(gid, cid, "2", command)
+	assert.Equal(t, []string{"You do not have permissions to manage HaikuHammer in " + channelMention(cid)}, h.dms("2"))
+	assert.EqualValues(t, 0, channelFlags(t, h, cid))
+
+	modID := h.post(gid, cid, "3", command)
+	assert.Equal(t, []string{"Enabled features ReactToHaiku for target " + channelMention(cid)}, h.replies(modID))
+	assert.Equal(t, db.ConfigReactToHaiku, channelFlags(t, h, cid))
+
+	ownerID := h.post(gid, cid, "1", "!haiku feature off "+channelMention(cid)+" ReactToHaiku")
+	assert.Equal(t, []string{"Disabled features ReactToHaiku for target " + channelMention(cid)}, h.replies(ownerID))
+	assert.EqualValues(t, 0, channelFlags(t, h, cid))
+}
+
+func TestHandleAdminCommand_Global(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+
+	h.post(gid, cid, "1", "!haiku feature on global ReactToHaiku ExplainNonHaiku")
+	listID := h.post(gid, cid, "1", "!haiku feature list global")
+	assert.Equal(t, []string{"Features enabled for target global: ReactToHaiku, ExplainNonHaiku"}, h.replies(listID))
+
+	haikuID := h.post(gid, cid, "2", testHaiku)
+	assert.Equal(t, []string{"💯"}, h.reactions(haikuID))
+}
+
+func TestHandleAdminCommand_Errors(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+
+	badID := h.post(gid, cid, "1", "!haiku feature on global Nonsense")
+	assert.Equal(t, []string{"could not understand 'Nonsense' as a valid feature; send `!haiku help` for help"}, h.replies(badID))
+
+	helpID := h.post(gid, cid, "1", "!haiku help")
+	assert.Equal(t, []string{AdminHelp}, h.replies(helpID))
+	for _, topic := range helpTopics {
+		helpID = h.post(gid, cid, "1", "!haiku help "+topic.Name)
+		assert.Equal(t, []string{topic.Text}, h.replies(helpID))
+	}
+	helpID = h.post(gid, cid, "1", "!haiku help Nonsense")
+	assert.Equal(t, []string{"could not understand 'Nonsense' as a help topic; expected one of form, awards, stats, attempts, estimates, language, dictionary, suggestions"}, h.replies(helpID))
+}
+
+func channelFlags(t *testing.T, h *harness, channelID string) db.ConfigFlag {
+	cid, err := strconv.Atoi(channelID)
+	assert.NoError(t, err)
+	conf, err := h.store.ChannelConfig(context.Background(), cid)
+	assert.NoError(t, err)
+	return conf.Flags
+}
+
+func subcommand(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionSubCommand, Options: options}
+}
+
+func featureGroup(sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: "feature", Type: discordgo.ApplicationCommandOptionSubCommandGroup,
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{sub}}
+}
+
+func stringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+}
+
+func channelOption(channelID string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: "channel", Type: discordgo.ApplicationCommandOptionChannel, Value: channelID}
+}
+
+func TestSlashCommand_Feature(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.addMember(gid, "2", "member")
+
+	resp := h.command(gid, cid, "2", featureGroup(subcommand("on", stringOption("features", "ReactToHaiku"), channelOption(cid))))
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, resp.Data.Flags)
+	assert.Equal(t, "You do not have permissions to manage HaikuHammer in "+channelMention(cid), resp.Data.Content)
+	assert.EqualValues(t, 0, channelFlags(t, h, cid))
+
+	resp = h.command(gid, cid, "1", featureGroup(subcommand("on", stringOption("features", "ReactToHaiku, ExplainNonHaiku"), channelOption(cid))))
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, resp.Data.Flags)
+	assert.Equal(t, "Enabled features ReactToHaiku, ExplainNonHaiku for target "+channelMention(cid), resp.Data.Content)
+	assert.Equal(t, db.ConfigReactToHaiku|db.ConfigExplainNonHaiku, channelFlags(t, h, cid))
+
+	resp = h.command(gid, cid, "1", featureGroup(subcommand("off", stringOption("features", "ExplainNonHaiku"), channelOption(cid))))
+	assert.Equal(t, "Disabled features ExplainNonHaiku for target "+channelMention(cid), resp.Data.Content)
+
+	resp = h.command(gid, cid, "1", featureGroup(subcommand("on", stringOption("features", "ServeRandomHaiku"))))
+	assert.Equal(t, "Enabled features ServeRandomHaiku for target global", resp.Data.Content)
+
+	resp = h.command(gid, cid, "1", featureGroup(subcommand("list")))
+	assert.Equal(t, "Features enabled for target global: ServeRandomHaiku", resp.Data.Content)
+	resp = h.command(gid, cid, "1", featureGroup(subcommand("list", channelOption(cid))))
+	assert.Equal(t, "Features enabled for target "+channelMention(cid)+": ReactToHaiku", resp.Data.Content)
+
+	resp = h.command(gid, cid, "1", featureGroup(subcommand("on", stringOption("features", "Nonsense"))))
+	assert.Equal(t, "could not understand 'Nonsense' as a valid feature; send `!haiku help` for help", resp.Data.Content)
+
+	resp = h.command(gid, cid, "1", subcommand("help"))
+	assert.Equal(t, AdminHelp, resp.Data.Content)
+	resp = h.command(gid, cid, "1", subcommand("help", stringOption("topic", "suggestions")))
+	assert.Equal(t, helpTopics[len(helpTopics)-1].Text, resp.Data.Content)
+
+	assert.Empty(t, h.session.sent, "slash commands should never post publicly")
+}
+
+func TestSlashCommand_Autocomplete(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+
+	features := stringOption("features", "ReactToHaiku, re")
+	features.Focused = true
+	resp := h.interact(discordgo.InteractionApplicationCommandAutocomplete, gid, cid, "1",
+		[]*discordgo.ApplicationCommandInteractionDataOption{featureGroup(subcommand("on", features))})
+
+	assert.Equal(t, discordgo.InteractionApplicationCommandAutocompleteResult, resp.Type)
+	var choices []string
+	for _, choice := range resp.Data.Choices {
+		choices = append(choices, choice.Name)
+	}
+	assert.Equal(t, []string{"ReactToHaiku, ReactToNonHaiku", "ReactToHaiku, ReplyToCopies"}, choices)
+}
+
+func TestFeatureChoices(t *testing.T) {
+	names := func(choices []*discordgo.ApplicationCommandOptionChoice) []string {
+		var result []string
+		for _, choice := range choices {
+			result = append(result, choice.Name)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"reacttohaiku, ReactToNonHaiku", "reacttohaiku, ReplyToCopies"}, names(featureChoices("reacttohaiku, re")),
+		"features already chosen aren't suggested again, whatever their case")
+
+	long := "ExplainNonHaiku, DeleteNonHaiku, ServeRandomHaiku, InferLineBreaks, CountInterjections, "
+	assert.Equal(t, []string{long + "ReactToHaiku"}, names(featureChoices(long+"re")),
+		"choices longer than Discord allows are left out")
+	assert.Empty(t, featureChoices("ReactToHaiku, "+long+"re"))
+}
@@ -0,0 +1,125 @@
+package haikuhammer
+
+import (
+	"github.com/kalexmills/haiku-enforcer/src/dict"
+	"strings"
+)
+
+// affix is a prefix or suffix which can be removed from a word to find a stem in the dictionary.
+type affix struct {
+	text      string
+	syllables int
+}
+
+// suffixes are checked in order, so longer suffixes must come before any suffix they end with.
+var suffixes = []affix{
+	{"NESS", 1},
+	{"MENT", 1},
+	{"ABLE", 2},
+	{"ING", 1},
+	{"EST", 1},
+	{"ED", 0}, // or 1 after a T or D; see suffixSyllables
+	{"ER", 1},
+	{"LY", 1},
+	{"Y", 1},
+	{"S", 0},
+}
+
+var prefixes = []affix{
+	{"OVER", 2},
+	{"ANTI", 2},
+	{"SEMI", 2},
+	{"PRE", 1},
+	{"PRO", 1},
+	{"NON", 1},
+	{"SUB", 1},
+	{"UN", 1},
+	{"RE", 1},
+}
+
+// maxAffixes limits how many affixes are removed from a single word, since every affix removed multiplies the number
+// of stems to search.
+const maxAffixes = 3
+
+// minStem is the length of the shortest stem a prefix can be removed from, so short words like RED aren't read as
+// RE- followed by a single letter.
+const minStem = 3
+
+// countAffixes counts the syllables in a word by removing prefixes and suffixes until a word in the dictionary is
+// found. Spelling changes made when adding a suffix, like the doubled consonant in RUNNING, the dropped E in MAKING and
+// the Y changed to I in HAPPINESS, are undone before looking up the stem.
+func countAffixes(word string, depth int) ([]int, Source) {
+	if depth >= maxAffixes {
+		return nil, SourceUnknown
+	}
+	for _, suffix := range suffixes {
+		if !strings.HasSuffix(word, suffix.text) || len(word) == len(suffix.text) {
+			continue
+		}
+		for _, stem := range suffixStems(word[:len(word)-len(suffix.text)], suffix) {
+			counts, ok := countStem(stem, depth)
+			if ok {
+				return addCounts(counts, []int{suffixSyllables(word, suffix)}), SourceSuffix
+			}
+		}
+	}
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(word, prefix.text) || len(word)-len(prefix.text) < minStem {
+			continue
+		}
+		counts, ok := countStem(word[len(prefix.text):], depth)
+		if ok {
+			return addCounts(counts, []int{prefix.syllables}), SourcePrefix
+		}
+	}
+	return nil, SourceUnknown
+}
+
+// countStem counts the syllables in a stem found in the dictionary, or after removing more affixes.
+func countStem(stem string, depth int) ([]int, bool) {
+	counts, ok := dict.SyllableCounts(stem)
+	if ok && len(counts) > 0 {
+		return counts, true
+	}
+	counts, source := countAffixes(stem, depth+1)
+	return counts, source != SourceUnknown
+}
+
+// suffixStems returns each stem which could have been spelled as stem before suffix was added.
+func suffixStems(stem string, suffix affix) []string {
+	result := []string{stem}
+	if suffix.text == "S" {
+		return result // no spelling changes are made before S
+	}
+	n := len(stem)
+	if n > 2 && stem[n-1] == stem[n-2] && !isVowelByte(stem[n-1]) {
+		result = append(result, stem[:n-1]) // RUNNING
+	}
+	if stem[n-1] == 'I' {
+		result = append(result, stem[:n-1]+"Y") // HAPPINESS
+	}
+	if stem[n-1] != 'E' {
+		result = append(result, stem+"E") // MAKING
+	}
+	return result
+}
+
+// suffixSyllables returns the syllables added to word by suffix. ED only adds a syllable after T or D, as in WANTED.
+func suffixSyllables(word string, suffix affix) int {
+	if suffix.text != "ED" {
+		return suffix.syllables
+	}
+	switch word[len(word)-3] {
+	case 'T', 'D':
+		return 1
+	}
+	return 0
+}
+
+func isVowelByte(b byte) bool {
+	switch b {
+	case 'A', 'E', 'I', 'O', 'U':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package haikuhammer
+
+import (
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"strconv"
+	"strings"
+)
+
+// Analysis describes how a message was counted when checking whether it is a haiku, or any other form.
+type Analysis struct {
+	Lines []Line
+	Form  Form   // the form the message matched, or the closest form if it matched none
+	Forms []Form // every form the message was checked against
+
+	LineBreaksInferred bool // true if the message was written on one line, and split into lines at word boundaries
+
+	Language Language // the language the message was read in; nil means English
+}
+
+// Line is a single line of an analysed message.
+type Line struct {
+	Text      string
+	Words     []Word // only counted if some form has the same number of lines as the message
+	Syllables []int  // every possible total of the words which could be counted, fewest first
+}
+
+// Word is a single word of an analysed message.
+type Word struct {
+	Text      string // as written in the message
+	Line      int    // index of the line containing the word
+	Position  int    // index of the word in its line
+	Syllables []int  // every number of syllables the word could be pronounced with, fewest first
+	Source    Source
+
+	Confidence float64 // for estimated words, how likely the estimate is to be right, from 0 to 1
+	Spoken     string  // for numbers, the words the number was read aloud as
+}
+
+// checker decides which messages are haiku.
+type checker struct {
+	forms           []Form                  // forms to check messages against, in order of preference
+	inferLineBreaks bool                    // if true, messages written on one line are split into lines at word boundaries
+	estimates       EstimateMode            // how to treat words which aren't in the dictionary; if empty, they are unknown
+	interjections   bool                    // if true, Interjections are counted by their usual pronunciation
+	words           map[string]db.GuildWord // words added to the dictionary, consulted before any other way of counting a word
+	mentions        map[string]string       // names to read mentions in a message as, keyed by the markup for the mention
+	language        Language                // the language messages are written in; nil means English
+}
+
+// analyse counts the syllables in each line and word of a message, and checks them against each of the provided forms
+// in order, stopping at the first match.
+func analyse(str string, forms ...Form) Analysis {
+	return checker{forms: forms}.analyse(str)
+}
+
+func (c checker) analyse(str string) Analysis {
+	trimmed := strings.Trim(str, " \n\t")
+	cleaned := stripMarkup(trimmed, c.mentions)
+	texts := strings.Split(cleaned, "\n")
+	if len(texts) == 1 {
+		texts = splitSeparators(texts[0])
+	}
+	result := Analysis{Forms: c.forms, Language: c.language}
+	for _, text := range texts {
+		result.Lines = append(result.Lines, Line{Text: text})
+	}
+	var candidates []Form
+	for _, form := range c.forms {
+		if form.Lines == len(result.Lines) {
+			candidates = append(candidates, form)
+		}
+	}
+	if len(candidates) == 0 {
+		if c.inferLineBreaks && !strings.Contains(cleaned, "\n") {
+			if inferred, ok := c.inferBreaks(cleaned); ok {
+				return inferred
+			}
+		}
+		return result
+	}
+	for i := range result.Lines {
+		c.count(&result.Lines[i], i)
+	}
+	result.Form = candidates[0]
+	for _, form := range candidates {
+		if result.known() && form.matches(result.Counts()) {
+			result.Form = form
+			break
+		}
+	}
+	return result
+}
+
+// maxInferredWords limits the length of messages which line breaks are inferred for, since most long messages aren't
+// poems and searching for line breaks in them is a waste of time.
+const maxInferredWords = 40
+
+// inferBreaks searches for word boundaries which split a single line into one of the forms, returning false if
+// none could be found.
+func (c checker) inferBreaks(text string) (Analysis, bool) {
+	line := Line{Text: text}
+	c.count(&line, 0)
+	if len(line.Words) > maxInferredWords || !line.known() {
+		return Analysis{}, false
+	}
+	for _, form := range c.forms {
+		breaks, ok := findBreaks(line.Words, form, nil)
+		if !ok {
+			continue
+		}
+		result := Analysis{Form: form, Forms: c.forms, LineBreaksInferred: true, Language: c.language}
+		start := 0
+		for i, end := range breaks {
+			var texts []string
+			for _, word := range line.Words[start:end] {
+				texts = append(texts, word.Text)
+			}
+			l := Line{Text: strings.Join(texts, " ")}
+			c.count(&l, i)
+			result.Lines = append(result.Lines, l)
+			start = end
+		}
+		return result, true
+	}
+	return Analysis{}, false
+}
+
+// findBreaks returns the index of the word after the end of each line, such that each line fits the pattern of form.
+// breaks holds the ends of the lines which have been found so far.
+func findBreaks(words []Word, form Form, breaks []int) ([]int, bool) {
+	line := len(breaks)
+	start := 0
+	if line > 0 {
+		start = breaks[line-1]
+	}
+	if line == form.Lines {
+		return breaks, start == len(words)
+	}
+	expected := form.Pattern[line]
+	counts := []int{0}
+	for end := start + 1; end <= len(words); end++ {
+		counts = addCounts(counts, words[end-1].Syllables)
+		if counts[0] > expected+form.Tolerance {
+			break // every longer line has even more syllables
+		}
+		if !form.lineMatches(counts, expected) {
+			continue
+		}
+		if result, ok := findBreaks(words, form, append(breaks, end)); ok {
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// splitSeparators splits a line written with " / " or "|" between each line of a poem. Spoilers, which are wrapped
+// in "||", are left alone.
+func splitSeparators(text string) []string {
+	var parts []string
+	switch {
+	case strings.Contains(text, " / "):
+		parts = strings.Split(text, " / ")
+	case strings.Contains(text, "|") && !strings.Contains(text, "||"):
+		parts = strings.Split(text, "|")
+	default:
+		return []string{text}
+	}
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
+
+// count counts the syllables in each word of a line, estimating any which aren't in the dictionary.
+func (c checker) count(l *Line, lineIdx int) {
+	l.Syllables = []int{0}
+	for _, text := range c.lang().Tokenize(l.Text) {
+		word := Word{Text: text, Line: lineIdx, Position: len(l.Words)}
+		word.Syllables, word.Source = c.countSyllables(text)
+		if word.Source == SourceNumber {
+			word.Spoken, _ = readNumber(text)
+		}
+		if word.Source != SourceUnknown || c.estimate(&word) {
+			l.Syllables = addCounts(l.Syllables, word.Syllables)
+		}
+		l.Words = append(l.Words, word)
+	}
+}
+
+// Text returns the analysed lines, joined by newlines.
+func (a Analysis) Text() string {
+	texts := make([]string, len(a.Lines))
+	for i, line := range a.Lines {
+		texts[i] = line.Text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// Counts returns every possible number of syllables on each line. Lines containing words which could not be counted
+// count 0.
+func (a Analysis) Counts() [][]int {
+	result := make([][]int, len(a.Lines))
+	for i, line := range a.Lines {
+		result[i] = []int{0}
+		if line.known() && len(line.Syllables) > 0 {
+			result[i] = line.Syllables
+		}
+	}
+	return result
+}
+
+// Structure renders the syllables counted on each line, e.g. 5/8/5, or 5/6–8/5 if the second line could be pronounced
+// with anywhere from 6 to 8 syllables.
+func (a Analysis) Structure() string {
+	strs := make([]string, len(a.Lines))
+	for i, counts := range a.Counts() {
+		strs[i] = describeCounts(counts)
+	}
+	return strings.Join(strs, "/")
+}
+
+// UnknownWords returns every word which could not be counted, in order.
+func (a Analysis) UnknownWords() []Word {
+	var result []Word
+	for _, line := range a.Lines {
+		for _, word := range line.Words {
+			if word.Source == SourceUnknown {
+				result = append(result, word)
+			}
+		}
+	}
+	return result
+}
+
+// OK returns true if the message matches one of the forms it was checked against.
+func (a Analysis) OK() bool {
+	return a.Form.Lines == len(a.Lines) && a.known() && a.Form.matches(a.Counts())
+}
+
+// Err returns nil if the message matches one of the forms it was checked against, an error if no form has the same
+// number of lines as the message, or a *HaikuError explaining what went wrong.
+func (a Analysis) Err() error {
+	if a.Form.Lines != len(a.Lines) {
+		return lineCountError(a.phrases(), a.Forms)
+	}
+	if a.OK() {
+		return nil
+	}
+	return &HaikuError{Analysis: a}
+}
+
+func (a Analysis) known() bool {
+	for _, line := range a.Lines {
+		if !line.known() {
+			return false
+		}
+	}
+	return true
+}
+
+func (l Line) known() bool {
+	for _, word := range l.Words {
+		if word.Source == SourceUnknown {
+			return false
+		}
+	}
+	return true
+}
+
+// describeWords lists words along with the syllables counted in each, e.g. "yeet (1), blorbo (2)".
+func describeWords(words []Word) string {
+	strs := make([]string, len(words))
+	for i, word := range words {
+		strs[i] = fmt.Sprintf("%s (%s)", word.Text, describeCounts(word.Syllables))
+	}
+	return strings.Join(strs, ", ")
+}
+
+// describeCounts renders a set of possible syllable counts as a single count, or as the range they span.
+func describeCounts(counts []int) string {
+	if len(counts) == 1 {
+		return strconv.Itoa(counts[0])
+	}
+	return fmt.Sprintf("%d–%d", counts[0], counts[len(counts)-1])
+}
+
+func joinCounts(counts []int) string {
+	strs := make([]string, len(counts))
+	for i, count := range counts {
+		strs[i] = strconv.Itoa(count)
+	}
+	return strings.Join(strs, "/")
+}
@@ -0,0 +1,84 @@
+package haikuhammer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAnalyse(t *testing.T) {
+	a := analyse("Shitposting at the W.P.A.\nzxqv hello :wink: prosey\n...", FormHaiku)
+
+	assert.Equal(t, FormHaiku, a.Form)
+	if assert.Len(t, a.Lines, 3) {
+		assert.Equal(t, []Word{
+			{Text: "Shitposting", Line: 0, Position: 0, Syllables: []int{3}, Source: SourceCompound},
+			{Text: "at", Line: 0, Position: 1, Syllables: []int{1}, Source: SourceDictionary},
+			{Text: "the", Line: 0, Position: 2, Syllables: []int{1}, Source: SourceDictionary},
+			{Text: "W.P.A.", Line: 0, Position: 3, Syllables: []int{5}, Source: SourceAbbreviation},
+		}, a.Lines[0].Words)
+		assert.Equal(t, []int{10}, a.Lines[0].Syllables)
+		assert.Equal(t, []Word{
+			{Text: "zxqv", Line: 1, Position: 0, Source: SourceUnknown},
+			{Text: "hello", Line: 1, Position: 1, Syllables: []int{2}, Source: SourceDictionary},
+			{Text: "prosey", Line: 1, Position: 2, Syllables: []int{2}, Source: SourceSuffix},
+		}, a.Lines[1].Words)
+		assert.Equal(t, []int{4}, a.Lines[1].Syllables, "unknown words are not counted")
+		assert.Equal(t, []Word{{Text: "...", Line: 2, Position: 0, Syllables: []int{0}, Source: SourcePunctuation}}, a.Lines[2].Words)
+	}
+	assert.Equal(t, []Word{{Text: "zxqv", Line: 1, Position: 0, Source: SourceUnknown}}, a.UnknownWords())
+	assert.Equal(t, [][]int{{10}, {0}, {0}}, a.Counts())
+	assert.False(t, a.OK())
+	assert.Equal(t, "Hmmm, this doesn't seem like a traditional English Haiku; here's why:\n"+
+		"- I don't know the words: zxqv\n"+
+		"- I counted a syllable structure of 10/0/0, but I expected 5/7/5", a.Err().Error())
+
+	a = analyse(testHaiku, FormHaiku)
+	assert.True(t, a.OK())
+	assert.NoError(t, a.Err())
+
+	a = analyse("one line", FormHaiku)
+	assert.Len(t, a.Lines, 1)
+	assert.Empty(t, a.Lines[0].Words, "words are not counted when the number of lines is wrong")
+	assert.Equal(t, ErrThreeLines, a.Err())
+}
+
+func TestAnalyse_Separators(t *testing.T) {
+	for _, text := range []string{
+		"Another test case / For the automated bot / Here’s a nice haiku",
+		"Another test case | For the automated bot | Here’s a nice haiku",
+		"Another test case|For the automated bot|Here’s a nice haiku",
+	} {
+		a := analyse(text, FormHaiku)
+		assert.NoError(t, a.Err(), text)
+		assert.Equal(t, testHaiku, a.Text(), text)
+		assert.False(t, a.LineBreaksInferred, text)
+	}
+
+	assert.Equal(t, ErrThreeLines, analyse("Another test case ||For the automated bot|| Here’s a nice haiku", FormHaiku).Err(),
+		"spoilers are not separators")
+	assert.Equal(t, ErrThreeLines, analyse("Another test case For the automated bot Here’s a nice haiku", FormHaiku).Err(),
+		"line breaks are only inferred if enabled")
+}
+
+func TestAnalyse_InferLineBreaks(t *testing.T) {
+	c := checker{forms: []Form{FormHaiku, FormTanka}, inferLineBreaks: true}
+
+	a := c.analyse("Another test case For the automated bot Here’s a nice haiku")
+	assert.NoError(t, a.Err())
+	assert.True(t, a.LineBreaksInferred)
+	assert.Equal(t, testHaiku, a.Text())
+	assert.Equal(t, []Word{
+		{Text: "For", Line: 1, Position: 0, Syllables: []int{1}, Source: SourceDictionary},
+		{Text: "the", Line: 1, Position: 1, Syllables: []int{1}, Source: SourceDictionary},
+		{Text: "automated", Line: 1, Position: 2, Syllables: []int{4}, Source: SourceDictionary},
+		{Text: "bot", Line: 1, Position: 3, Syllables: []int{1}, Source: SourceDictionary},
+	}, a.Lines[1].Words)
+
+	a = c.analyse("Another test case For the automated bot Here’s a nice haiku For the automated bot Another seven right here")
+	assert.NoError(t, a.Err())
+	assert.Equal(t, FormTanka, a.Form)
+	assert.Equal(t, testTanka, a.Text())
+
+	assert.False(t, c.analyse("Another test case For the automated bot Here’s a haiku").LineBreaksInferred)
+	assert.False(t, c.analyse("Another test case For the zxqv bot Here’s a nice haiku").LineBreaksInferred)
+}
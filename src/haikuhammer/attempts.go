@@ -0,0 +1,108 @@
+package haikuhammer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// recordAttempt remembers why a three-line message was not accepted as a haiku, so admins can review near-misses and
+// dictionary maintainers can find the words which most often block haiku.
+func (h *HaikuHammer) recordAttempt(m *Message, err error) {
+	var haikuErr *HaikuError
+	if !errors.As(err, &haikuErr) || m.GuildID == "" {
+		return
+	}
+	gid, cid, mid, err := idToInt(m)
+	if err != nil {
+		return
+	}
+	ctx := context.Background()
+	var words []string
+	for _, word := range haikuErr.UnknownWords() {
+		if cleaned := cleanWord(word.Text); cleaned != "" {
+			words = append(words, cleaned)
+		}
+	}
+	err = h.store.SaveAttempt(ctx, db.Attempt{
+		GuildID:      gid,
+		ChannelID:    cid,
+		MessageID:    mid,
+		AuthorID:     m.AuthorID,
+		CreatedAt:    h.timestamp(m).Unix(),
+		Content:      m.Content,
+		Syllables:    haikuErr.Structure(),
+		UnknownWords: strings.Join(wordTexts(haikuErr.UnknownWords()), ", "),
+	}, words)
+	if err != nil {
+		log.Println("could not record haiku attempt,", err)
+	}
+}
+
+func wordTexts(words []Word) []string {
+	result := make([]string, len(words))
+	for i, word := range words {
+		result[i] = word.Text
+	}
+	return result
+}
+
+func (h *HaikuHammer) deleteAttempt(ctx context.Context, mid int) {
+	err := h.store.DeleteAttempt(ctx, mid)
+	if err != nil {
+		log.Println("could not delete haiku attempt,", err)
+	}
+}
+
+// attemptList lists the most recent messages in a guild which were rejected, along with the reasons why.
+func (h *HaikuHammer) attemptList(guildID string) string {
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return "I couldn't look up recent attempts, please try again later"
+	}
+	attempts, err := h.store.RecentAttempts(context.Background(), gid, 5)
+	if err != nil {
+		log.Println("could not list haiku attempts,", err)
+		return "I couldn't look up recent attempts, please try again later"
+	}
+	if len(attempts) == 0 {
+		return "Nobody has tried and failed to write a haiku here yet"
+	}
+	var result strings.Builder
+	result.WriteString("Recent attempts which weren't haiku:")
+	for _, attempt := range attempts {
+		result.WriteString(fmt.Sprintf("\n%s\n> - %s; I counted %s", quote(attempt.Content), h.nick(gid, attempt.AuthorID), attempt.Syllables))
+		if attempt.UnknownWords != "" {
+			result.WriteString(" and didn't know " + attempt.UnknownWords)
+		}
+	}
+	return result.String()
+}
+
+// attemptWords lists the unknown words which most often stopped messages in a guild from being accepted as haiku.
+func (h *HaikuHammer) attemptWords(guildID string) string {
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return "I couldn't look up unknown words, please try again later"
+	}
+	words, err := h.store.TopWords(context.Background(), gid, 10)
+	if err != nil {
+		log.Println("could not list unknown words,", err)
+		return "I couldn't look up unknown words, please try again later"
+	}
+	if len(words) == 0 {
+		return "No haiku have been blocked by words I don't know here yet"
+	}
+	var result strings.Builder
+	result.WriteString("Words I didn't know which most often blocked a haiku:")
+	for i, word := range words {
+		result.WriteString(fmt.Sprintf("\n%d. %s - %s", i+1, strings.ToLower(word.Word), pluralize(word.Attempts, "attempt")))
+	}
+	return result.String()
+}
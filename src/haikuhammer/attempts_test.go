@@ -0,0 +1,35 @@
+package haikuhammer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAttempts(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.addMember(gid, "2", "Basho")
+
+	listID := h.post(gid, cid, "1", "!haiku attempts list")
+	assert.Equal(t, []string{"Nobody has tried and failed to write a haiku here yet"}, h.replies(listID))
+
+	h.post(gid, cid, "2", "Banana man why\nmake so many haiku\nDo you have job")
+	h.post(gid, cid, "2", "y do u go now\nzxqv wrote a bot\nzxqv, big fill coming")
+	h.post(gid, cid, "2", "it's not a haiku") // only three-line messages are attempts
+	fixedID := h.post(gid, cid, "2", "Another test case\nFor the automated bot\nHere’s a haiku")
+	h.edit(fixedID, testHaiku) // fixed attempts are forgotten
+
+	listID = h.post(gid, cid, "1", "!haiku attempts list")
+	assert.Equal(t, []string{"Recent attempts which weren't haiku:\n" +
+		"> y do u go now\n> zxqv wrote a bot\n> zxqv, big fill coming\n> - Basho; I counted 5/0/0 and didn't know zxqv, zxqv,\n" +
+		"> Banana man why\n> make so many haiku\n> Do you have job\n> - Basho; I counted 5/6/4"}, h.replies(listID))
+
+	wordsID := h.post(gid, cid, "1", "!haiku attempts words")
+	assert.Equal(t, []string{"Words I didn't know which most often blocked a haiku:\n1. zxqv - 1 attempt"}, h.replies(wordsID))
+
+	h.post(gid, cid, "2", "!haiku attempts words")
+	assert.Equal(t, []string{"You do not have permissions to manage HaikuHammer in " + channelMention(cid)}, h.dms("2"))
+
+	resp := h.command(gid, cid, "1", subcommand("attempts", subcommand("words")))
+	assert.Equal(t, "Words I didn't know which most often blocked a haiku:\n1. zxqv - 1 attempt", resp.Data.Content)
+}
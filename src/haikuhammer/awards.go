@@ -0,0 +1,237 @@
+package haikuhammer
+
+import (
+	"context"
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AwardPeriod is a span of time over which votes for haiku are tallied to pick a winner.
+type AwardPeriod string
+
+const (
+	AwardWeek  AwardPeriod = "week"
+	AwardMonth AwardPeriod = "month"
+	AwardYear  AwardPeriod = "year"
+)
+
+// AwardPeriods lists every valid AwardPeriod.
+var AwardPeriods = []AwardPeriod{AwardWeek, AwardMonth, AwardYear}
+
+// ParseAwardPeriod finds the AwardPeriod with the provided name, ignoring case.
+func ParseAwardPeriod(s string) (AwardPeriod, error) {
+	for _, p := range AwardPeriods {
+		if strings.EqualFold(s, string(p)) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("could not understand '%s' as a valid period; expected one of week, month or year", s)
+}
+
+// Title names the award given for this period.
+func (p AwardPeriod) Title() string {
+	switch p {
+	case AwardWeek:
+		return "Haiku of the Week"
+	case AwardMonth:
+		return "Haiku of the Month"
+	default:
+		return "Haiku of the Year"
+	}
+}
+
+// Start returns the beginning of the period containing t. Weeks start on Monday; all periods start at midnight UTC.
+func (p AwardPeriod) Start(t time.Time) time.Time {
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch p {
+	case AwardWeek:
+		return midnight.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	case AwardMonth:
+		return midnight.AddDate(0, 0, 1-t.Day())
+	default:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Previous returns the bounds of the most recent period to have ended before t.
+func (p AwardPeriod) Previous(t time.Time) (start, end time.Time) {
+	end = p.Start(t)
+	return p.Start(end.Add(-time.Second)), end
+}
+
+// describe renders the span of time covered by a period starting at start.
+func (p AwardPeriod) describe(start time.Time) string {
+	switch p {
+	case AwardWeek:
+		return "week of " + start.Format("Jan 2, 2006")
+	case AwardMonth:
+		return start.Format("January 2006")
+	default:
+		return start.Format("2006")
+	}
+}
+
+// HandleReactionAdd counts a reaction to a saved haiku as a vote.
+func (h *HaikuHammer) HandleReactionAdd(r *Reaction) {
+	if r == nil || r.UserBot {
+		return
+	}
+	mid, err := strconv.Atoi(r.MessageID)
+	if err != nil {
+		log.Println("could not parse messageID as integer,", r.MessageID)
+		return
+	}
+	err = h.store.AddVote(context.Background(), mid, r.UserID, r.Emoji, h.now().Unix())
+	if err != nil {
+		log.Println("could not record vote,", err)
+	}
+}
+
+// HandleReactionRemove retracts a vote previously counted by HandleReactionAdd.
+func (h *HaikuHammer) HandleReactionRemove(r *Reaction) {
+	if r == nil || r.UserBot {
+		return
+	}
+	mid, err := strconv.Atoi(r.MessageID)
+	if err != nil {
+		log.Println("could not parse messageID as integer,", r.MessageID)
+		return
+	}
+	err = h.store.RemoveVote(context.Background(), mid, r.UserID, r.Emoji)
+	if err != nil {
+		log.Println("could not remove vote,", err)
+	}
+}
+
+// runAwards periodically presents awards until the bot is closed.
+func (h *HaikuHammer) runAwards(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.PresentAwards(h.now())
+		select {
+		case <-ticker.C:
+		case <-h.done:
+			return
+		}
+	}
+}
+
+// PresentAwards announces the winners of every configured period which ended before now, in each guild which has an
+// award channel. Each period is only ever decided once.
+func (h *HaikuHammer) PresentAwards(now time.Time) {
+	ctx := context.Background()
+	channels, err := h.store.AwardChannels(ctx)
+	if err != nil {
+		log.Println("could not retrieve award channels,", err)
+		return
+	}
+	for _, c := range channels {
+		for _, period := range h.config.AwardPeriods {
+			h.presentAward(ctx, c, period, now)
+		}
+	}
+}
+
+func (h *HaikuHammer) presentAward(ctx context.Context, c db.AwardChannel, period AwardPeriod, now time.Time) {
+	start, end := period.Previous(now)
+	existing, err := h.store.FindAward(ctx, c.GuildID, string(period), start.Unix())
+	if err != nil {
+		log.Println("could not look up award,", err)
+		return
+	}
+	if existing.Period != "" {
+		return // already decided
+	}
+	winner, err := h.store.AwardWinner(ctx, c.GuildID, start.Unix(), end.Unix())
+	if err != nil {
+		log.Println("could not find award winner,", err)
+		return
+	}
+	inserted, err := h.store.InsertAward(ctx, db.Award{
+		GuildID:   c.GuildID,
+		Period:    string(period),
+		StartsAt:  start.Unix(),
+		MessageID: winner.MessageID,
+		Votes:     winner.Votes,
+	})
+	if err != nil {
+		log.Println("could not record award,", err)
+		return
+	}
+	if !inserted || winner.MessageID == 0 {
+		return // decided elsewhere, or nobody voted
+	}
+	announcement := fmt.Sprintf("🏆 %s for the %s, with %s:\n%s",
+		period.Title(), period.describe(start), pluralize(winner.Votes, "vote"), h.presentHaiku(winner.Haiku))
+	err = h.platform.Send(strconv.Itoa(c.ChannelID), announcement)
+	if err != nil {
+		log.Println("could not announce award,", err)
+	}
+}
+
+// updateAwardChannel sets the channel awards are announced to in a guild, or stops announcements if the command
+// target is empty.
+func (h *HaikuHammer) updateAwardChannel(guildID string, command Command) string {
+	ctx := context.Background()
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return "I couldn't update the award channel, please try again later"
+	}
+	if command.Target == "" {
+		err = h.store.DeleteAwardChannel(ctx, gid)
+		if err != nil {
+			log.Println("could not delete award channel,", err)
+			return "I couldn't update the award channel, please try again later"
+		}
+		return "Stopped announcing awards"
+	}
+	cid, err := strconv.Atoi(command.Target)
+	if err != nil {
+		log.Println("could not parse channelID as integer,", command.Target)
+		return "I couldn't update the award channel, please try again later"
+	}
+	err = h.store.SaveAwardChannel(ctx, db.AwardChannel{GuildID: gid, ChannelID: cid})
+	if err != nil {
+		log.Println("could not update award channel,", err)
+		return "I couldn't update the award channel, please try again later"
+	}
+	return "Awards will be announced in " + command.MentionTarget()
+}
+
+// awardList lists the most recent winners of an award in a guild.
+func (h *HaikuHammer) awardList(guildID string, period AwardPeriod) string {
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return "I couldn't look up past awards, please try again later"
+	}
+	winners, err := h.store.ListAwards(context.Background(), gid, string(period), 5)
+	if err != nil {
+		log.Println("could not list awards,", err)
+		return "I couldn't look up past awards, please try again later"
+	}
+	if len(winners) == 0 {
+		return fmt.Sprintf("Nobody has won %s yet", period.Title())
+	}
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Past winners of %s:", period.Title()))
+	for _, winner := range winners {
+		start := time.Unix(winner.StartsAt, 0).UTC()
+		result.WriteString(fmt.Sprintf("\n**%s**, with %s:\n%s", period.describe(start), pluralize(winner.Votes, "vote"), h.presentHaiku(winner.Haiku)))
+	}
+	return result.String()
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
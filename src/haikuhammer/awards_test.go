@@ -0,0 +1,77 @@
+package haikuhammer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+const otherHaiku = "Crisp autumn morning\nA cat naps beside the stove\nThe kettle whistles"
+
+func TestAwardPeriod_Previous(t *testing.T) {
+	now := time.Date(2021, time.March, 10, 12, 0, 0, 0, time.UTC) // a Wednesday
+
+	start, end := AwardWeek.Previous(now)
+	assert.Equal(t, time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2021, time.March, 8, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = AwardMonth.Previous(now)
+	assert.Equal(t, time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = AwardYear.Previous(now)
+	assert.Equal(t, time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestPresentAwards(t *testing.T) {
+	h := newHarness(t)
+	h.hammer.config.AwardPeriods = AwardPeriods
+	h.hammer.now = func() time.Time { return time.Date(2021, time.March, 10, 12, 0, 0, 0, time.UTC) }
+	gid, cid := h.addGuild("1")
+	awardCID := h.addChannel(gid)
+	h.addMember(gid, "2", "Basho")
+	h.addMember(gid, "3", "Buson")
+
+	setID := h.post(gid, cid, "1", "!haiku awards channel "+channelMention(awardCID))
+	assert.Equal(t, []string{"Awards will be announced in " + channelMention(awardCID)}, h.replies(setID))
+
+	firstID := h.post(gid, cid, "2", testHaiku)
+	secondID := h.post(gid, cid, "3", otherHaiku)
+	nonHaikuID := h.post(gid, cid, "4", testNonHaiku)
+
+	h.react(gid, cid, firstID, "4", "👍")
+	h.react(gid, cid, secondID, "4", "👍")
+	h.react(gid, cid, secondID, "4", "❤") // a second emoji from the same user is not another vote
+	h.react(gid, cid, secondID, "5", "👍")
+	h.react(gid, cid, secondID, "3", "👍")       // authors can't vote for themselves
+	h.react(gid, cid, secondID, fakeBotID, "👍") // neither can bots
+	h.react(gid, cid, nonHaikuID, "5", "👍")
+	h.react(gid, cid, firstID, "5", "👍")
+	h.unreact(gid, cid, firstID, "5", "👍")
+
+	h.hammer.PresentAwards(time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC))
+	assert.Empty(t, h.messages(awardCID), "awards are not presented before the week is over")
+
+	nextWeek := time.Date(2021, time.March, 15, 1, 0, 0, 0, time.UTC)
+	h.hammer.PresentAwards(nextWeek)
+	h.hammer.PresentAwards(nextWeek)
+	announcements := h.messages(awardCID)
+	require.Len(t, announcements, 1, "each award is only presented once")
+	assert.Contains(t, announcements[0], "🏆 Haiku of the Week for the week of Mar 8, 2021, with 2 votes:")
+	assert.Contains(t, announcements[0], "Buson")
+
+	listID := h.post(gid, cid, "2", "!haiku awards list week")
+	replies := h.replies(listID)
+	require.Len(t, replies, 1, "anyone can list awards")
+	assert.Contains(t, replies[0], "Past winners of Haiku of the Week:\n**week of Mar 8, 2021**, with 2 votes:")
+
+	listID = h.post(gid, cid, "2", "!haiku awards list year")
+	assert.Equal(t, []string{"Nobody has won Haiku of the Year yet"}, h.replies(listID))
+
+	resp := h.command(gid, cid, "2", subcommand("awards", subcommand("channel")))
+	assert.Equal(t, "You do not have permissions to manage HaikuHammer in "+channelMention(cid), resp.Data.Content)
+	resp = h.command(gid, cid, "1", subcommand("awards", subcommand("channel")))
+	assert.Equal(t, "Stopped announcing awards", resp.Data.Content)
+}
@@ -3,16 +3,17 @@ package haikuhammer
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"github.com/bwmarrin/discordgo"
+	"github.com/kalexmills/haiku-enforcer/src/dict"
 	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
 	"log"
 	"math/rand"
 	"runtime/debug"
 	"strconv"
 	"strings"
+	"time"
 
-	_ "github.com/mattn/go-sqlite3"
 )
 
 type Config struct {
@@ -22,86 +23,99 @@ type Config struct {
 	BotUsername string
 	PositiveReacts []string
 	NegativeReacts []string
+	QuotationReacts []string // reactions to famous haiku; PositiveReacts are used if empty
 
 	Debug bool
 
-	DBPath string
+	DBDriver string // database to connect to; sqlite3 or postgres
+	DBPath   string // path to a SQLite database file, or a connection string for Postgres
+
+	AwardPeriods  []AwardPeriod // periods for which a haiku is awarded
+	AwardInterval time.Duration // how often to check for periods which have ended; 0 disables awards
+
+	GlobalModerators []string // IDs of users who may approve suggested words for every guild
+
+	DictionaryDir string // directory of extra dictionary files to load and watch; empty disables them
 }
 
 func (c Config) String() string {
-	return fmt.Sprintf("\tReactToHaiku: %t\n\tReactToNonHaiku: %t\n\tDeleteNonHaiku: %t\n\tExplainNonHaiku: %t\n\tServeRandomHaiku: %t\n",
-		c.ActionFlags.ReactToHaiku(), c.ActionFlags.ReactToNonHaiku(), c.ActionFlags.DeleteNonHaiku(), c.ActionFlags.ExplainNonHaiku(), c.ActionFlags.ServeRandomHaiku())
+	return fmt.Sprintf("\tReactToHaiku: %t\n\tReactToNonHaiku: %t\n\tDeleteNonHaiku: %t\n\tExplainNonHaiku: %t\n\tServeRandomHaiku: %t\n\tInferLineBreaks: %t\n\tCountInterjections: %t\n\tReplyToCopies: %t\n",
+		c.ActionFlags.ReactToHaiku(), c.ActionFlags.ReactToNonHaiku(), c.ActionFlags.DeleteNonHaiku(), c.ActionFlags.ExplainNonHaiku(), c.ActionFlags.ServeRandomHaiku(), c.ActionFlags.InferLineBreaks(), c.ActionFlags.CountInterjections(), c.ActionFlags.ReplyToCopies())
 }
 
 type HaikuHammer struct {
-	session *discordgo.Session
-	db *sql.DB
+	platform ChatPlatform
+	discord *DiscordPlatform
+	store db.Store
 
 	config  Config
 
-	dmCache map[string]bool // maps from channelIDs to whether they're DM channels or not
-	dmChannelCache map[string]string // maps from userIDs to their DM channel ID
-
-	botID string
+	now  func() time.Time
+	done chan struct{} // closed when the bot shuts down
 }
 
 func NewHaikuHammer(config Config) HaikuHammer {
 	log.Printf("Haiku Bot Config:\n%v", config)
 	return HaikuHammer{
 		config: config,
-		dmCache: make(map[string]bool),
-		dmChannelCache: make(map[string]string),
+		now: time.Now,
+		done: make(chan struct{}),
 	}
 }
 
-func (h *HaikuHammer) Open() error {
-	err := h.OpenDB()
-	if err != nil {
-		return err
+// NewHaikuHammerWithPlatform creates a HaikuHammer which moderates messages using the provided platform and
+// store.
+func NewHaikuHammerWithPlatform(config Config, platform ChatPlatform, store db.Store) *HaikuHammer {
+	return &HaikuHammer{
+		config: config,
+		platform: platform,
+		store: store,
+		now: time.Now,
+		done: make(chan struct{}),
 	}
+}
 
-	go UpdateHashes(h.db) // start a new thread for updating all the hashes
-
-	h.session, err = discordgo.New("Bot " + h.config.Token)
+func (h *HaikuHammer) Open() error {
+	err := h.OpenDB()
 	if err != nil {
-		log.Println("error creating Discord session,", err)
 		return err
 	}
 
-	if h.config.Debug {
-		h.session.LogLevel = discordgo.LogDebug
-	}
-	h.session.StateEnabled = true
-
-	h.session.AddHandler(h.ReceiveMessageCreate)
-	h.session.AddHandler(h.ReceiveMessageEdit)
+	go UpdateHashes(h.store) // start a new thread for updating all the hashes
 
-	h.session.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages
-	if h.config.ActionFlags.ReactToNonHaiku() || h.config.ActionFlags.ReactToHaiku() {
-		h.session.Identify.Intents |= discordgo.IntentsGuildMessageReactions | discordgo.IntentsDirectMessageReactions
+	if h.config.DictionaryDir != "" {
+		err = dict.LoadDir(h.config.DictionaryDir)
+		if err != nil {
+			return fmt.Errorf("could not load dictionary files: %w", err)
+		}
+		err = dict.Watch(h.config.DictionaryDir, h.done)
+		if err != nil {
+			log.Println("could not watch dictionary files,", err)
+		}
 	}
 
-	err = h.session.Open()
+	h.discord, err = NewDiscordPlatform(h.config, h)
 	if err != nil {
-		log.Println("error opening connection,", err)
+		log.Println("error creating Discord session,", err)
 		return err
 	}
+	h.platform = h.discord
 
-	user, err := h.session.User("@me")
+	err = h.discord.Open()
 	if err != nil {
-		log.Println("error looking up bot user", err)
 		return err
 	}
-	h.botID = user.ID
-	log.Println("Bot running as username: ", user.Username + "#" + user.Discriminator)
 
+	if h.config.AwardInterval > 0 && len(h.config.AwardPeriods) > 0 {
+		go h.runAwards(h.config.AwardInterval)
+	}
 	return nil
 }
 
 func (h *HaikuHammer) OpenDB() error {
-	DB, err := sql.Open("sqlite3", h.config.DBPath+"?cache=shared&mode=rwc")
+	DB, err := ConnectDB(h.config)
 	if err != nil {
-		return fmt.Errorf("cannot open database from %s: %w", h.config.DBPath, err)
+		return err
 	}
 
 	err = db.BootstrapDB(DB)
@@ -109,231 +123,227 @@ func (h *HaikuHammer) OpenDB() error {
 		return fmt.Errorf("could not bootstrap database: %w", err)
 	}
 
-	_, err = DB.Exec("PRAGMA journal_mode=WAL;")
-	if err != nil {
-		return fmt.Errorf("could not set journal mode: %w", err)
-	}
-
-	h.db = DB
+	h.store = db.NewSQLStore(DB)
 	return nil
 }
 
-func (h *HaikuHammer) Close() error {
-	return h.session.Close()
+// ConnectDB connects to the database described by a config, without changing its schema.
+func ConnectDB(c Config) (*sql.DB, error) {
+	driver := c.DBDriver
+	if driver == "" {
+		driver = string(db.SQLite)
+	}
+	dialect, ok := db.LookupDialect(driver)
+	if !ok {
+		return nil, fmt.Errorf("unknown database driver %s; expected one of %s", driver, db.Dialects)
+	}
+	DB, err := db.Open(dialect, c.DBPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s database: %w", dialect, err)
+	}
+	return DB, nil
 }
 
-func (h *HaikuHammer) ReceiveMessageEdit(s *discordgo.Session, m *discordgo.MessageUpdate) {
-	h.HandleMessage(h.session, m.Message)
+func (h *HaikuHammer) Close() error {
+	close(h.done)
+	return h.discord.Close()
 }
 
-func (h *HaikuHammer) ReceiveMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
-	if strings.HasPrefix(m.Content, "!haiku ") {
-		h.HandleAdminCommand(h.session, m.Message)
+// ReceiveMessage handles a newly created message, dispatching it as an admin command if it is one.
+func (h *HaikuHammer) ReceiveMessage(m *Message) {
+	if m != nil && strings.HasPrefix(m.Content, "!haiku ") {
+		h.HandleAdminCommand(m)
 		return
 	}
-	h.HandleMessage(h.session, m.Message)
+	h.HandleMessage(m)
 }
 
-func (h *HaikuHammer) HandleMessage(s *discordgo.Session, m *discordgo.Message) { // TODO: remove s from everywhere.
+func (h *HaikuHammer) HandleMessage(m *Message) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("Recovered from panic on content, %s, panicking on: %v\n%v", strings.ReplaceAll(m.Content, "\n","\\n"), r, debug.Stack())
 			panic(r)
 		}
 	}()
-	if m == nil || m.Author == nil || m.Author.Bot { // prevent dumb APIs and bot messages
+	if m == nil || m.AuthorID == "" || m.AuthorBot { // prevent dumb APIs and bot messages
 		return
 	}
 
 	gid := m.GuildID // store original guild ID
-	m, err := s.ChannelMessage(m.ChannelID, m.ID)
+	m, err := h.platform.FetchMessage(m.ChannelID, m.ID)
 	if err != nil {
 		log.Println("could not look up message from channel", err)
 		return
 	}
 	m.GuildID = gid
 
-	if err := IsHaiku(m.Content); err == nil {
-		log.Printf("received haiku: %s\n", strings.ReplaceAll(m.Content, "\n","\\n"))
-		h.HandleHaiku(s, m)
+	analysis := h.checker(m).analyse(m.Content)
+	if err := analysis.Err(); err == nil {
+		log.Printf("received %s: %s\n", analysis.Form.Name, strings.ReplaceAll(m.Content, "\n","\\n"))
+		h.HandleHaiku(m, analysis)
 	} else {
-		h.HandleNonHaiku(s, m, err)
+		h.HandleNonHaiku(m, err)
+	}
+}
+
+// HandleHaiku handles a message which matched one of the forms it was checked against.
+func (h *HaikuHammer) HandleHaiku(m *Message, analysis Analysis) {
+	if h.actionsEnabled(m, db.ConfigDeleteNonHaiku) && h.violatesEnforcedForms(m) {
+		h.Delete(m)
+		return
 	}
+	if q, ok := findQuotation(m.Content); ok {
+		h.HandleQuotation(m, q)
+		return
+	}
+	if h.actionsEnabled(m, db.ConfigReactToHaiku) && m.MyReaction == "" {
+		h.react(m, randomString(h.config.PositiveReacts))
+		if analysis.LineBreaksInferred {
+			h.reply(m, analysis.phrases().ReadAs+"\n"+quote(analysis.Text()))
+		}
+		if flagged := analysis.FlaggedWords(); len(flagged) > 0 && h.estimateMode(m) == EstimateFlag {
+			h.reply(m, analysis.phrases().FlaggedWords+describeWords(flagged))
+		}
+	}
+	h.saveHaiku(m, analysis.Form)
 }
 
-func (h *HaikuHammer) HandleHaiku(s *discordgo.Session, m *discordgo.Message) {
-	if r := myReaction(m); h.actionsEnabled(m, db.ConfigReactToHaiku) && r == nil {
-		h.react(s, m, randomString(h.config.PositiveReacts))
+// checker returns the checker used to decide whether messages in the channel a message was sent to are haiku.
+func (h *HaikuHammer) checker(m *Message) checker {
+	return checker{
+		forms:           h.forms(m),
+		inferLineBreaks: h.actionsEnabled(m, db.ConfigInferLineBreaks),
+		interjections:   h.actionsEnabled(m, db.ConfigCountInterjections),
+		words:           h.guildWords(m),
+		estimates:       h.estimateMode(m),
+		mentions:        h.platform.MentionNames(m.GuildID, m.Content),
+		language:        h.language(m),
 	}
-	h.saveHaiku(m)
 }
 
-func (h *HaikuHammer) HandleNonHaiku(s *discordgo.Session, m *discordgo.Message, err error) {
+func (h *HaikuHammer) HandleNonHaiku(m *Message, err error) {
+	h.recordAttempt(m, err)
+
 	if h.actionsEnabled(m, db.ConfigServeRandomHaiku) {
-		if h.mentionsMe(m) {
-			h.replyWithRandomHaiku(s, m)
+		if m.MentionsBot {
+			h.replyWithRandomHaiku(m)
 			return
 		}
 	}
 
-	if h.actionsEnabled(m, db.ConfigDeleteNonHaiku) {
-		h.Delete(s, m)
+	if h.actionsEnabled(m, db.ConfigDeleteNonHaiku) && h.violatesEnforcedForms(m) {
+		h.Delete(m)
 		return
 	}
 
 	if h.actionsEnabled(m, db.ConfigReactToHaiku) {
-		h.removeReaction(s, m)
+		h.removeReaction(m)
 	}
 
 	if h.actionsEnabled(m, db.ConfigReactToNonHaiku) {
-		h.react(s, m, randomString(h.config.NegativeReacts))
+		h.react(m, randomString(h.config.NegativeReacts))
 		log.Println("reacted to non-haiku,", m.ID, strings.ReplaceAll(m.Content, "\n", "\\n"))
 	}
 
-	if isDM, err2 := h.isDM(s, m.ChannelID); err2 == nil &&
+	if isDM, err2 := h.platform.IsDM(m.ChannelID); err2 == nil &&
 		((isDM && h.config.ActionFlags.ExplainNonHaiku()) || // explain non-haiku in all DMs if globally configured
 			h.actionsEnabled(m, db.ConfigExplainNonHaiku)) { // also explain non-haiku in any specially-enabled channels
-		h.ExplainHaiku(s, m, err)
+		h.ExplainHaiku(m, err)
 	} else if err2 != nil {
 		log.Println("could not lookup channel,", err)
 	}
 }
 
-func (h *HaikuHammer) Delete(s *discordgo.Session, m *discordgo.Message) {
-	err := s.ChannelMessageDelete(m.ChannelID, m.ID)
+func (h *HaikuHammer) Delete(m *Message) {
+	err := h.platform.Delete(m.ChannelID, m.ID)
 	if err != nil {
 		log.Println("could not delete message from channel,", err)
 		return
 	}
-	dmChannelID, err := h.getDMChannelID(s, m.Author.ID)
-	if err != nil {
-		log.Println("could not create user DM channel,", err)
-		return
-	}
-	explanation := fmt.Sprintf("I deleted the message you just sent to %s since I didn't think it was a proper Haiku:\n %s", channelMention(m.ChannelID), quote(m.Content))
-	_, err = s.ChannelMessageSend(dmChannelID, explanation)
-	if err != nil {
-		log.Println("could not send message to user DM channel,", err)
-		return
-	}
+	explanation := fmt.Sprintf(h.language(m).Phrases().Deleted+"\n %s", channelMention(m.ChannelID), quote(m.Content))
+	h.DM(m, explanation)
 	log.Println("deleted message,", m.ID, strings.ReplaceAll(m.Content, "\n", "\\n"))
 }
 
-func (h *HaikuHammer) ExplainHaiku(s *discordgo.Session, m *discordgo.Message, explainErr error) {
+func (h *HaikuHammer) ExplainHaiku(m *Message, explainErr error) {
 	if explainErr == nil {
 		log.Println("tried to explain a non-haiku without an error,", strings.ReplaceAll(m.Content, "\n", "\\n"))
 		return
 	}
-	_, err := s.ChannelMessageSendReply(m.ChannelID, explainErr.Error(), m.MessageReference)
-	if err != nil {
-		log.Println("could not send message to channel,", err)
-		return
+	response := explainErr.Error()
+	var haikuErr *HaikuError
+	if errors.As(explainErr, &haikuErr) && len(haikuErr.UnknownWords()) > 0 {
+		response += "\n" + haikuErr.phrases().Teach
 	}
+	h.reply(m, response)
 }
 
-func (h *HaikuHammer) DM(s *discordgo.Session, m *discordgo.Message, response string) {
-	dmChannelID, err := h.getDMChannelID(s, m.Author.ID)
-	if err != nil {
-		log.Println("could not create user DM channel,", err)
-		return
-	}
-	_, err = s.ChannelMessageSend(dmChannelID, response)
+func (h *HaikuHammer) DM(m *Message, response string) {
+	err := h.platform.DM(m.AuthorID, response)
 	if err != nil {
 		log.Println("could not send message to user DM channel,", err)
 		return
 	}
 }
 
-func (h *HaikuHammer) isDM(s *discordgo.Session, channelID string) (bool, error) {
-	if result, ok := h.dmCache[channelID]; ok {
-		return result, nil
-	}
-	c, err := s.Channel(channelID)
+func (h *HaikuHammer) reply(m *Message, response string) {
+	err := h.platform.Reply(m.ChannelID, m.ID, response)
 	if err != nil {
-		return false, err
-	}
-	log.Println("looked up channel", channelID)
-	result := c.Type == discordgo.ChannelTypeDM && len(c.Recipients) == 1
-	h.dmCache[channelID] = result
-	return result, nil
-}
-
-func myReaction(m *discordgo.Message) *discordgo.MessageReactions {
-	for _, reaction := range m.Reactions {
-		if reaction.Me {
-			return reaction
-		}
+		log.Println("could not send message reply,", err)
+		return
 	}
-	return nil
 }
 
-func (h *HaikuHammer) removeReaction(s *discordgo.Session, m *discordgo.Message) {
-	r := myReaction(m)
-	if r == nil {
+func (h *HaikuHammer) removeReaction(m *Message) {
+	if m.MyReaction == "" {
 		return
 	}
-	err := s.MessageReactionRemove(m.ChannelID, m.ID, r.Emoji.Name, h.botID)
+	err := h.platform.Unreact(m.ChannelID, m.ID, m.MyReaction)
 	if err != nil {
 		log.Println("could not remove emoji reaction", err)
 		return
 	}
 }
 
-func (h *HaikuHammer) react(s *discordgo.Session, m *discordgo.Message, reaction string) {
-	err := s.MessageReactionAdd(m.ChannelID, m.ID, reaction)
+func (h *HaikuHammer) react(m *Message, reaction string) {
+	err := h.platform.React(m.ChannelID, m.ID, reaction)
 	if err != nil {
 		log.Println("could not add emoji reaction,", err)
 		return
 	}
 }
 
-func (h *HaikuHammer) getDMChannelID(s *discordgo.Session, authorID string) (string, error) {
-	if c, ok := h.dmChannelCache[authorID]; ok {
-		return c, nil
-	}
-	c, err := s.UserChannelCreate(authorID)
-	if err != nil {
-		return "", err
-	}
-	log.Println("retrieved new DM channel for user", authorID)
-	h.dmCache[c.ID] = true
-	h.dmChannelCache[authorID] = c.ID
-	return c.ID, nil
-}
-
-func (h *HaikuHammer) getMemberNick(s *discordgo.Session, guildID string, userID string) (string, error) {
-	member, err := s.GuildMember(guildID, userID)
-	if err != nil {
-		return "", err
-	}
-	return memberNick(member), nil
-}
-
-func memberNick(m *discordgo.Member) string {
-	if m.Nick != "" {
-		return m.Nick
-	}
-	return m.User.Username
-}
-
-func (h *HaikuHammer) saveHaiku(m *discordgo.Message) {
+func (h *HaikuHammer) saveHaiku(m *Message, form Form) {
 	gid, cid, mid, err := idToInt(m)
 	if err != nil {
 		return
 	}
 	ctx := context.Background()
-	err = db.CheckHash(ctx, h.db, mid, DuplicateHash(m.Content))
+	fingerprint := Fingerprint(m.Content)
+	if original, distance, found := h.findOriginal(ctx, gid, mid, m.AuthorID, fingerprint); found {
+		h.recordCopy(ctx, m, original, distance)
+		return
+	}
+	err = h.store.CheckHash(ctx, mid, DuplicateHash(m.Content))
 	if err != nil {
 		return // haiku was a duplicate
 	}
-	_, err = db.HaikuDAO.Upsert(ctx, h.db, db.Haiku{gid, cid, mid, m.Author.ID, m.Content})
+	err = h.store.SaveHaiku(ctx, db.Haiku{GuildID: gid, ChannelID: cid, MessageID: mid, AuthorID: m.AuthorID, Content: m.Content, CreatedAt: h.timestamp(m).Unix(), Form: form.Name})
 	if err != nil {
 		log.Println("could not save haiku to database,", err)
+		return
 	}
+	h.saveFingerprint(ctx, mid, fingerprint)
+	h.deleteAttempt(ctx, mid) // an edit may have fixed an earlier attempt
 }
 
-func (h *HaikuHammer) replyWithRandomHaiku(s *discordgo.Session, m *discordgo.Message) {
-	haiku, err := db.HaikuDAO.Random(context.Background(), h.db, m.GuildID)
+func (h *HaikuHammer) replyWithRandomHaiku(m *Message) {
+	gid, err := strconv.Atoi(m.GuildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", m.GuildID)
+		return
+	}
+	haiku, err := h.store.RandomHaiku(context.Background(), gid)
 	if err != nil {
 		log.Println("could not retrieve random haiku for guild", err)
 		return
@@ -342,43 +352,33 @@ func (h *HaikuHammer) replyWithRandomHaiku(s *discordgo.Session, m *discordgo.Me
 		log.Println("could not find any haiku for guild", m.GuildID)
 		return
 	}
-	_, err = s.ChannelMessageSendReply(m.ChannelID, h.presentHaiku(s, haiku), m.MessageReference)
-	if err != nil {
-		log.Println("could not send message reply", err)
-		return
-	}
+	h.reply(m, h.presentHaiku(haiku))
 }
 
-func (h *HaikuHammer) mentionsMe(m *discordgo.Message) bool {
-	for _, m := range m.Mentions {
-		if m.ID == h.botID {
-			return true
-		}
-	}
-	return false
+func (h *HaikuHammer) presentHaiku(haiku db.Haiku) string {
+	return fmt.Sprintf("%s\n> - %s", quote(haiku.Content), h.nick(haiku.GuildID, haiku.AuthorID))
 }
 
-func (h *HaikuHammer) presentHaiku(s *discordgo.Session, haiku db.Haiku) string {
-	nick, err := h.getMemberNick(s, strconv.Itoa(haiku.GuildID), haiku.AuthorID)
-	if err != nil {
-		log.Println("could not retrieve member nick for guildID:", haiku.GuildID, "authorID:", haiku.AuthorID)
-		return fmt.Sprintf("%s\n> - Unknown", quote(haiku.Content))
-	}
-	return fmt.Sprintf("%s\n> - %s", quote(haiku.Content), nick)
-}
-
-func (h *HaikuHammer) actionsEnabled(m *discordgo.Message, flags db.ConfigFlag) bool {
+func (h *HaikuHammer) actionsEnabled(m *Message, flags db.ConfigFlag) bool {
 	guildID, channelID, _, err := idToInt(m)
 	if err != nil {
 		return false
 	}
-	found, err := db.LookupFlags(context.Background(), h.db, guildID, channelID)
+	found, err := h.store.LookupFlags(context.Background(), guildID, channelID)
 	if err != nil {
 		log.Println("could not retrieve flags for guildID:", guildID, "channelID:", channelID)
 	}
 	return (h.config.ActionFlags & flags & found) == flags
 }
 
+// timestamp returns the time a message was sent, if known, otherwise the current time.
+func (h *HaikuHammer) timestamp(m *Message) time.Time {
+	if m.Timestamp.IsZero() {
+		return h.now()
+	}
+	return m.Timestamp
+}
+
 func randomString(strs []string) string {
 	return strs[rand.Intn(len(strs))]
 }
@@ -391,7 +391,7 @@ func channelMention(channelID string) string {
 	return fmt.Sprintf("<#%s>", channelID)
 }
 
-func idToInt(m *discordgo.Message) (guildID, channelID, messageID int, err error) {
+func idToInt(m *Message) (guildID, channelID, messageID int, err error) {
 	guildID, err = strconv.Atoi(m.GuildID)
 	if err != nil {
 		log.Println("could not parse guildID as integer,", m.GuildID)
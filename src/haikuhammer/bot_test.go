@@ -0,0 +1,160 @@
+package haikuhammer
+
+import (
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const (
+	testHaiku    = "Another test case\nFor the automated bot\nHere’s a nice haiku"
+	testNonHaiku = "this\nis\nnot haiku"
+)
+
+func TestHandleMessage_ReactToHaiku(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.setChannelFlags(cid, db.ConfigReactToHaiku)
+
+	haikuID := h.post(gid, cid, "2", testHaiku)
+	nonHaikuID := h.post(gid, cid, "2", testNonHaiku)
+
+	assert.Equal(t, []string{"💯"}, h.reactions(haikuID))
+	assert.Empty(t, h.reactions(nonHaikuID))
+	assert.Empty(t, h.session.sent)
+
+	saved := h.savedHaiku(haikuID)
+	assert.Equal(t, testHaiku, saved.Content)
+	assert.Equal(t, "2", saved.AuthorID)
+	assert.Empty(t, h.savedHaiku(nonHaikuID).Content)
+}
+
+func TestHandleMessage_IgnoresBots(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.setGuildFlags(gid, allFlags)
+
+	mid := h.post(gid, cid, fakeBotID, testNonHaiku)
+
+	assert.Empty(t, h.reactions(mid))
+	assert.Empty(t, h.session.deleted)
+	assert.Empty(t, h.session.sent)
+}
+
+func TestHandleMessage_ReactToNonHaiku(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.setGuildFlags(gid, db.ConfigReactToNonHaiku)
+
+	haikuID := h.post(gid, cid, "2", testHaiku)
+	nonHaikuID := h.post(gid, cid, "2", testNonHaiku)
+
+	assert.Empty(t, h.reactions(haikuID))
+	assert.Equal(t, []string{"🚫"}, h.reactions(nonHaikuID))
+}
+
+func TestHandleMessage_EditRemovesReaction(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.setChannelFlags(cid, db.ConfigReactToHaiku)
+
+	mid := h.post(gid, cid, "2", testHaiku)
+	assert.Equal(t, []string{"💯"}, h.reactions(mid))
+
+	h.edit(mid, testNonHaiku)
+	assert.Empty(t, h.reactions(mid))
+
+	h.edit(mid, testHaiku)
+	assert.Equal(t, []string{"💯"}, h.reactions(mid))
+
+	h.edit(mid, testHaiku) // already reacted; shouldn't react twice
+	assert.Equal(t, []string{"💯"}, h.reactions(mid))
+}
+
+func TestHandleMessage_DeleteNonHaiku(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	other := h.addChannel(gid)
+	h.setChannelFlags(cid, db.ConfigDeleteNonHaiku|db.ConfigReactToNonHaiku)
+
+	haikuID := h.post(gid, cid, "2", testHaiku)
+	nonHaikuID := h.post(gid, cid, "2", testNonHaiku)
+	otherID := h.post(gid, other, "2", testNonHaiku)
+
+	assert.Equal(t, []string{nonHaikuID}, h.session.deleted)
+	assert.Empty(t, h.reactions(haikuID))
+	assert.Empty(t, h.reactions(nonHaikuID), "deleted messages should not be reacted to")
+	assert.Empty(t, h.reactions(otherID))
+
+	dms := h.dms("2")
+	if assert.Len(t, dms, 1) {
+		assert.Contains(t, dms[0], channelMention(cid))
+		assert.Contains(t, dms[0], quote(testNonHaiku))
+	}
+}
+
+func TestHandleMessage_ExplainNonHaiku(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.setChannelFlags(cid, db.ConfigExplainNonHaiku)
+
+	haikuID := h.post(gid, cid, "2", testHaiku)
+	nonHaikuID := h.post(gid, cid, "2", testNonHaiku)
+
+	assert.Empty(t, h.replies(haikuID))
+	replies := h.replies(nonHaikuID)
+	if assert.Len(t, replies, 1) {
+		assert.Contains(t, replies[0], "I counted a syllable structure of 1/1/3, but I expected 5/7/5")
+	}
+}
+
+func TestHandleMessage_ServeRandomHaiku(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.addMember(gid, "2", "Basho")
+	h.setGuildFlags(gid, db.ConfigReactToHaiku|db.ConfigServeRandomHaiku)
+
+	h.post(gid, cid, "2", testHaiku)
+	mentionID := h.post(gid, cid, "3", "hey <@999> got any haiku?", fakeBotID)
+	ignoredID := h.post(gid, cid, "3", "hey, got any haiku?")
+
+	replies := h.replies(mentionID)
+	if assert.Len(t, replies, 1) {
+		assert.Equal(t, quote(testHaiku)+"\n> - Basho", replies[0])
+	}
+	assert.Empty(t, h.replies(ignoredID))
+}
+
+func TestHandleMessage_DuplicateHaiku(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.setGuildFlags(gid, db.ConfigReactToHaiku)
+
+	firstID := h.post(gid, cid, "2", testHaiku)
+	copyID := h.post(gid, cid, "3", testHaiku+"!")
+
+	assert.Equal(t, []string{"💯"}, h.reactions(copyID))
+	assert.Equal(t, "2", h.savedHaiku(firstID).AuthorID)
+	assert.Empty(t, h.savedHaiku(copyID).Content)
+}
+
+func TestHandleMessage_InferLineBreaks(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	otherCID := h.addChannel(gid)
+	h.setGuildFlags(gid, db.ConfigReactToHaiku)
+	h.setChannelFlags(cid, db.ConfigInferLineBreaks)
+
+	runOn := "Another test case For the automated bot Here’s a nice haiku"
+	runOnID := h.post(gid, cid, "2", runOn)
+	assert.Equal(t, []string{"💯"}, h.reactions(runOnID))
+	assert.Equal(t, []string{"I read this as:\n" + quote(testHaiku)}, h.replies(runOnID))
+	assert.Equal(t, runOn, h.savedHaiku(runOnID).Content)
+
+	otherID := h.post(gid, otherCID, "3", runOn)
+	assert.Empty(t, h.reactions(otherID), "line breaks are only inferred in channels which enable it")
+
+	slashID := h.post(gid, otherCID, "3", "Crisp autumn morning / A cat naps beside the stove / The kettle whistles")
+	assert.Equal(t, []string{"💯"}, h.reactions(slashID))
+	assert.Empty(t, h.replies(slashID), "explicit line breaks are not reported")
+}
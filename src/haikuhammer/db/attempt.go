@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"github.com/jonbodner/proteus"
+)
+
+// Attempt is a three-line message which was not accepted as a haiku, along with the reasons it was rejected.
+type Attempt struct {
+	GuildID      int    `prof:"guild_id"`
+	ChannelID    int    `prof:"channel_id"`
+	MessageID    int    `prof:"message_id"`
+	AuthorID     string `prof:"author_id"`
+	CreatedAt    int64  `prof:"created_at"` // unix seconds
+	Content      string `prof:"content"`
+	Syllables    string `prof:"syllables"`     // syllables counted on each line, e.g. 5/8/5
+	UnknownWords string `prof:"unknown_words"` // comma-separated
+}
+
+// WordCount is the number of attempts in which a word could not be found.
+type WordCount struct {
+	Word     string `prof:"word"`
+	Attempts int    `prof:"attempts"`
+}
+
+var AttemptDAO AttemptDaoImpl
+
+type AttemptDaoImpl struct {
+	// Upsert records an attempt, replacing the reasons previously recorded for the same message.
+	Upsert func(ctx context.Context, e proteus.ContextExecutor, a Attempt) (int64, error) `proq:"q:attempt_upsert" prop:"a"`
+	// Delete forgets an attempt, used when a message is edited into a proper haiku.
+	Delete func(ctx context.Context, e proteus.ContextExecutor, messageID int) (int64, error) `proq:"q:attempt_delete" prop:"messageID"`
+	// Recent lists the latest attempts in a guild, newest first.
+	Recent func(ctx context.Context, e proteus.ContextQuerier, guildID int, limit int) ([]Attempt, error) `proq:"q:attempt_recent" prop:"guildID,limit"`
+
+	AddWord     func(ctx context.Context, e proteus.ContextExecutor, messageID int, guildID int, word string) (int64, error) `proq:"q:attempt_word_add" prop:"messageID,guildID,word"`
+	DeleteWords func(ctx context.Context, e proteus.ContextExecutor, messageID int) (int64, error)                           `proq:"q:attempt_word_delete" prop:"messageID"`
+	// TopWords lists the unknown words which blocked the most attempts in a guild.
+	TopWords func(ctx context.Context, e proteus.ContextQuerier, guildID int, limit int) ([]WordCount, error) `proq:"q:attempt_word_top" prop:"guildID,limit"`
+	// TopWordsGlobal lists the unknown words which blocked the most attempts in every guild.
+	TopWordsGlobal func(ctx context.Context, e proteus.ContextQuerier, limit int) ([]WordCount, error) `proq:"q:attempt_word_top_global" prop:"limit"`
+	// BlockedBy lists the latest attempts in a guild which were blocked by an unknown word, newest first. If guildID is
+	// 0, attempts in every guild are listed.
+	BlockedBy func(ctx context.Context, e proteus.ContextQuerier, guildID int, word string, limit int) ([]Attempt, error) `proq:"q:attempt_blocked_by" prop:"guildID,word,limit"`
+}
+
+func init() {
+	m := proteus.MapMapper{
+		"attempt_upsert": `INSERT INTO haiku_attempt (guild_id, channel_id, message_id, author_id, created_at, content, syllables, unknown_words)
+						   VALUES (:a.GuildID:, :a.ChannelID:, :a.MessageID:, :a.AuthorID:, :a.CreatedAt:, :a.Content:, :a.Syllables:, :a.UnknownWords:)
+						   ON CONFLICT (message_id)
+						   DO UPDATE SET content = excluded.content, syllables = excluded.syllables, unknown_words = excluded.unknown_words`,
+		"attempt_delete": `DELETE FROM haiku_attempt WHERE message_id = :messageID:`,
+		"attempt_recent": `SELECT * FROM haiku_attempt WHERE guild_id = :guildID: AND content IS NOT NULL
+						   ORDER BY created_at DESC, message_id DESC LIMIT :limit:`,
+		"attempt_word_add": `INSERT INTO haiku_attempt_word (message_id, guild_id, word) VALUES (:messageID:, :guildID:, :word:)
+							 ON CONFLICT (message_id, word) DO NOTHING`,
+		"attempt_word_delete": `DELETE FROM haiku_attempt_word WHERE message_id = :messageID:`,
+		"attempt_word_top": `SELECT word, COUNT(*) AS attempts FROM haiku_attempt_word WHERE guild_id = :guildID:
+							 GROUP BY word ORDER BY attempts DESC, word ASC LIMIT :limit:`,
+		"attempt_word_top_global": `SELECT word, COUNT(*) AS attempts FROM haiku_attempt_word
+									GROUP BY word ORDER BY attempts DESC, word ASC LIMIT :limit:`,
+		"attempt_blocked_by": `SELECT haiku_attempt.* FROM haiku_attempt
+							   JOIN haiku_attempt_word ON haiku_attempt_word.message_id = haiku_attempt.message_id
+							   WHERE haiku_attempt_word.word = :word: AND (:guildID: = 0 OR haiku_attempt.guild_id = :guildID:)
+							     AND haiku_attempt.content IS NOT NULL
+							   ORDER BY haiku_attempt.created_at DESC, haiku_attempt.message_id DESC LIMIT :limit:`,
+	}
+	register(&AttemptDAO, m)
+}
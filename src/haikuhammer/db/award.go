@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"github.com/jonbodner/proteus"
+)
+
+var VoteDAO VoteDaoImpl
+
+// VoteDaoImpl records emoji reactions to saved haiku as votes. Reactions to messages which are not saved haiku, and
+// reactions by the author of the haiku are ignored.
+type VoteDaoImpl struct {
+	Add    func(ctx context.Context, e proteus.ContextExecutor, mid int, userID string, emoji string, votedAt int64) (int64, error) `proq:"q:vote_add" prop:"mid,userID,emoji,votedAt"`
+	Remove func(ctx context.Context, e proteus.ContextExecutor, mid int, userID string, emoji string) (int64, error)                `proq:"q:vote_remove" prop:"mid,userID,emoji"`
+	Count  func(ctx context.Context, e proteus.ContextQuerier, mid int) (int, error)                                                `proq:"q:vote_count" prop:"mid"`
+}
+
+// Award records the winner of a voting period. If no haiku received votes, MessageID is 0.
+type Award struct {
+	GuildID   int    `prof:"guild_id"`
+	Period    string `prof:"period"`
+	StartsAt  int64  `prof:"starts_at"` // unix seconds
+	MessageID int    `prof:"message_id"`
+	Votes     int    `prof:"votes"`
+}
+
+// VotedHaiku is a haiku along with the number of users who voted for it.
+type VotedHaiku struct {
+	Haiku
+	Votes int `prof:"votes"`
+}
+
+// AwardedHaiku is a haiku which won an award.
+type AwardedHaiku struct {
+	Haiku
+	Period   string `prof:"period"`
+	StartsAt int64  `prof:"starts_at"` // unix seconds
+	Votes    int    `prof:"votes"`
+}
+
+var AwardDAO AwardDaoImpl
+
+type AwardDaoImpl struct {
+	// Insert records an award, returning 0 if an award has already been recorded for the same period.
+	Insert func(ctx context.Context, e proteus.ContextExecutor, a Award) (int64, error)                                   `proq:"q:award_insert" prop:"a"`
+	Find   func(ctx context.Context, e proteus.ContextQuerier, guildID int, period string, startsAt int64) (Award, error) `proq:"q:award_find" prop:"guildID,period,startsAt"`
+	// Winner finds the haiku created in [start, end) with the most votes cast before end.
+	Winner func(ctx context.Context, e proteus.ContextQuerier, guildID int, start int64, end int64) (VotedHaiku, error) `proq:"q:award_winner" prop:"guildID,start,end"`
+	// List returns the most recent winners of the provided period, newest first.
+	List func(ctx context.Context, e proteus.ContextQuerier, guildID int, period string, limit int) ([]AwardedHaiku, error) `proq:"q:award_list" prop:"guildID,period,limit"`
+}
+
+// AwardChannel is the channel awards are announced to in a guild.
+type AwardChannel struct {
+	GuildID   int `prof:"guild_id"`
+	ChannelID int `prof:"channel_id"`
+}
+
+var AwardChannelDAO AwardChannelDaoImpl
+
+type AwardChannelDaoImpl struct {
+	Upsert   func(ctx context.Context, e proteus.ContextExecutor, c AwardChannel) (int64, error)    `proq:"q:award_chan_upsert" prop:"c"`
+	Delete   func(ctx context.Context, e proteus.ContextExecutor, guildID int) (int64, error)       `proq:"q:award_chan_delete" prop:"guildID"`
+	FindByID func(ctx context.Context, e proteus.ContextQuerier, guildID int) (AwardChannel, error) `proq:"q:award_chan_findByID" prop:"guildID"`
+	FindAll  func(ctx context.Context, e proteus.ContextQuerier) ([]AwardChannel, error)            `proq:"q:award_chan_findAll"`
+}
+
+func init() {
+	m := proteus.MapMapper{
+		"vote_add": `INSERT INTO haiku_vote (message_id, user_id, emoji, voted_at)
+					 SELECT :mid:, :userID:, :emoji:, :votedAt:
+					 WHERE EXISTS (SELECT 1 FROM haiku WHERE message_id = :mid: AND author_id != :userID:)
+					 ON CONFLICT (message_id, user_id, emoji) DO NOTHING`,
+		"vote_remove": `DELETE FROM haiku_vote WHERE message_id = :mid: AND user_id = :userID: AND emoji = :emoji:`,
+		"vote_count":  `SELECT COUNT(DISTINCT user_id) FROM haiku_vote WHERE message_id = :mid:`,
+		"award_insert": `INSERT INTO haiku_award (guild_id, period, starts_at, message_id, votes)
+						 VALUES (:a.GuildID:, :a.Period:, :a.StartsAt:, :a.MessageID:, :a.Votes:)
+						 ON CONFLICT (guild_id, period, starts_at) DO NOTHING`,
+		"award_find": `SELECT * FROM haiku_award WHERE guild_id = :guildID: AND period = :period: AND starts_at = :startsAt:`,
+		"award_winner": `SELECT haiku.*, COUNT(DISTINCT haiku_vote.user_id) AS votes
+						 FROM haiku JOIN haiku_vote ON haiku_vote.message_id = haiku.message_id
+						 WHERE haiku.guild_id = :guildID: AND haiku.created_at >= :start: AND haiku.created_at < :end:
+						   AND haiku_vote.voted_at < :end:
+						 GROUP BY haiku.guild_id, haiku.channel_id, haiku.message_id
+						 ORDER BY votes DESC, haiku.created_at ASC
+						 LIMIT 1`,
+		"award_list": `SELECT haiku.*, haiku_award.period, haiku_award.starts_at, haiku_award.votes
+					   FROM haiku_award JOIN haiku ON haiku.message_id = haiku_award.message_id
+					   WHERE haiku_award.guild_id = :guildID: AND haiku_award.period = :period:
+					   ORDER BY haiku_award.starts_at DESC
+					   LIMIT :limit:`,
+		"award_chan_upsert": `INSERT INTO award_channel (guild_id, channel_id) VALUES (:c.GuildID:, :c.ChannelID:)
+							  ON CONFLICT (guild_id)
+							  DO UPDATE SET channel_id = excluded.channel_id`,
+		"award_chan_delete":   `DELETE FROM award_channel WHERE guild_id = :guildID:`,
+		"award_chan_findByID": `SELECT * FROM award_channel WHERE guild_id = :guildID:`,
+		"award_chan_findAll":  `SELECT * FROM award_channel`,
+	}
+	register(&VoteDAO, m)
+	register(&AwardDAO, m)
+	register(&AwardChannelDAO, m)
+}
@@ -28,6 +28,18 @@ func (f ConfigFlag) ServeRandomHaiku() bool {
 	return f &ConfigServeRandomHaiku > 0
 }
 
+func (f ConfigFlag) InferLineBreaks() bool {
+	return f &ConfigInferLineBreaks > 0
+}
+
+func (f ConfigFlag) CountInterjections() bool {
+	return f &ConfigCountInterjections > 0
+}
+
+func (f ConfigFlag) ReplyToCopies() bool {
+	return f &ConfigReplyToCopies > 0
+}
+
 func (f ConfigFlag) Or(other ConfigFlag) ConfigFlag {
 	return f | other
 }
@@ -53,6 +65,15 @@ func (f ConfigFlag) String() string {
 	if f.ServeRandomHaiku() {
 		features = append(features, "ServeRandomHaiku")
 	}
+	if f.InferLineBreaks() {
+		features = append(features, "InferLineBreaks")
+	}
+	if f.CountInterjections() {
+		features = append(features, "CountInterjections")
+	}
+	if f.ReplyToCopies() {
+		features = append(features, "ReplyToCopies")
+	}
 	return strings.Join(features, ", ")
 }
 
@@ -62,6 +83,9 @@ const (
 	ConfigDeleteNonHaiku
 	ConfigExplainNonHaiku
 	ConfigServeRandomHaiku
+	ConfigInferLineBreaks
+	ConfigCountInterjections
+	ConfigReplyToCopies
 )
 
 func LookupFlags(ctx context.Context, e proteus.ContextQuerier, guildID int, channelID int) (ConfigFlag, error) {
@@ -103,7 +127,6 @@ type GuildConfigDAOImpl struct {
 }
 
 func init() {
-	ctx := context.Background()
 	m := proteus.MapMapper{
 		"chan_upsert": `INSERT INTO channel_config (channel_id, flags)
 						VALUES (:channelID:, :flags:)
@@ -116,12 +139,6 @@ func init() {
 						DO UPDATE SET flags = excluded.flags, positive_reacts = excluded.positive_reacts, negative_reacts = excluded.negative_reacts`,
 		"guild_findByID": `SELECT * FROM guild_config WHERE guild_id = :guildID:`,
 	}
-	err := proteus.ShouldBuild(ctx, &ChannelConfigDAO, proteus.Sqlite, m)
-	if err != nil {
-		panic(err)
-	}
-	err = proteus.ShouldBuild(ctx, &GuildConfigDAO, proteus.Sqlite, m)
-	if err != nil {
-		panic(err)
-	}
+	register(&ChannelConfigDAO, m)
+	register(&GuildConfigDAO, m)
 }
\ No newline at end of file
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"github.com/jonbodner/proteus"
+)
+
+// Fingerprint is the SimHash of a saved haiku, along with the details needed to credit its author.
+type Fingerprint struct {
+	MessageID   int    `prof:"message_id"`
+	AuthorID    string `prof:"author_id"`
+	CreatedAt   int64  `prof:"created_at"` // unix seconds
+	Fingerprint int64  `prof:"fingerprint"`
+}
+
+// Copy is a haiku which was not saved because it looked too much like an earlier one.
+type Copy struct {
+	GuildID    int    `prof:"guild_id"`
+	MessageID  int    `prof:"message_id"`
+	AuthorID   string `prof:"author_id"`
+	OriginalID int    `prof:"original_id"`
+	Distance   int    `prof:"distance"`   // bits which differ between the fingerprints of the two haiku
+	CreatedAt  int64  `prof:"created_at"` // unix seconds
+}
+
+var FingerprintDAO FingerprintDaoImpl
+
+type FingerprintDaoImpl struct {
+	Upsert func(ctx context.Context, e proteus.ContextExecutor, mid int, fingerprint int64) (int64, error) `proq:"q:fingerprint_upsert" prop:"mid,fingerprint"`
+	// FindByGuild lists the fingerprints of every haiku saved in a guild.
+	FindByGuild func(ctx context.Context, e proteus.ContextQuerier, guildID int) ([]Fingerprint, error) `proq:"q:fingerprint_guild" prop:"guildID"`
+}
+
+var CopyDAO CopyDaoImpl
+
+type CopyDaoImpl struct {
+	Upsert   func(ctx context.Context, e proteus.ContextExecutor, c Copy) (int64, error)      `proq:"q:copy_upsert" prop:"c"`
+	FindByID func(ctx context.Context, e proteus.ContextQuerier, messageID int) (Copy, error) `proq:"q:copy_findByID" prop:"messageID"`
+}
+
+func init() {
+	m := proteus.MapMapper{
+		"fingerprint_upsert": `INSERT INTO haiku_fingerprint (message_id, fingerprint) VALUES (:mid:, :fingerprint:)
+							   ON CONFLICT (message_id) DO UPDATE SET fingerprint = excluded.fingerprint`,
+		"fingerprint_guild": `SELECT haiku.message_id, haiku.author_id, haiku.created_at, haiku_fingerprint.fingerprint
+							  FROM haiku_fingerprint JOIN haiku ON haiku.message_id = haiku_fingerprint.message_id
+							  WHERE haiku.guild_id = :guildID:`,
+		"copy_upsert": `INSERT INTO haiku_copy (message_id, guild_id, author_id, original_id, distance, created_at)
+						VALUES (:c.MessageID:, :c.GuildID:, :c.AuthorID:, :c.OriginalID:, :c.Distance:, :c.CreatedAt:)
+						ON CONFLICT (message_id) DO UPDATE SET original_id = excluded.original_id, distance = excluded.distance`,
+		"copy_findByID": `SELECT * FROM haiku_copy WHERE message_id = :messageID:`,
+	}
+	register(&FingerprintDAO, m)
+	register(&CopyDAO, m)
+}
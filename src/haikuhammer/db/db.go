@@ -3,42 +3,14 @@ package db
 import (
 	"database/sql"
 	"embed"
-	"fmt"
-	"log"
 )
 
-//go:embed scripts/*.sql
+//go:embed scripts/*.sql scripts/postgres/*.sql
 var bootstrapScripts embed.FS
 
-// BootstrapDB attempts to execute all files ending in .sql from the provided directory against the
-// provided database, in alphabetical order by filename. If no files are found, an error is returned.
+// BootstrapDB brings the schema of the provided database up to date by applying each embedded migration for the
+// current dialect which it hasn't seen yet. An error is returned if any migration fails.
 func BootstrapDB(DB *sql.DB) error {
-	foundSQLFile := false
-	scripts, err := bootstrapScripts.ReadDir("scripts")
-	if err != nil {
-		return err
-	}
-	for _, finfo := range scripts {
-		if finfo.IsDir() {
-			continue
-		}
-		foundSQLFile = true
-
-		script, err := bootstrapScripts.ReadFile("scripts/"+finfo.Name())
-		if err != nil {
-			return err
-		}
-		_, err = DB.Exec(string(script))
-		if err != nil {
-			log.Printf("could not execute bootstrap script %s: %v", finfo.Name(), err)
-			// TODO: proper version control and error reporting ._.
-			//return err
-		} else {
-			log.Printf("executed bootstrap script %s", finfo.Name())
-		}
-	}
-	if !foundSQLFile {
-		return fmt.Errorf("could not find any *.sql files in schema folder scripts")
-	}
-	return nil
+	_, err := Migrate(DB)
+	return err
 }
\ No newline at end of file
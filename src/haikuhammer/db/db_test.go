@@ -17,6 +17,9 @@ import (
 var DB *sql.DB
 
 func TestMain(m *testing.M) {
+	if dsn := os.Getenv(testPostgresEnv); dsn != "" {
+		os.Exit(runPostgres(m, dsn))
+	}
 	dbPath := fmt.Sprintf(path.Join("%s","test.db"), os.TempDir())
 
 	// delete any existing database
@@ -43,7 +46,7 @@ func TestMain(m *testing.M) {
 func TestHaikuDAO_Upsert(t *testing.T) {
 	ctx := context.Background()
 
-	rows, err := db.HaikuDAO.Upsert(ctx, DB, db.Haiku{1,1,1,"mention#3414", "not really a haiku"})
+	rows, err := db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 1, ChannelID: 1, MessageID: 1, AuthorID: "mention#3414", Content: "not really a haiku"})
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, 1, rows)
@@ -53,7 +56,7 @@ func TestHaikuDAO_Upsert(t *testing.T) {
 	assert.EqualValues(t, haiku.Content, "not really a haiku")
 	assert.EqualValues(t, haiku.AuthorID, "mention#3414")
 
-	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{1,1,1,"changed_mention","updated haiku"})
+	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 1, ChannelID: 1, MessageID: 1, AuthorID: "changed_mention", Content: "updated haiku"})
 	assert.NoError(t, err)
 	assert.EqualValues(t, 1, rows)
 
@@ -66,14 +69,14 @@ func TestHaikuDAO_Upsert(t *testing.T) {
 func TestHaikuDAO_Random(t *testing.T) {
 	ctx := context.Background()
 
-	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{1,1,2,"mention#3414","not really a haiku"})
-	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{1,1,3,"mention#3414","also not haiku"})
-	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{1,1,4,"mention#3414","not even haiku"})
+	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 1, ChannelID: 1, MessageID: 2, AuthorID: "mention#3414", Content: "not really a haiku"})
+	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 1, ChannelID: 1, MessageID: 3, AuthorID: "mention#3414", Content: "also not haiku"})
+	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 1, ChannelID: 1, MessageID: 4, AuthorID: "mention#3414", Content: "not even haiku"})
 
 	// should not hit the below rows since filtering by guild_id
-	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{2,2,6,"mention#3414","not even haiku"})
-	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{2,2,7,"mention#3414","not even haiku"})
-	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{2,2,8,"mention#3414","not even haiku"})
+	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 2, ChannelID: 2, MessageID: 6, AuthorID: "mention#3414", Content: "not even haiku"})
+	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 2, ChannelID: 2, MessageID: 7, AuthorID: "mention#3414", Content: "not even haiku"})
+	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 2, ChannelID: 2, MessageID: 8, AuthorID: "mention#3414", Content: "not even haiku"})
 
 	for i := 0; i < 10; i++ {
 		result, err := db.HaikuDAO.Random(ctx, DB, "1")
@@ -162,4 +165,366 @@ func TestHaikuHashDao(t *testing.T) {
 	mid, err = db.HaikuHashDAO.FindByMD5(ctx, DB, otherHash[:])
 	assert.NoError(t, err) // I wish it was elseways.
 	assert.EqualValues(t, 0, mid)
-}
\ No newline at end of file
+}
+func TestCheckHash(t *testing.T) {
+	ctx := context.Background()
+
+	haikuHash := [16]byte{1,1,2,3,4,5,6,7,8,9,10,11,12,13,14,15}
+
+	assert.NoError(t, db.CheckHash(ctx, DB, 200, haikuHash))
+	assert.NoError(t, db.CheckHash(ctx, DB, 200, haikuHash)) // same message may be edited
+	assert.ErrorIs(t, db.CheckHash(ctx, DB, 201, haikuHash), db.ErrDuplicate)
+}
+
+func TestVoteDAO(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 10, ChannelID: 10, MessageID: 100, AuthorID: "author", Content: "haiku", CreatedAt: 1000})
+	assert.NoError(t, err)
+
+	count, err := db.VoteDAO.Add(ctx, DB, 100, "voter", "💯", 1001)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	count, err = db.VoteDAO.Add(ctx, DB, 100, "voter", "💯", 1002) // same reaction twice
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+	_, err = db.VoteDAO.Add(ctx, DB, 100, "voter", "🍵", 1003) // same voter, different reaction
+	assert.NoError(t, err)
+
+	count, err = db.VoteDAO.Add(ctx, DB, 100, "author", "💯", 1001) // no voting for yourself
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+	count, err = db.VoteDAO.Add(ctx, DB, 101, "voter", "💯", 1001) // not a haiku
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	votes, err := db.VoteDAO.Count(ctx, DB, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, votes)
+
+	_, err = db.VoteDAO.Remove(ctx, DB, 100, "voter", "💯")
+	assert.NoError(t, err)
+	votes, err = db.VoteDAO.Count(ctx, DB, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, votes, "voter still has a reaction on the haiku")
+
+	_, err = db.VoteDAO.Remove(ctx, DB, 100, "voter", "🍵")
+	assert.NoError(t, err)
+	votes, err = db.VoteDAO.Count(ctx, DB, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, votes)
+}
+
+func TestAwardDAO(t *testing.T) {
+	ctx := context.Background()
+
+	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 11, ChannelID: 11, MessageID: 110, AuthorID: "a", Content: "early", CreatedAt: 100})
+	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 11, ChannelID: 11, MessageID: 111, AuthorID: "a", Content: "popular", CreatedAt: 150})
+	db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: 11, ChannelID: 11, MessageID: 112, AuthorID: "a", Content: "too late", CreatedAt: 200})
+	db.VoteDAO.Add(ctx, DB, 110, "x", "💯", 160)
+	db.VoteDAO.Add(ctx, DB, 111, "x", "💯", 160)
+	db.VoteDAO.Add(ctx, DB, 111, "y", "💯", 160)
+	db.VoteDAO.Add(ctx, DB, 111, "z", "💯", 250) // cast after the period ended
+	db.VoteDAO.Add(ctx, DB, 112, "x", "💯", 210)
+	db.VoteDAO.Add(ctx, DB, 112, "y", "💯", 210)
+	db.VoteDAO.Add(ctx, DB, 112, "z", "💯", 210)
+
+	winner, err := db.AwardDAO.Winner(ctx, DB, 11, 100, 200)
+	assert.NoError(t, err)
+	assert.Equal(t, 111, winner.MessageID)
+	assert.Equal(t, "popular", winner.Content)
+	assert.Equal(t, 2, winner.Votes)
+
+	winner, err = db.AwardDAO.Winner(ctx, DB, 11, 300, 400)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, winner.MessageID)
+
+	count, err := db.AwardDAO.Insert(ctx, DB, db.Award{GuildID: 11, Period: "week", StartsAt: 100, MessageID: 111, Votes: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	count, err = db.AwardDAO.Insert(ctx, DB, db.Award{GuildID: 11, Period: "week", StartsAt: 100, MessageID: 110, Votes: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count, "only one award can be recorded per period")
+	db.AwardDAO.Insert(ctx, DB, db.Award{GuildID: 11, Period: "week", StartsAt: 200, MessageID: 112, Votes: 3})
+	db.AwardDAO.Insert(ctx, DB, db.Award{GuildID: 11, Period: "week", StartsAt: 300, MessageID: 0, Votes: 0})
+
+	award, err := db.AwardDAO.Find(ctx, DB, 11, "week", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Award{GuildID: 11, Period: "week", StartsAt: 100, MessageID: 111, Votes: 2}, award)
+
+	winners, err := db.AwardDAO.List(ctx, DB, 11, "week", 5)
+	assert.NoError(t, err)
+	if assert.Len(t, winners, 2) {
+		assert.Equal(t, "too late", winners[0].Content)
+		assert.EqualValues(t, 200, winners[0].StartsAt)
+		assert.Equal(t, 3, winners[0].Votes)
+		assert.Equal(t, "popular", winners[1].Content)
+	}
+	winners, err = db.AwardDAO.List(ctx, DB, 11, "month", 5)
+	assert.NoError(t, err)
+	assert.Empty(t, winners)
+}
+
+func TestAwardChannelDAO(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := db.AwardChannelDAO.Upsert(ctx, DB, db.AwardChannel{GuildID: 12, ChannelID: 1})
+	assert.NoError(t, err)
+	_, err = db.AwardChannelDAO.Upsert(ctx, DB, db.AwardChannel{GuildID: 12, ChannelID: 2})
+	assert.NoError(t, err)
+	_, err = db.AwardChannelDAO.Upsert(ctx, DB, db.AwardChannel{GuildID: 13, ChannelID: 3})
+	assert.NoError(t, err)
+
+	c, err := db.AwardChannelDAO.FindByID(ctx, DB, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, db.AwardChannel{GuildID: 12, ChannelID: 2}, c)
+
+	_, err = db.AwardChannelDAO.Delete(ctx, DB, 13)
+	assert.NoError(t, err)
+	all, err := db.AwardChannelDAO.FindAll(ctx, DB)
+	assert.NoError(t, err)
+	assert.Contains(t, all, db.AwardChannel{GuildID: 12, ChannelID: 2})
+	assert.NotContains(t, all, db.AwardChannel{GuildID: 13, ChannelID: 3})
+}
+
+func TestStatsDAO(t *testing.T) {
+	ctx := context.Background()
+	const day = 86400
+
+	for i, h := range []db.Haiku{
+		{GuildID: 20, ChannelID: 1, MessageID: 200, AuthorID: "a", Content: "first", CreatedAt: 10 * day},
+		{GuildID: 20, ChannelID: 1, MessageID: 201, AuthorID: "a", Content: "second", CreatedAt: 10*day + 5},
+		{GuildID: 20, ChannelID: 1, MessageID: 202, AuthorID: "a", Content: "third", CreatedAt: 11 * day},
+		{GuildID: 20, ChannelID: 1, MessageID: 203, AuthorID: "b", Content: "fourth", CreatedAt: 9 * day},
+		{GuildID: 21, ChannelID: 2, MessageID: 204, AuthorID: "b", Content: "other guild", CreatedAt: 9 * day},
+	} {
+		_, err := db.HaikuDAO.Upsert(ctx, DB, h)
+		assert.NoError(t, err, "haiku %d", i)
+	}
+	for _, a := range []db.Attempt{
+		{GuildID: 20, ChannelID: 1, MessageID: 205, AuthorID: "a"},
+		{GuildID: 20, ChannelID: 1, MessageID: 206, AuthorID: "c"},
+		{GuildID: 20, ChannelID: 1, MessageID: 207, AuthorID: "c"},
+	} {
+		_, err := db.AttemptDAO.Upsert(ctx, DB, a)
+		assert.NoError(t, err)
+	}
+	_, err := db.AttemptDAO.Upsert(ctx, DB, db.Attempt{GuildID: 20, ChannelID: 1, MessageID: 207, AuthorID: "c"})
+	assert.NoError(t, err)
+	_, err = db.AttemptDAO.Delete(ctx, DB, 207)
+	assert.NoError(t, err)
+
+	summary, err := db.StatsDAO.GuildSummary(ctx, DB, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Summary{Haiku: 4, Attempts: 2, Authors: 2}, summary)
+
+	summary, err = db.StatsDAO.AuthorSummary(ctx, DB, 20, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, db.Summary{Haiku: 3, Attempts: 1, Authors: 1}, summary)
+
+	top, err := db.StatsDAO.TopAuthors(ctx, DB, 20, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.AuthorCount{{AuthorID: "a", Haiku: 3}, {AuthorID: "b", Haiku: 1}}, top)
+
+	top, err = db.StatsDAO.TopAuthors(ctx, DB, 20, 1)
+	assert.NoError(t, err)
+	assert.Len(t, top, 1)
+
+	days, err := db.StatsDAO.AuthorDays(ctx, DB, 20, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []db.AuthorDay{{Day: 10}, {Day: 11}}, days)
+}
+
+func TestAttemptDAO(t *testing.T) {
+	ctx := context.Background()
+
+	for _, a := range []db.Attempt{
+		{GuildID: 22, ChannelID: 1, MessageID: 220, AuthorID: "a", CreatedAt: 1, Content: "first", Syllables: "0/7/5", UnknownWords: "dat"},
+		{GuildID: 22, ChannelID: 1, MessageID: 221, AuthorID: "a", CreatedAt: 2, Content: "second", Syllables: "5/8/5"},
+		{GuildID: 23, ChannelID: 2, MessageID: 222, AuthorID: "b", CreatedAt: 3, Content: "third", Syllables: "0/0/5", UnknownWords: "dat, yeet"},
+	} {
+		_, err := db.AttemptDAO.Upsert(ctx, DB, a)
+		assert.NoError(t, err)
+	}
+	_, err := db.AttemptDAO.Upsert(ctx, DB, db.Attempt{GuildID: 22, ChannelID: 1, MessageID: 221, AuthorID: "a", CreatedAt: 2, Content: "edited", Syllables: "5/6/5"})
+	assert.NoError(t, err)
+
+	recent, err := db.AttemptDAO.Recent(ctx, DB, 22, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.Attempt{
+		{GuildID: 22, ChannelID: 1, MessageID: 221, AuthorID: "a", CreatedAt: 2, Content: "edited", Syllables: "5/6/5"},
+		{GuildID: 22, ChannelID: 1, MessageID: 220, AuthorID: "a", CreatedAt: 1, Content: "first", Syllables: "0/7/5", UnknownWords: "dat"},
+	}, recent)
+
+	for _, w := range []struct {
+		mid, gid int
+		word     string
+	}{{220, 22, "DAT"}, {222, 23, "DAT"}, {222, 23, "YEET"}, {222, 23, "YEET"}} {
+		_, err = db.AttemptDAO.AddWord(ctx, DB, w.mid, w.gid, w.word)
+		assert.NoError(t, err)
+	}
+
+	words, err := db.AttemptDAO.TopWords(ctx, DB, 23, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.WordCount{{Word: "DAT", Attempts: 1}, {Word: "YEET", Attempts: 1}}, words)
+
+	words, err = db.AttemptDAO.TopWordsGlobal(ctx, DB, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.WordCount{{Word: "DAT", Attempts: 2}}, words)
+
+	_, err = db.AttemptDAO.DeleteWords(ctx, DB, 222)
+	assert.NoError(t, err)
+	words, err = db.AttemptDAO.TopWords(ctx, DB, 23, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, words)
+}
+
+func TestLookupSetting(t *testing.T) {
+	ctx := context.Background()
+
+	value, err := db.LookupSetting(ctx, DB, 30, 31, db.SettingForms)
+	assert.NoError(t, err)
+	assert.Equal(t, "", value)
+
+	_, err = db.SettingDAO.Upsert(ctx, DB, db.Setting{Scope: db.ScopeGuild, TargetID: 30, Name: db.SettingForms, Value: "haiku"})
+	assert.NoError(t, err)
+	value, err = db.LookupSetting(ctx, DB, 30, 31, db.SettingForms)
+	assert.NoError(t, err)
+	assert.Equal(t, "haiku", value, "channels fall back to their guild")
+
+	_, err = db.SettingDAO.Upsert(ctx, DB, db.Setting{Scope: db.ScopeChannel, TargetID: 31, Name: db.SettingForms, Value: "tanka"})
+	assert.NoError(t, err)
+	_, err = db.SettingDAO.Upsert(ctx, DB, db.Setting{Scope: db.ScopeChannel, TargetID: 31, Name: db.SettingForms, Value: "tanka, cinquain"})
+	assert.NoError(t, err)
+	value, err = db.LookupSetting(ctx, DB, 30, 31, db.SettingForms)
+	assert.NoError(t, err)
+	assert.Equal(t, "tanka, cinquain", value, "channel settings take precedence")
+
+	_, err = db.SettingDAO.Delete(ctx, DB, db.ScopeChannel, 31, db.SettingForms)
+	assert.NoError(t, err)
+	value, err = db.LookupSetting(ctx, DB, 30, 31, db.SettingForms)
+	assert.NoError(t, err)
+	assert.Equal(t, "haiku", value)
+
+	// older guilds have a channel with the same ID as the guild itself
+	_, err = db.SettingDAO.Upsert(ctx, DB, db.Setting{Scope: db.ScopeChannel, TargetID: 30, Name: db.SettingForms, Value: "tanka"})
+	assert.NoError(t, err)
+	value, err = db.LookupSetting(ctx, DB, 30, 31, db.SettingForms)
+	assert.NoError(t, err)
+	assert.Equal(t, "haiku", value, "a channel sharing its guild's ID doesn't overwrite the guild's setting")
+	value, err = db.LookupSetting(ctx, DB, 30, 30, db.SettingForms)
+	assert.NoError(t, err)
+	assert.Equal(t, "tanka", value)
+}
+
+func TestGuildDictionaryDAO(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := db.GuildDictionaryDAO.Upsert(ctx, DB, db.GuildWord{GuildID: 40, Word: "YEET", Syllables: 1})
+	assert.NoError(t, err)
+	_, err = db.GuildDictionaryDAO.Upsert(ctx, DB, db.GuildWord{GuildID: 40, Word: "BLORBO", Syllables: 3})
+	assert.NoError(t, err)
+	_, err = db.GuildDictionaryDAO.Upsert(ctx, DB, db.GuildWord{GuildID: 40, Word: "BLORBO", Syllables: 2})
+	assert.NoError(t, err)
+	_, err = db.GuildDictionaryDAO.Upsert(ctx, DB, db.GuildWord{GuildID: 41, Word: "YEET", Syllables: 2})
+	assert.NoError(t, err)
+
+	words, err := db.GuildDictionaryDAO.List(ctx, DB, 40)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.GuildWord{{GuildID: 40, Word: "BLORBO", Syllables: 2}, {GuildID: 40, Word: "YEET", Syllables: 1}}, words)
+
+	deleted, err := db.GuildDictionaryDAO.Delete(ctx, DB, 40, "YEET")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	words, err = db.GuildDictionaryDAO.List(ctx, DB, 40)
+	assert.NoError(t, err)
+	assert.Len(t, words, 1)
+}
+
+func TestSuggestionDAO(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := db.SuggestionDAO.Upsert(ctx, DB, db.Suggestion{GuildID: 50, UserID: "1", Word: "ZXQV", Syllables: 2, CreatedAt: 100})
+	assert.NoError(t, err)
+	_, err = db.SuggestionDAO.Upsert(ctx, DB, db.Suggestion{GuildID: 50, UserID: "1", Word: "ZXQV", Syllables: 1, CreatedAt: 101})
+	assert.NoError(t, err)
+	_, err = db.SuggestionDAO.Upsert(ctx, DB, db.Suggestion{GuildID: 50, UserID: "2", Word: "ZXQV", Syllables: 1, CreatedAt: 102})
+	assert.NoError(t, err)
+
+	pending, err := db.SuggestionDAO.Pending(ctx, DB, 50, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, 1, pending[0].Syllables, "suggesting a word again replaces the earlier suggestion")
+		assert.Equal(t, db.SuggestionPending, pending[0].Status)
+	}
+
+	reviewed, err := db.SuggestionDAO.Review(ctx, DB, 51, pending[0].ID, db.SuggestionApproved)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), reviewed, "suggestions can only be reviewed in their own guild")
+	reviewed, err = db.SuggestionDAO.Review(ctx, DB, 50, pending[0].ID, db.SuggestionApproved)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), reviewed)
+	reviewed, err = db.SuggestionDAO.Review(ctx, DB, 50, pending[0].ID, db.SuggestionRejected)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), reviewed)
+
+	found, err := db.SuggestionDAO.Find(ctx, DB, 50, pending[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, db.SuggestionApproved, found.Status)
+}
+
+func TestAttemptDAO_BlockedBy(t *testing.T) {
+	ctx := context.Background()
+
+	for i, guildID := range []int{52, 52, 53} {
+		mid := 5200 + i
+		_, err := db.AttemptDAO.Upsert(ctx, DB, db.Attempt{GuildID: guildID, ChannelID: 1, MessageID: mid, CreatedAt: int64(i), Content: "x"})
+		assert.NoError(t, err)
+		_, err = db.AttemptDAO.AddWord(ctx, DB, mid, guildID, "QWXZ")
+		assert.NoError(t, err)
+	}
+
+	attempts, err := db.AttemptDAO.BlockedBy(ctx, DB, 52, "QWXZ", 10)
+	assert.NoError(t, err)
+	assert.Len(t, attempts, 2)
+	attempts, err = db.AttemptDAO.BlockedBy(ctx, DB, 0, "QWXZ", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, attempts, 3) {
+		assert.Equal(t, 5202, attempts[0].MessageID, "newest first")
+	}
+}
+
+func TestFingerprintDAO(t *testing.T) {
+	ctx := context.Background()
+
+	for i, guildID := range []int{54, 54, 55} {
+		mid := 5400 + i
+		_, err := db.HaikuDAO.Upsert(ctx, DB, db.Haiku{GuildID: guildID, ChannelID: 1, MessageID: mid, AuthorID: "author", Content: "haiku", CreatedAt: int64(i)})
+		assert.NoError(t, err)
+		_, err = db.FingerprintDAO.Upsert(ctx, DB, mid, int64(-1)<<63+int64(i)) // fingerprints use every bit
+		assert.NoError(t, err)
+	}
+
+	fingerprints, err := db.FingerprintDAO.FindByGuild(ctx, DB, 54)
+	assert.NoError(t, err)
+	if assert.Len(t, fingerprints, 2) {
+		assert.Equal(t, db.Fingerprint{MessageID: 5400, AuthorID: "author", CreatedAt: 0, Fingerprint: int64(-1) << 63}, fingerprints[0])
+	}
+}
+
+func TestCopyDAO(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := db.CopyDAO.Upsert(ctx, DB, db.Copy{GuildID: 56, MessageID: 5600, AuthorID: "copycat", OriginalID: 5500, Distance: 4, CreatedAt: 1000})
+	assert.NoError(t, err)
+	_, err = db.CopyDAO.Upsert(ctx, DB, db.Copy{GuildID: 56, MessageID: 5600, AuthorID: "copycat", OriginalID: 5501, Distance: 2, CreatedAt: 2000})
+	assert.NoError(t, err)
+
+	found, err := db.CopyDAO.FindByID(ctx, DB, 5600)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Copy{GuildID: 56, MessageID: 5600, AuthorID: "copycat", OriginalID: 5501, Distance: 2, CreatedAt: 1000}, found)
+
+	found, err = db.CopyDAO.FindByID(ctx, DB, 5601)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Copy{}, found)
+}
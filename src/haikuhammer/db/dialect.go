@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jonbodner/proteus"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Dialect is the flavour of SQL spoken by a database, named after the driver used to connect to it.
+type Dialect string
+
+const (
+	SQLite   Dialect = "sqlite3"
+	Postgres Dialect = "postgres"
+)
+
+// Dialects lists every supported dialect, starting with the default.
+var Dialects = []Dialect{SQLite, Postgres}
+
+// LookupDialect finds a supported dialect by the name of its driver.
+func LookupDialect(name string) (Dialect, bool) {
+	for _, d := range Dialects {
+		if string(d) == name {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+func (d Dialect) adapter() proteus.ParamAdapter {
+	if d == Postgres {
+		return proteus.Postgres
+	}
+	return proteus.Sqlite
+}
+
+// queries returns the queries written specially for a dialect, which are used instead of the SQLite queries written
+// alongside each DAO.
+func (d Dialect) queries() proteus.MapMapper {
+	if d == Postgres {
+		return postgresQueries
+	}
+	return proteus.MapMapper{}
+}
+
+// scripts returns the directory holding the migrations for a dialect.
+func (d Dialect) scripts() string {
+	if d == Postgres {
+		return "scripts/postgres"
+	}
+	return "scripts"
+}
+
+// legacyVersion returns the last migration written before versions were recorded, or -1 if the dialect was added
+// later.
+func (d Dialect) legacyVersion() int {
+	if d == SQLite {
+		return sqliteLegacyVersion
+	}
+	return -1
+}
+
+// dialect is the dialect every DAO is currently built for.
+var dialect = SQLite
+
+// daos holds every DAO along with its SQLite queries, so they can be rebuilt for another dialect.
+var daos []dao
+
+type dao struct {
+	impl    interface{}
+	queries proteus.MapMapper
+}
+
+// register builds a DAO for the current dialect, and rebuilds it whenever the dialect changes.
+func register(impl interface{}, queries proteus.MapMapper) {
+	daos = append(daos, dao{impl: impl, queries: queries})
+	err := proteus.ShouldBuild(context.Background(), impl, dialect.adapter(), dialect.queries(), queries)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// UseDialect rebuilds every DAO to run queries against databases of the provided dialect. It's not safe to call while
+// queries are running.
+func UseDialect(d Dialect) error {
+	for _, dao := range daos {
+		err := proteus.ShouldBuild(context.Background(), dao.impl, d.adapter(), d.queries(), dao.queries)
+		if err != nil {
+			return fmt.Errorf("could not build queries for %s: %w", d, err)
+		}
+	}
+	dialect = d
+	return nil
+}
+
+// Open connects to a database, building every DAO for its dialect. The source is the path to the database file for
+// SQLite, or a connection string for Postgres.
+func Open(d Dialect, source string) (*sql.DB, error) {
+	err := UseDialect(d)
+	if err != nil {
+		return nil, err
+	}
+	if d == SQLite {
+		source += "?cache=shared&mode=rwc"
+	}
+	DB, err := sql.Open(string(d), source)
+	if err != nil {
+		return nil, err
+	}
+	if d == SQLite {
+		_, err = DB.Exec("PRAGMA journal_mode=WAL;")
+		if err != nil {
+			DB.Close()
+			return nil, fmt.Errorf("could not set journal mode: %w", err)
+		}
+	}
+	return DB, nil
+}
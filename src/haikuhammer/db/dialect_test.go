@@ -0,0 +1,30 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupDialect(t *testing.T) {
+	d, ok := LookupDialect("postgres")
+	assert.True(t, ok)
+	assert.Equal(t, Postgres, d)
+
+	_, ok = LookupDialect("mysql")
+	assert.False(t, ok)
+}
+
+func TestUseDialect(t *testing.T) {
+	previous := dialect
+	defer UseDialect(previous)
+
+	for _, d := range Dialects {
+		assert.NoError(t, UseDialect(d), "every query should build for %s", d)
+		assert.Equal(t, d, dialect)
+
+		migrations, err := readMigrations(bootstrapScripts, d.scripts())
+		assert.NoError(t, err)
+		assert.NotEmpty(t, migrations)
+	}
+}
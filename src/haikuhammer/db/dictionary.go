@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+	"github.com/jonbodner/proteus"
+)
+
+// GuildWord overrides the number of syllables in a word for a single guild.
+type GuildWord struct {
+	GuildID   int    `prof:"guild_id"`
+	Word      string `prof:"word"`
+	Syllables int    `prof:"syllables"`
+}
+
+var GuildDictionaryDAO GuildDictionaryDaoImpl
+
+type GuildDictionaryDaoImpl struct {
+	Upsert func(ctx context.Context, e proteus.ContextExecutor, w GuildWord) (int64, error)              `proq:"q:guild_dict_upsert" prop:"w"`
+	Delete func(ctx context.Context, e proteus.ContextExecutor, guildID int, word string) (int64, error) `proq:"q:guild_dict_delete" prop:"guildID,word"`
+	List   func(ctx context.Context, e proteus.ContextQuerier, guildID int) ([]GuildWord, error)         `proq:"q:guild_dict_list" prop:"guildID"`
+}
+
+func init() {
+	m := proteus.MapMapper{
+		"guild_dict_upsert": `INSERT INTO guild_dictionary (guild_id, word, syllables) VALUES (:w.GuildID:, :w.Word:, :w.Syllables:)
+							  ON CONFLICT (guild_id, word)
+							  DO UPDATE SET syllables = excluded.syllables`,
+		"guild_dict_delete": `DELETE FROM guild_dictionary WHERE guild_id = :guildID: AND word = :word:`,
+		"guild_dict_list":   `SELECT * FROM guild_dictionary WHERE guild_id = :guildID: ORDER BY word`,
+	}
+	register(&GuildDictionaryDAO, m)
+}
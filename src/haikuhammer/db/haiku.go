@@ -11,28 +11,27 @@ type Haiku struct {
 	MessageID int    `prof:"message_id"`
 	AuthorID  string `prof:"author_id"`
 	Content   string `prof:"content"`
+	CreatedAt int64  `prof:"created_at"` // unix seconds
+	Form      string `prof:"form"`       // name of the poetic form the haiku was written in
 }
 
 var HaikuDAO HaikuDaoImpl
 
 type HaikuDaoImpl struct {
-	Upsert func(ctx context.Context, e proteus.ContextExecutor, h Haiku) (int64, error) `proq:"q:upsert" prop:"h"`
-	Random func(ctx context.Context, e proteus.ContextQuerier, guildID string) (Haiku, error)           `proq:"q:random" prop:"guildID"`
+	Upsert func(ctx context.Context, e proteus.ContextExecutor, h Haiku) (int64, error) `proq:"q:haiku_upsert" prop:"h"`
+	Random func(ctx context.Context, e proteus.ContextQuerier, guildID string) (Haiku, error)           `proq:"q:haiku_random" prop:"guildID"`
 	// FindByID is only intended for testing
-	FindByID func(ctx context.Context, e proteus.ContextQuerier, messageID int) (Haiku, error)           `proq:"q:findByID" prop:"messageID"`
+	FindByID func(ctx context.Context, e proteus.ContextQuerier, messageID int) (Haiku, error)           `proq:"q:haiku_findByID" prop:"messageID"`
 }
 
 func init() {
 	m := proteus.MapMapper{
-		"upsert": `INSERT INTO haiku (guild_id, channel_id, message_id, author_id, content)
-				   VALUES (:h.GuildID:,:h.ChannelID:,:h.MessageID:,:h.AuthorID:,:h.Content:)
+		"haiku_upsert": `INSERT INTO haiku (guild_id, channel_id, message_id, author_id, content, created_at, form)
+				   VALUES (:h.GuildID:,:h.ChannelID:,:h.MessageID:,:h.AuthorID:,:h.Content:,:h.CreatedAt:,:h.Form:)
                    ON CONFLICT(guild_id, channel_id, message_id)
-				   DO UPDATE SET content = excluded.content`,
-		"findByID": `SELECT * FROM haiku WHERE message_id = :messageID:`,
-	    "random": `SELECT * FROM haiku WHERE guild_id = :guildID: ORDER BY RANDOM() LIMIT 1`,
-	}
-	err := proteus.ShouldBuild(context.Background(), &HaikuDAO, proteus.Sqlite, m)
-	if err != nil {
-		panic(err)
+				   DO UPDATE SET content = excluded.content, form = excluded.form`,
+		"haiku_findByID": `SELECT * FROM haiku WHERE message_id = :messageID:`,
+	    "haiku_random": `SELECT * FROM haiku WHERE guild_id = :guildID: ORDER BY RANDOM() LIMIT 1`,
 	}
+	register(&HaikuDAO, m)
 }
\ No newline at end of file
@@ -2,36 +2,41 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jonbodner/proteus"
 	"log"
 )
 
+// ErrDuplicate is returned by CheckHash when another message already has the same hash.
+var ErrDuplicate = errors.New("haiku is a duplicate")
+
 var HaikuHashDAO HaikuHashDaoImpl
 
 type HaikuHashDaoImpl struct {
-	Upsert    func(ctx context.Context, e proteus.ContextExecutor, mid int, md5Sum []byte) (int64, error) `proq:"q:upsert" prop:"mid,md5Sum"`
-	FindByMD5 func(ctx context.Context, e proteus.ContextQuerier, md5Sum []byte) (int64, error)           `proq:"q:findByMD5" prop:"md5Sum"`
+	Upsert    func(ctx context.Context, e proteus.ContextExecutor, mid int, md5Sum []byte) (int64, error) `proq:"q:hash_upsert" prop:"mid,md5Sum"`
+	FindByMD5 func(ctx context.Context, e proteus.ContextQuerier, md5Sum []byte) (int64, error)           `proq:"q:hash_findByMD5" prop:"md5Sum"`
 }
 
 func init() {
 	m := proteus.MapMapper{
-		"upsert":    `INSERT INTO haiku_hash (message_id, md5_sum) VALUES (:mid:, :md5Sum:)
+		"hash_upsert":    `INSERT INTO haiku_hash (message_id, md5_sum) VALUES (:mid:, :md5Sum:)
   				      ON CONFLICT (message_id) 
 					  DO UPDATE SET md5_sum = excluded.md5_sum`,
-		"findByMD5": `SELECT message_id FROM haiku_hash WHERE md5_sum = :md5Sum:`,
-	}
-	err := proteus.ShouldBuild(context.Background(), &HaikuHashDAO, proteus.Sqlite, m)
-	if err != nil {
-		panic(err)
+		"hash_findByMD5": `SELECT message_id FROM haiku_hash WHERE md5_sum = :md5Sum:`,
 	}
+	register(&HaikuHashDAO, m)
 }
 
 func CheckHash(ctx context.Context, e proteus.ContextWrapper, mid int, hash [16]byte) error {
 	midFound, err := HaikuHashDAO.FindByMD5(ctx, e, hash[:])
 	if err != nil {
-		log.Println("haiku was found to be plagiarized; original message_id:", midFound, ",", err)
-		return err
+		log.Println("could not look up haiku hash in database,", err)
+		return fmt.Errorf("error while looking up haiku hash: %w", err)
+	}
+	if midFound != 0 && midFound != int64(mid) {
+		log.Println("haiku was found to be plagiarized; original message_id:", midFound)
+		return ErrDuplicate
 	}
 	_, err = HaikuHashDAO.Upsert(ctx, e, mid, hash[:])
 	if err != nil {
@@ -0,0 +1,603 @@
+package db
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// memoryStore is a Store which keeps everything in maps, answering each query the same way as the SQL it replaces.
+type memoryStore struct {
+	mu sync.Mutex
+
+	haiku        map[int]Haiku // keyed by message ID
+	hashes       map[int][16]byte
+	fingerprints map[int]int64
+	copies       map[int]Copy
+
+	guildConfigs   map[int]GuildConfig
+	channelConfigs map[int]ChannelConfig
+	settings       map[settingKey]Setting
+	words          map[int]map[string]GuildWord // keyed by guild ID, then word
+
+	attempts     map[int]Attempt
+	attemptWords map[int][]string // unknown words, keyed by the message ID of the attempt
+
+	suggestions      map[int]Suggestion
+	lastSuggestionID int
+
+	votes         map[voteKey]int64 // when each vote was cast
+	awards        map[awardKey]Award
+	awardChannels map[int]AwardChannel
+}
+
+type settingKey struct {
+	scope    string
+	targetID int
+	name     string
+}
+
+type voteKey struct {
+	messageID int
+	userID    string
+	emoji     string
+}
+
+type awardKey struct {
+	guildID  int
+	period   string
+	startsAt int64
+}
+
+// NewMemoryStore returns an empty Store which keeps everything in memory, for tests and short-lived bots which don't
+// need a database.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		haiku:          make(map[int]Haiku),
+		hashes:         make(map[int][16]byte),
+		fingerprints:   make(map[int]int64),
+		copies:         make(map[int]Copy),
+		guildConfigs:   make(map[int]GuildConfig),
+		channelConfigs: make(map[int]ChannelConfig),
+		settings:       make(map[settingKey]Setting),
+		words:          make(map[int]map[string]GuildWord),
+		attempts:       make(map[int]Attempt),
+		attemptWords:   make(map[int][]string),
+		suggestions:    make(map[int]Suggestion),
+		votes:          make(map[voteKey]int64),
+		awards:         make(map[awardKey]Award),
+		awardChannels:  make(map[int]AwardChannel),
+	}
+}
+
+func (s *memoryStore) SaveHaiku(ctx context.Context, h Haiku) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.haiku[h.MessageID]; ok {
+		existing.Content, existing.Form = h.Content, h.Form
+		h = existing
+	}
+	s.haiku[h.MessageID] = h
+	return nil
+}
+
+func (s *memoryStore) FindHaiku(ctx context.Context, messageID int) (Haiku, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.haiku[messageID], nil
+}
+
+func (s *memoryStore) RandomHaiku(ctx context.Context, guildID int) (Haiku, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var found []Haiku
+	for _, h := range s.haiku {
+		if h.GuildID == guildID {
+			found = append(found, h)
+		}
+	}
+	if len(found) == 0 {
+		return Haiku{}, nil
+	}
+	return found[rand.Intn(len(found))], nil
+}
+
+func (s *memoryStore) EachHaiku(ctx context.Context, fn func(Haiku)) error {
+	s.mu.Lock()
+	all := make([]Haiku, 0, len(s.haiku))
+	for _, h := range s.haiku {
+		all = append(all, h)
+	}
+	s.mu.Unlock() // fn is free to save things while it runs
+	for _, h := range all {
+		fn(h)
+	}
+	return nil
+}
+
+func (s *memoryStore) CheckHash(ctx context.Context, messageID int, hash [16]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for midFound, other := range s.hashes {
+		if other == hash && midFound != messageID {
+			log.Println("haiku was found to be plagiarized; original message_id:", midFound)
+			return ErrDuplicate
+		}
+	}
+	s.hashes[messageID] = hash
+	return nil
+}
+
+func (s *memoryStore) SaveHash(ctx context.Context, messageID int, hash [16]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hashes[messageID] = hash
+	return nil
+}
+
+func (s *memoryStore) SaveFingerprint(ctx context.Context, messageID int, fingerprint int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fingerprints[messageID] = fingerprint
+	return nil
+}
+
+func (s *memoryStore) GuildFingerprints(ctx context.Context, guildID int) ([]Fingerprint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []Fingerprint
+	for mid, fingerprint := range s.fingerprints {
+		if h, ok := s.haiku[mid]; ok && h.GuildID == guildID {
+			result = append(result, Fingerprint{MessageID: mid, AuthorID: h.AuthorID, CreatedAt: h.CreatedAt, Fingerprint: fingerprint})
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryStore) SaveCopy(ctx context.Context, c Copy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.copies[c.MessageID]; ok {
+		existing.OriginalID, existing.Distance = c.OriginalID, c.Distance
+		c = existing
+	}
+	s.copies[c.MessageID] = c
+	return nil
+}
+
+func (s *memoryStore) FindCopy(ctx context.Context, messageID int) (Copy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.copies[messageID], nil
+}
+
+func (s *memoryStore) GuildConfig(ctx context.Context, guildID int) (GuildConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.guildConfigs[guildID], nil
+}
+
+func (s *memoryStore) SaveGuildConfig(ctx context.Context, c GuildConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.guildConfigs[c.GuildID] = c
+	return nil
+}
+
+func (s *memoryStore) ChannelConfig(ctx context.Context, channelID int) (ChannelConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.channelConfigs[channelID], nil
+}
+
+func (s *memoryStore) SaveChannelConfig(ctx context.Context, c ChannelConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channelConfigs[c.ChannelID] = c
+	return nil
+}
+
+func (s *memoryStore) LookupFlags(ctx context.Context, guildID int, channelID int) (ConfigFlag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.guildConfigs[guildID].Flags.Or(s.channelConfigs[channelID].Flags), nil
+}
+
+func (s *memoryStore) Setting(ctx context.Context, scope string, targetID int, name string) (Setting, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settings[settingKey{scope, targetID, name}], nil
+}
+
+func (s *memoryStore) SaveSetting(ctx context.Context, setting Setting) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings[settingKey{setting.Scope, setting.TargetID, setting.Name}] = setting
+	return nil
+}
+
+func (s *memoryStore) LookupSetting(ctx context.Context, guildID int, channelID int, name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if setting, ok := s.settings[settingKey{ScopeChannel, channelID, name}]; ok {
+		return setting.Value, nil
+	}
+	return s.settings[settingKey{ScopeGuild, guildID, name}].Value, nil
+}
+
+func (s *memoryStore) GuildWords(ctx context.Context, guildID int) ([]GuildWord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []GuildWord
+	for _, w := range s.words[guildID] {
+		result = append(result, w)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Word < result[j].Word })
+	return result, nil
+}
+
+func (s *memoryStore) SaveGuildWord(ctx context.Context, w GuildWord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.words[w.GuildID] == nil {
+		s.words[w.GuildID] = make(map[string]GuildWord)
+	}
+	s.words[w.GuildID][w.Word] = w
+	return nil
+}
+
+func (s *memoryStore) DeleteGuildWord(ctx context.Context, guildID int, word string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.words[guildID][word]
+	delete(s.words[guildID], word)
+	return ok, nil
+}
+
+func (s *memoryStore) SaveAttempt(ctx context.Context, a Attempt, words []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.attempts[a.MessageID]; ok {
+		existing.Content, existing.Syllables, existing.UnknownWords = a.Content, a.Syllables, a.UnknownWords
+		a = existing
+	}
+	s.attempts[a.MessageID] = a
+	var unique []string
+	seen := make(map[string]bool)
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			unique = append(unique, word)
+		}
+	}
+	s.attemptWords[a.MessageID] = unique
+	return nil
+}
+
+func (s *memoryStore) DeleteAttempt(ctx context.Context, messageID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, messageID)
+	delete(s.attemptWords, messageID)
+	return nil
+}
+
+func (s *memoryStore) RecentAttempts(ctx context.Context, guildID int, limit int) ([]Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latestAttempts(limit, func(a Attempt) bool { return a.GuildID == guildID }), nil
+}
+
+func (s *memoryStore) TopWords(ctx context.Context, guildID int, limit int) ([]WordCount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[string]int)
+	for mid, words := range s.attemptWords {
+		if s.attempts[mid].GuildID != guildID {
+			continue
+		}
+		for _, word := range words {
+			counts[word]++
+		}
+	}
+	var result []WordCount
+	for word, attempts := range counts {
+		result = append(result, WordCount{Word: word, Attempts: attempts})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Attempts != result[j].Attempts {
+			return result[i].Attempts > result[j].Attempts
+		}
+		return result[i].Word < result[j].Word
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (s *memoryStore) BlockedBy(ctx context.Context, guildID int, word string, limit int) ([]Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latestAttempts(limit, func(a Attempt) bool {
+		if guildID != 0 && a.GuildID != guildID {
+			return false
+		}
+		for _, w := range s.attemptWords[a.MessageID] {
+			if w == word {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// latestAttempts lists the attempts which match a filter, newest first.
+func (s *memoryStore) latestAttempts(limit int, filter func(Attempt) bool) []Attempt {
+	var result []Attempt
+	for _, a := range s.attempts {
+		if filter(a) {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt != result[j].CreatedAt {
+			return result[i].CreatedAt > result[j].CreatedAt
+		}
+		return result[i].MessageID > result[j].MessageID
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+func (s *memoryStore) SaveSuggestion(ctx context.Context, suggestion Suggestion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	suggestion.Status = SuggestionPending
+	for id, existing := range s.suggestions {
+		if existing.GuildID == suggestion.GuildID && existing.UserID == suggestion.UserID && existing.Word == suggestion.Word {
+			existing.Syllables, existing.CreatedAt, existing.Status = suggestion.Syllables, suggestion.CreatedAt, suggestion.Status
+			s.suggestions[id] = existing
+			return nil
+		}
+	}
+	s.lastSuggestionID++
+	suggestion.ID = s.lastSuggestionID
+	s.suggestions[suggestion.ID] = suggestion
+	return nil
+}
+
+func (s *memoryStore) FindSuggestion(ctx context.Context, guildID int, id int) (Suggestion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if suggestion := s.suggestions[id]; suggestion.GuildID == guildID {
+		return suggestion, nil
+	}
+	return Suggestion{}, nil
+}
+
+func (s *memoryStore) PendingSuggestions(ctx context.Context, guildID int, limit int) ([]Suggestion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []Suggestion
+	for _, suggestion := range s.suggestions {
+		if suggestion.GuildID == guildID && suggestion.Status == SuggestionPending {
+			result = append(result, suggestion)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt != result[j].CreatedAt {
+			return result[i].CreatedAt < result[j].CreatedAt
+		}
+		return result[i].ID < result[j].ID
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (s *memoryStore) ReviewSuggestion(ctx context.Context, guildID int, id int, status string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	suggestion, ok := s.suggestions[id]
+	if !ok || suggestion.GuildID != guildID || suggestion.Status != SuggestionPending {
+		return false, nil
+	}
+	suggestion.Status = status
+	s.suggestions[id] = suggestion
+	return true, nil
+}
+
+func (s *memoryStore) AddVote(ctx context.Context, messageID int, userID string, emoji string, votedAt int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.haiku[messageID]
+	if !ok || h.AuthorID == userID {
+		return nil
+	}
+	key := voteKey{messageID, userID, emoji}
+	if _, ok := s.votes[key]; !ok {
+		s.votes[key] = votedAt
+	}
+	return nil
+}
+
+func (s *memoryStore) RemoveVote(ctx context.Context, messageID int, userID string, emoji string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.votes, voteKey{messageID, userID, emoji})
+	return nil
+}
+
+func (s *memoryStore) InsertAward(ctx context.Context, a Award) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := awardKey{a.GuildID, a.Period, a.StartsAt}
+	if _, ok := s.awards[key]; ok {
+		return false, nil
+	}
+	s.awards[key] = a
+	return true, nil
+}
+
+func (s *memoryStore) FindAward(ctx context.Context, guildID int, period string, startsAt int64) (Award, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.awards[awardKey{guildID, period, startsAt}], nil
+}
+
+func (s *memoryStore) AwardWinner(ctx context.Context, guildID int, start int64, end int64) (VotedHaiku, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	voters := make(map[int]map[string]bool)
+	for key, votedAt := range s.votes {
+		h := s.haiku[key.messageID]
+		if h.GuildID != guildID || h.CreatedAt < start || h.CreatedAt >= end || votedAt >= end {
+			continue
+		}
+		if voters[key.messageID] == nil {
+			voters[key.messageID] = make(map[string]bool)
+		}
+		voters[key.messageID][key.userID] = true
+	}
+	var winner VotedHaiku
+	for mid, users := range voters {
+		candidate := VotedHaiku{Haiku: s.haiku[mid], Votes: len(users)}
+		if winner.MessageID == 0 || candidate.Votes > winner.Votes ||
+			candidate.Votes == winner.Votes && candidate.CreatedAt < winner.CreatedAt ||
+			candidate.Votes == winner.Votes && candidate.CreatedAt == winner.CreatedAt && mid < winner.MessageID {
+			winner = candidate
+		}
+	}
+	return winner, nil
+}
+
+func (s *memoryStore) ListAwards(ctx context.Context, guildID int, period string, limit int) ([]AwardedHaiku, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []AwardedHaiku
+	for _, a := range s.awards {
+		h, ok := s.haiku[a.MessageID]
+		if !ok || a.GuildID != guildID || a.Period != period {
+			continue
+		}
+		result = append(result, AwardedHaiku{Haiku: h, Period: a.Period, StartsAt: a.StartsAt, Votes: a.Votes})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartsAt > result[j].StartsAt })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (s *memoryStore) AwardChannels(ctx context.Context) ([]AwardChannel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []AwardChannel
+	for _, c := range s.awardChannels {
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].GuildID < result[j].GuildID })
+	return result, nil
+}
+
+func (s *memoryStore) SaveAwardChannel(ctx context.Context, c AwardChannel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.awardChannels[c.GuildID] = c
+	return nil
+}
+
+func (s *memoryStore) DeleteAwardChannel(ctx context.Context, guildID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.awardChannels, guildID)
+	return nil
+}
+
+func (s *memoryStore) GuildSummary(ctx context.Context, guildID int) (Summary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var summary Summary
+	authors := make(map[string]bool)
+	for _, h := range s.haiku {
+		if h.GuildID == guildID {
+			summary.Haiku++
+			authors[h.AuthorID] = true
+		}
+	}
+	for _, a := range s.attempts {
+		if a.GuildID == guildID {
+			summary.Attempts++
+		}
+	}
+	summary.Authors = len(authors)
+	return summary, nil
+}
+
+func (s *memoryStore) AuthorSummary(ctx context.Context, guildID int, authorID string) (Summary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary := Summary{Authors: 1}
+	for _, h := range s.haiku {
+		if h.GuildID == guildID && h.AuthorID == authorID {
+			summary.Haiku++
+		}
+	}
+	for _, a := range s.attempts {
+		if a.GuildID == guildID && a.AuthorID == authorID {
+			summary.Attempts++
+		}
+	}
+	return summary, nil
+}
+
+func (s *memoryStore) TopAuthors(ctx context.Context, guildID int, limit int) ([]AuthorCount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[string]int)
+	first := make(map[string]int64)
+	for _, h := range s.haiku {
+		if h.GuildID != guildID {
+			continue
+		}
+		if created, ok := first[h.AuthorID]; !ok || h.CreatedAt < created {
+			first[h.AuthorID] = h.CreatedAt
+		}
+		counts[h.AuthorID]++
+	}
+	var result []AuthorCount
+	for author, count := range counts {
+		result = append(result, AuthorCount{AuthorID: author, Haiku: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Haiku != result[j].Haiku {
+			return result[i].Haiku > result[j].Haiku
+		}
+		return first[result[i].AuthorID] < first[result[j].AuthorID]
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (s *memoryStore) AuthorDays(ctx context.Context, guildID int, authorID string) ([]AuthorDay, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[int64]bool)
+	var result []AuthorDay
+	for _, h := range s.haiku {
+		if h.GuildID == guildID && h.AuthorID == authorID && !seen[h.CreatedAt/86400] {
+			seen[h.CreatedAt/86400] = true
+			result = append(result, AuthorDay{Day: h.CreatedAt / 86400})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Day < result[j].Day })
+	return result, nil
+}
@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jonbodner/proteus"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration is one of the scripts which build up the schema, applied to a database at most once.
+type Migration struct {
+	Version   int    `prof:"version"` // taken from the number the script's filename starts with
+	Name      string `prof:"name"`
+	AppliedAt int64  `prof:"applied_at"` // unix seconds; 0 if the migration hasn't been applied
+	script    string
+}
+
+func (m Migration) Applied() bool {
+	return m.AppliedAt != 0
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%03d_%s", m.Version, m.Name)
+}
+
+// sqliteLegacyVersion is the last migration released before versions were recorded, when every script was run on each
+// startup and any failures were ignored. Later migrations have never been run, so they're applied like any other.
+const sqliteLegacyVersion = 3
+
+var SchemaVersionDAO SchemaVersionDaoImpl
+
+type SchemaVersionDaoImpl struct {
+	Create func(ctx context.Context, e proteus.ContextExecutor) (int64, error)                                            `proq:"q:schema_create"`
+	Insert func(ctx context.Context, e proteus.ContextExecutor, version int, name string, appliedAt int64) (int64, error) `proq:"q:schema_insert" prop:"version,name,appliedAt"`
+	// All lists every migration applied to the database, in order.
+	All func(ctx context.Context, e proteus.ContextQuerier) ([]Migration, error) `proq:"q:schema_all"`
+	// CountTables counts the tables with a name, which is used to recognise databases created before versions were
+	// recorded.
+	CountTables func(ctx context.Context, e proteus.ContextQuerier, name string) (int, error) `proq:"q:schema_count_tables" prop:"name"`
+}
+
+func init() {
+	m := proteus.MapMapper{
+		"schema_create": `CREATE TABLE IF NOT EXISTS schema_version (
+							  version    INTEGER,
+							  name       TEXT,
+							  applied_at INTEGER, -- unix seconds
+							  PRIMARY KEY (version)
+						  )`,
+		"schema_insert":       `INSERT INTO schema_version (version, name, applied_at) VALUES (:version:, :name:, :appliedAt:)`,
+		"schema_all":          `SELECT * FROM schema_version ORDER BY version`,
+		"schema_count_tables": `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = :name:`,
+	}
+	register(&SchemaVersionDAO, m)
+}
+
+// Migrations lists every migration along with when it was applied to DB, in order. Databases created before versions
+// were recorded show every migration as pending until they're next migrated.
+func Migrations(DB *sql.DB) ([]Migration, error) {
+	return migrations(DB, bootstrapScripts)
+}
+
+// Migrate applies each migration which hasn't been applied to DB yet, in order and inside its own transaction,
+// returning the migrations which were applied. It stops at the first migration which fails.
+func Migrate(DB *sql.DB) ([]Migration, error) {
+	return migrate(DB, bootstrapScripts)
+}
+
+func migrations(DB *sql.DB, fsys fs.FS) ([]Migration, error) {
+	ctx := context.Background()
+	result, err := readMigrations(fsys, dialect.scripts())
+	if err != nil {
+		return nil, err
+	}
+	exists, err := hasTable(ctx, DB, "schema_version")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return result, nil
+	}
+	applied, err := SchemaVersionDAO.All(ctx, DB)
+	if err != nil {
+		return nil, fmt.Errorf("could not look up applied migrations: %w", err)
+	}
+	for _, a := range applied {
+		i := sort.Search(len(result), func(i int) bool { return result[i].Version >= a.Version })
+		if i == len(result) || result[i].Version != a.Version {
+			return nil, fmt.Errorf("database has migration %s applied, which is unknown to this version", a)
+		}
+		result[i].AppliedAt = a.AppliedAt
+	}
+	return result, nil
+}
+
+func migrate(DB *sql.DB, fsys fs.FS) ([]Migration, error) {
+	ctx := context.Background()
+	legacy, err := isLegacy(ctx, DB)
+	if err != nil {
+		return nil, err
+	}
+	_, err = SchemaVersionDAO.Create(ctx, DB)
+	if err != nil {
+		return nil, fmt.Errorf("could not create schema_version table: %w", err)
+	}
+	all, err := migrations(DB, fsys)
+	if err != nil {
+		return nil, err
+	}
+	var applied []Migration
+	for _, m := range all {
+		if m.Applied() {
+			continue
+		}
+		if legacy && m.Version <= dialect.legacyVersion() {
+			err = applyLegacy(ctx, DB, m)
+		} else {
+			err = apply(ctx, DB, m)
+		}
+		if err != nil {
+			return applied, fmt.Errorf("could not apply migration %s: %w", m, err)
+		}
+		log.Printf("applied migration %s", m)
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// isLegacy reports whether DB was created before versions were recorded, so its migrations have already been run at
+// least once.
+func isLegacy(ctx context.Context, DB *sql.DB) (bool, error) {
+	versioned, err := hasTable(ctx, DB, "schema_version")
+	if err != nil || versioned {
+		return false, err
+	}
+	return hasTable(ctx, DB, "haiku")
+}
+
+func hasTable(ctx context.Context, DB *sql.DB, name string) (bool, error) {
+	count, err := SchemaVersionDAO.CountTables(ctx, DB, name)
+	if err != nil {
+		return false, fmt.Errorf("could not look up %s table: %w", name, err)
+	}
+	return count > 0, nil
+}
+
+func apply(ctx context.Context, DB *sql.DB, m Migration) error {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, m.script)
+	if err == nil {
+		_, err = SchemaVersionDAO.Insert(ctx, tx, m.Version, m.Name, time.Now().Unix())
+	}
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Println("could not roll back migration,", rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// applyLegacy runs a migration the way it was run before versions were recorded, ignoring failures from changes
+// which were already made, then records it as applied.
+func applyLegacy(ctx context.Context, DB *sql.DB, m Migration) error {
+	_, err := DB.ExecContext(ctx, m.script)
+	if err != nil {
+		log.Printf("ignoring error from legacy migration %s: %v", m, err)
+	}
+	_, err = SchemaVersionDAO.Insert(ctx, DB, m.Version, m.Name, time.Now().Unix())
+	return err
+}
+
+// readMigrations loads every script ending in .sql from a directory, ordered by version.
+func readMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var result []Migration
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		parts := strings.SplitN(base, "_", 2)
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not read version of migration %s; filenames must start with a number", entry.Name())
+		}
+		script, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Migration{Version: version, Name: parts[len(parts)-1], script: string(script)})
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("could not find any *.sql files in schema folder %s", dir)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	for i := 1; i < len(result); i++ {
+		if result[i-1].Version == result[i].Version {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", result[i-1], result[i])
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openDB opens a new SQLite database, since migrations are tested from scratch.
+func openDB(t *testing.T) *sql.DB {
+	if dialect != SQLite {
+		t.Skip("migrations are tested against SQLite")
+	}
+	DB, err := sql.Open("sqlite3", path.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { DB.Close() })
+	return DB
+}
+
+func TestMigrate(t *testing.T) {
+	DB := openDB(t)
+
+	applied, err := Migrate(DB)
+	require.NoError(t, err)
+	all, err := Migrations(DB)
+	require.NoError(t, err)
+	assert.Equal(t, len(all), len(applied))
+	for _, m := range all {
+		assert.True(t, m.Applied(), m.String())
+	}
+
+	applied, err = Migrate(DB)
+	assert.NoError(t, err)
+	assert.Empty(t, applied, "migrations are only applied once")
+}
+
+func TestMigrate_Legacy(t *testing.T) {
+	DB := openDB(t)
+	all, err := readMigrations(bootstrapScripts, SQLite.scripts())
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ { // every released script was run on every startup, ignoring errors
+		for _, m := range all {
+			if m.Version <= sqliteLegacyVersion {
+				DB.Exec(m.script)
+			}
+		}
+	}
+
+	applied, err := Migrate(DB)
+	require.NoError(t, err)
+	assert.Equal(t, len(all), len(applied))
+
+	scripts := fstest.MapFS{
+		"scripts/000_first.sql":  {Data: []byte("CREATE TABLE haiku (id INTEGER);")},
+		"scripts/003_legacy.sql": {Data: []byte("ALTER TABLE haiku ADD COLUMN author_id TEXT;")},
+		"scripts/004_broken.sql": {Data: []byte("ALTER TABLE haiku ADD COLUMN author_id TEXT;")},
+	}
+	legacyDB := openDB(t)
+	legacyDB.Exec("CREATE TABLE haiku (id INTEGER, author_id TEXT);")
+	applied, err = migrate(legacyDB, scripts)
+	assert.EqualError(t, err, "could not apply migration 004_broken: duplicate column name: author_id",
+		"only migrations which were released before versions were recorded ignore errors")
+	assert.Len(t, applied, 2)
+
+	_, err = HaikuDAO.Upsert(context.Background(), DB, Haiku{GuildID: 1, ChannelID: 1, MessageID: 1, AuthorID: "1", Content: "haiku", Form: "haiku"})
+	assert.NoError(t, err)
+}
+
+func TestMigrate_Failure(t *testing.T) {
+	DB := openDB(t)
+	scripts := fstest.MapFS{
+		"scripts/000_first.sql":  {Data: []byte("CREATE TABLE first (id INTEGER);")},
+		"scripts/001_broken.sql": {Data: []byte("CREATE TABLE second (id INTEGER); INSERT INTO missing VALUES (1);")},
+		"scripts/002_third.sql":  {Data: []byte("CREATE TABLE third (id INTEGER);")},
+	}
+
+	applied, err := migrate(DB, scripts)
+	assert.EqualError(t, err, "could not apply migration 001_broken: no such table: missing")
+	if assert.Len(t, applied, 1) {
+		assert.Equal(t, "000_first", applied[0].String())
+	}
+	exists, err := hasTable(context.Background(), DB, "second")
+	assert.NoError(t, err)
+	assert.False(t, exists, "failed migrations are rolled back")
+
+	all, err := migrations(DB, scripts)
+	require.NoError(t, err)
+	if assert.Len(t, all, 3) {
+		assert.True(t, all[0].Applied())
+		assert.False(t, all[1].Applied())
+		assert.False(t, all[2].Applied())
+	}
+
+	scripts["scripts/001_broken.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE second (id INTEGER);")}
+	applied, err = migrate(DB, scripts)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 2)
+}
+
+func TestReadMigrations(t *testing.T) {
+	_, err := readMigrations(fstest.MapFS{"scripts/first.sql": {}}, "scripts")
+	assert.EqualError(t, err, "could not read version of migration first.sql; filenames must start with a number")
+
+	_, err = readMigrations(fstest.MapFS{"scripts/001_first.sql": {}, "scripts/1_again.sql": {}}, "scripts")
+	assert.EqualError(t, err, "migrations 001_first and 001_again have the same version")
+
+	_, err = readMigrations(fstest.MapFS{"scripts/README.md": {}}, "scripts")
+	assert.EqualError(t, err, "could not find any *.sql files in schema folder scripts")
+
+	_, err = migrations(openDB(t), fstest.MapFS{"scripts/000_first.sql": {}})
+	assert.NoError(t, err, "databases which haven't been migrated have no versions")
+}
@@ -0,0 +1,14 @@
+package db
+
+import "github.com/jonbodner/proteus"
+
+// postgresQueries replace the SQLite queries which Postgres can't run as written. Postgres can't infer the types of
+// parameters which are selected rather than compared, and has its own catalog of tables.
+var postgresQueries = proteus.MapMapper{
+	"vote_add": `INSERT INTO haiku_vote (message_id, user_id, emoji, voted_at)
+				 SELECT CAST(:mid: AS BIGINT), CAST(:userID: AS TEXT), CAST(:emoji: AS TEXT), CAST(:votedAt: AS BIGINT)
+				 WHERE EXISTS (SELECT 1 FROM haiku WHERE message_id = :mid: AND author_id != :userID:)
+				 ON CONFLICT (message_id, user_id, emoji) DO NOTHING`,
+	"schema_count_tables": `SELECT COUNT(*) FROM information_schema.tables
+							WHERE table_schema = current_schema() AND table_name = :name:`,
+}
@@ -0,0 +1,150 @@
+package db_test
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/lib/pq"
+)
+
+const (
+	// postgresDSNEnv names the variable holding a connection string for a Postgres server to run the tests against.
+	postgresDSNEnv = "HAIKU_POSTGRES_DSN"
+	// testPostgresEnv is set when the tests in this package are being re-run against Postgres.
+	testPostgresEnv = "HAIKU_TEST_POSTGRES"
+)
+
+// TestPostgres re-runs every test in this package against the Postgres server named by HAIKU_POSTGRES_DSN, or a
+// throwaway server started with initdb and pg_ctl if it isn't set.
+func TestPostgres(t *testing.T) {
+	if os.Getenv(testPostgresEnv) != "" {
+		t.Skip("already running against postgres")
+	}
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		dsn = startPostgres(t)
+	}
+	cmd := exec.Command(os.Args[0], "-test.count=1")
+	cmd.Env = append(os.Environ(), testPostgresEnv+"="+dsn)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("tests failed against postgres: %v\n%s", err, out)
+	}
+}
+
+// startPostgres starts a Postgres server in a temporary directory, which is stopped when the test ends, and returns a
+// connection string for it. The test is skipped if Postgres isn't installed, unless it's running in CI.
+func startPostgres(t *testing.T) string {
+	var pgCtl string
+	initdb, err := findPostgres("initdb")
+	if err == nil {
+		pgCtl, err = findPostgres("pg_ctl")
+	}
+	if err != nil {
+		if os.Getenv("CI") != "" {
+			t.Fatalf("%s is not set and postgres is not installed: %v", postgresDSNEnv, err)
+		}
+		t.Skipf("%s is not set and postgres is not installed: %v", postgresDSNEnv, err)
+	}
+
+	// t.TempDir isn't used since its parent can only be entered by the current user, who may not be the one running
+	// postgres.
+	dir, err := os.MkdirTemp("", "haiku-postgres")
+	if err != nil {
+		t.Fatalf("could not create a directory for postgres: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	attr, err := postgresProcAttr(dir)
+	if err != nil {
+		t.Fatalf("could not find a user other than root to run postgres as: %v", err)
+	}
+	data := filepath.Join(dir, "data")
+	initialise := exec.Command(initdb, "--pgdata", data, "--username", "postgres", "--auth", "trust", "--no-sync")
+	initialise.SysProcAttr = attr
+	if out, err := initialise.CombinedOutput(); err != nil {
+		t.Fatalf("could not initialise postgres in %s: %v\n%s", data, err, out)
+	}
+	port, err := freePort()
+	if err != nil {
+		t.Fatalf("could not find a free port for postgres: %v", err)
+	}
+	options := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off", port, dir)
+	start := exec.Command(pgCtl, "start", "--pgdata", data, "--log", filepath.Join(dir, "postgres.log"), "--wait", "-o", options)
+	start.SysProcAttr = attr
+	if out, err := start.CombinedOutput(); err != nil {
+		logs, _ := os.ReadFile(filepath.Join(dir, "postgres.log"))
+		t.Fatalf("could not start postgres: %v\n%s\n%s", err, out, logs)
+	}
+	t.Cleanup(func() {
+		stop := exec.Command(pgCtl, "stop", "--pgdata", data, "--mode", "immediate", "--wait")
+		stop.SysProcAttr = attr
+		if out, err := stop.CombinedOutput(); err != nil {
+			t.Logf("could not stop postgres: %v\n%s", err, out)
+		}
+	})
+	return fmt.Sprintf("host=127.0.0.1 port=%d user=postgres dbname=postgres sslmode=disable", port)
+}
+
+// findPostgres finds a Postgres program on the PATH, or in the versioned directories Debian and Ubuntu install it to.
+func findPostgres(name string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err == nil {
+		return path, nil
+	}
+	matches, _ := filepath.Glob(filepath.Join("/usr/lib/postgresql", "*", "bin", name))
+	if len(matches) == 0 {
+		return "", err
+	}
+	sort.Strings(matches)
+	return matches[len(matches)-1], nil
+}
+
+// freePort asks the OS for a TCP port nothing is listening on.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// runPostgres runs the tests in a schema of their own, which is dropped afterwards.
+func runPostgres(m *testing.M, dsn string) int {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		var err error
+		dsn, err = pq.ParseURL(dsn)
+		if err != nil {
+			log.Fatalf("could not parse %s: %v", postgresDSNEnv, err)
+		}
+	}
+	schema := fmt.Sprintf("haiku_test_%d", time.Now().UnixNano())
+	admin, err := db.Open(db.Postgres, dsn)
+	if err != nil {
+		log.Fatalf("could not connect to postgres: %v", err)
+	}
+	defer admin.Close()
+	if _, err = admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		log.Fatalf("could not create schema %s: %v", schema, err)
+	}
+	defer admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+
+	DB, err = db.Open(db.Postgres, dsn+" search_path="+schema)
+	if err != nil {
+		log.Fatalf("could not connect to postgres: %v", err)
+	}
+	defer DB.Close()
+	if err = db.BootstrapDB(DB); err != nil {
+		log.Fatalf("could not bootstrap postgres schema %s: %v", schema, err)
+	}
+	return m.Run()
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package db_test
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// postgresProcAttr returns the attributes to run initdb and pg_ctl with. initdb refuses to run as root, so when the
+// tests are running as root they're run as the postgres user, or nobody if there isn't one, and dir is handed to them.
+func postgresProcAttr(dir string) (*syscall.SysProcAttr, error) {
+	if os.Getuid() != 0 {
+		return nil, nil
+	}
+	u, err := user.Lookup("postgres")
+	if err != nil {
+		if u, err = user.Lookup("nobody"); err != nil {
+			return nil, err
+		}
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chown(dir, int(uid), int(gid)); err != nil {
+		return nil, err
+	}
+	return &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}}, nil
+}
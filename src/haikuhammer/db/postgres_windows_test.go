@@ -0,0 +1,8 @@
+package db_test
+
+import "syscall"
+
+// postgresProcAttr returns the attributes to run initdb and pg_ctl with; on Windows they run as the current user.
+func postgresProcAttr(dir string) (*syscall.SysProcAttr, error) {
+	return nil, nil
+}
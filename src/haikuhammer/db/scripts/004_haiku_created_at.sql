@@ -0,0 +1,2 @@
+ALTER TABLE haiku ADD COLUMN created_at INTEGER; -- unix seconds
+UPDATE haiku SET created_at = ((message_id >> 22) + 1420070400000) / 1000 WHERE created_at IS NULL; -- recover from snowflake IDs
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS haiku_vote (
+    message_id INTEGER,
+    user_id    TEXT,
+    emoji      TEXT,
+    voted_at   INTEGER, -- unix seconds
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+
+CREATE TABLE IF NOT EXISTS haiku_award (
+    guild_id   INTEGER,
+    period     TEXT,    -- week; month; year
+    starts_at  INTEGER, -- unix seconds at which the period began
+    message_id INTEGER, -- 0 if no haiku received any votes during the period
+    votes      INTEGER,
+    PRIMARY KEY (guild_id, period, starts_at)
+);
+
+CREATE TABLE IF NOT EXISTS award_channel (
+    guild_id   INTEGER,
+    channel_id INTEGER,
+    PRIMARY KEY (guild_id)
+);
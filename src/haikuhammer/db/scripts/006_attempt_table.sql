@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS haiku_attempt (
+    guild_id   INTEGER,
+    channel_id INTEGER,
+    message_id INTEGER,
+    author_id  TEXT,
+    created_at INTEGER, -- unix seconds
+    PRIMARY KEY (message_id)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS haiku_attempt_word (
+    message_id INTEGER,
+    guild_id   INTEGER,
+    word       TEXT, -- uppercase, as found in the dictionary
+    PRIMARY KEY (message_id, word)
+);
+
+ALTER TABLE haiku_attempt ADD COLUMN content TEXT;
+ALTER TABLE haiku_attempt ADD COLUMN syllables TEXT; -- syllables counted on each line, e.g. 5/8/5
+ALTER TABLE haiku_attempt ADD COLUMN unknown_words TEXT; -- comma-separated, as written in the message
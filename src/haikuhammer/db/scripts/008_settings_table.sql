@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS setting (
+    scope     TEXT,    -- 'guild' or 'channel'; settings for a channel take precedence over its guild
+    target_id INTEGER, -- guild or channel ID, depending on the scope
+    name      TEXT,
+    value     TEXT,
+    PRIMARY KEY (scope, target_id, name)
+);
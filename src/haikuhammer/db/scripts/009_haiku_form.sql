@@ -0,0 +1 @@
+ALTER TABLE haiku ADD COLUMN form TEXT NOT NULL DEFAULT 'haiku';
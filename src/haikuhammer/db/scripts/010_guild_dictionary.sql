@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS guild_dictionary (
+    guild_id  INTEGER,
+    word      TEXT, -- uppercase, as looked up in the dictionary
+    syllables INTEGER,
+    PRIMARY KEY (guild_id, word)
+);
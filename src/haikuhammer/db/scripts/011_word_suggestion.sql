@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS word_suggestion (
+    suggestion_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    guild_id      INTEGER,
+    user_id       TEXT,
+    word          TEXT, -- uppercase, as looked up in the dictionary
+    syllables     INTEGER,
+    created_at    INTEGER, -- unix seconds
+    status        TEXT,    -- pending, approved or rejected
+    UNIQUE (guild_id, user_id, word)
+);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS haiku_fingerprint (
+    message_id  INTEGER,
+    fingerprint INTEGER, -- SimHash of the haiku's words
+    PRIMARY KEY (message_id)
+);
+
+CREATE TABLE IF NOT EXISTS haiku_copy (
+    message_id  INTEGER,
+    guild_id    INTEGER,
+    author_id   TEXT,
+    original_id INTEGER, -- message_id of the earlier haiku which was copied
+    distance    INTEGER, -- bits which differ between the fingerprints of the two haiku
+    created_at  INTEGER, -- unix seconds
+    PRIMARY KEY (message_id)
+);
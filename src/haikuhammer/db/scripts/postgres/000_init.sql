@@ -0,0 +1,116 @@
+-- The schema built by the SQLite migrations up to 012_haiku_copies.sql. Discord IDs need 64 bits, so they're stored as
+-- BIGINT. Later migrations need to be written for both dialects.
+CREATE TABLE IF NOT EXISTS haiku (
+    guild_id   BIGINT,
+    channel_id BIGINT,
+    message_id BIGINT,
+    author_id  TEXT,
+    content    TEXT,
+    created_at BIGINT, -- unix seconds
+    form       TEXT NOT NULL DEFAULT 'haiku',
+    PRIMARY KEY (guild_id, channel_id, message_id)
+);
+
+CREATE TABLE IF NOT EXISTS channel_config (
+    channel_id BIGINT,
+    flags      BIGINT, -- see db.ConfigFlag
+    PRIMARY KEY (channel_id)
+);
+
+CREATE TABLE IF NOT EXISTS guild_config (
+    guild_id        BIGINT,
+    flags           BIGINT, -- see db.ConfigFlag
+    positive_reacts TEXT,
+    negative_reacts TEXT,
+    PRIMARY KEY (guild_id)
+);
+
+CREATE TABLE IF NOT EXISTS haiku_hash (
+    message_id BIGINT,
+    md5_sum    BYTEA,
+    PRIMARY KEY (message_id)
+);
+
+CREATE TABLE IF NOT EXISTS haiku_vote (
+    message_id BIGINT,
+    user_id    TEXT,
+    emoji      TEXT,
+    voted_at   BIGINT, -- unix seconds
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+
+CREATE TABLE IF NOT EXISTS haiku_award (
+    guild_id   BIGINT,
+    period     TEXT,   -- week; month; year
+    starts_at  BIGINT, -- unix seconds at which the period began
+    message_id BIGINT, -- 0 if no haiku received any votes during the period
+    votes      INTEGER,
+    PRIMARY KEY (guild_id, period, starts_at)
+);
+
+CREATE TABLE IF NOT EXISTS award_channel (
+    guild_id   BIGINT,
+    channel_id BIGINT,
+    PRIMARY KEY (guild_id)
+);
+
+CREATE TABLE IF NOT EXISTS haiku_attempt (
+    guild_id      BIGINT,
+    channel_id    BIGINT,
+    message_id    BIGINT,
+    author_id     TEXT,
+    created_at    BIGINT, -- unix seconds
+    content       TEXT,
+    syllables     TEXT,   -- syllables counted on each line, e.g. 5/8/5
+    unknown_words TEXT,   -- comma-separated, as written in the message
+    PRIMARY KEY (message_id)
+);
+
+CREATE TABLE IF NOT EXISTS haiku_attempt_word (
+    message_id BIGINT,
+    guild_id   BIGINT,
+    word       TEXT, -- uppercase, as found in the dictionary
+    PRIMARY KEY (message_id, word)
+);
+
+CREATE TABLE IF NOT EXISTS setting (
+    scope     TEXT,   -- 'guild' or 'channel'; settings for a channel take precedence over its guild
+    target_id BIGINT, -- guild or channel ID, depending on the scope
+    name      TEXT,
+    value     TEXT,
+    PRIMARY KEY (scope, target_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS guild_dictionary (
+    guild_id  BIGINT,
+    word      TEXT, -- uppercase, as looked up in the dictionary
+    syllables INTEGER,
+    PRIMARY KEY (guild_id, word)
+);
+
+CREATE TABLE IF NOT EXISTS word_suggestion (
+    suggestion_id BIGSERIAL PRIMARY KEY,
+    guild_id      BIGINT,
+    user_id       TEXT,
+    word          TEXT,   -- uppercase, as looked up in the dictionary
+    syllables     INTEGER,
+    created_at    BIGINT, -- unix seconds
+    status        TEXT,   -- pending, approved or rejected
+    UNIQUE (guild_id, user_id, word)
+);
+
+CREATE TABLE IF NOT EXISTS haiku_fingerprint (
+    message_id  BIGINT,
+    fingerprint BIGINT, -- SimHash of the haiku's words
+    PRIMARY KEY (message_id)
+);
+
+CREATE TABLE IF NOT EXISTS haiku_copy (
+    message_id  BIGINT,
+    guild_id    BIGINT,
+    author_id   TEXT,
+    original_id BIGINT,  -- message_id of the earlier haiku which was copied
+    distance    INTEGER, -- bits which differ between the fingerprints of the two haiku
+    created_at  BIGINT,  -- unix seconds
+    PRIMARY KEY (message_id)
+);
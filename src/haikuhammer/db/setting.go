@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"github.com/jonbodner/proteus"
+)
+
+// Names of settings which can be configured per guild or channel.
+const (
+	SettingForms         = "forms"          // comma-separated list of poetic forms accepted
+	SettingEnforcedForms = "enforced_forms" // comma-separated list of poetic forms DeleteNonHaiku keeps; defaults to SettingForms
+	SettingEstimates     = "estimates"      // how words whose syllables are estimated with low confidence are treated
+	SettingLanguage      = "language"       // name of the language messages are written in
+)
+
+// Scopes of settings. Guilds and channels are kept apart, since a guild's ID can be the same as one of its channels.
+const (
+	ScopeGuild   = "guild"
+	ScopeChannel = "channel"
+)
+
+// Setting is a named value configured for a guild or channel.
+type Setting struct {
+	Scope    string `prof:"scope"` // ScopeGuild or ScopeChannel
+	TargetID int    `prof:"target_id"`
+	Name     string `prof:"name"`
+	Value    string `prof:"value"`
+}
+
+var SettingDAO SettingDaoImpl
+
+type SettingDaoImpl struct {
+	Upsert func(ctx context.Context, e proteus.ContextExecutor, s Setting) (int64, error)                                `proq:"q:setting_upsert" prop:"s"`
+	Delete func(ctx context.Context, e proteus.ContextExecutor, scope string, targetID int, name string) (int64, error)  `proq:"q:setting_delete" prop:"scope,targetID,name"`
+	Find   func(ctx context.Context, e proteus.ContextQuerier, scope string, targetID int, name string) (Setting, error) `proq:"q:setting_find" prop:"scope,targetID,name"`
+}
+
+// LookupSetting returns the value of a setting for a channel, falling back to the value for its guild. Returns the
+// empty string if neither has been configured.
+func LookupSetting(ctx context.Context, e proteus.ContextQuerier, guildID int, channelID int, name string) (string, error) {
+	chanSetting, err := SettingDAO.Find(ctx, e, ScopeChannel, channelID, name)
+	if err != nil {
+		return "", err
+	}
+	if chanSetting.Name != "" {
+		return chanSetting.Value, nil
+	}
+	guildSetting, err := SettingDAO.Find(ctx, e, ScopeGuild, guildID, name)
+	if err != nil {
+		return "", err
+	}
+	return guildSetting.Value, nil
+}
+
+func init() {
+	m := proteus.MapMapper{
+		"setting_upsert": `INSERT INTO setting (scope, target_id, name, value) VALUES (:s.Scope:, :s.TargetID:, :s.Name:, :s.Value:)
+						   ON CONFLICT (scope, target_id, name)
+						   DO UPDATE SET value = excluded.value`,
+		"setting_delete": `DELETE FROM setting WHERE scope = :scope: AND target_id = :targetID: AND name = :name:`,
+		"setting_find":   `SELECT * FROM setting WHERE scope = :scope: AND target_id = :targetID: AND name = :name:`,
+	}
+	register(&SettingDAO, m)
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"github.com/jonbodner/proteus"
+)
+
+// Summary counts the haiku and rejected attempts sent by one or more authors.
+type Summary struct {
+	Haiku    int `prof:"haiku"`
+	Attempts int `prof:"attempts"`
+	Authors  int `prof:"authors"`
+}
+
+// AuthorCount is the number of haiku written by an author.
+type AuthorCount struct {
+	AuthorID string `prof:"author_id"`
+	Haiku    int    `prof:"haiku"`
+}
+
+// AuthorDay is a day on which an author wrote at least one haiku, counted in days since the unix epoch (UTC).
+type AuthorDay struct {
+	Day int64 `prof:"day"`
+}
+
+var StatsDAO StatsDaoImpl
+
+type StatsDaoImpl struct {
+	GuildSummary  func(ctx context.Context, e proteus.ContextQuerier, guildID int) (Summary, error)                  `proq:"q:stats_guild" prop:"guildID"`
+	AuthorSummary func(ctx context.Context, e proteus.ContextQuerier, guildID int, authorID string) (Summary, error) `proq:"q:stats_author" prop:"guildID,authorID"`
+	// TopAuthors lists the authors who have written the most haiku in a guild, most prolific first.
+	TopAuthors func(ctx context.Context, e proteus.ContextQuerier, guildID int, limit int) ([]AuthorCount, error) `proq:"q:stats_top" prop:"guildID,limit"`
+	// AuthorDays lists every day an author wrote a haiku in a guild, in ascending order.
+	AuthorDays func(ctx context.Context, e proteus.ContextQuerier, guildID int, authorID string) ([]AuthorDay, error) `proq:"q:stats_days" prop:"guildID,authorID"`
+}
+
+func init() {
+	m := proteus.MapMapper{
+		"stats_guild": `SELECT (SELECT COUNT(*) FROM haiku WHERE guild_id = :guildID:) AS haiku,
+							   (SELECT COUNT(*) FROM haiku_attempt WHERE guild_id = :guildID:) AS attempts,
+							   (SELECT COUNT(DISTINCT author_id) FROM haiku WHERE guild_id = :guildID:) AS authors`,
+		"stats_author": `SELECT (SELECT COUNT(*) FROM haiku WHERE guild_id = :guildID: AND author_id = :authorID:) AS haiku,
+								(SELECT COUNT(*) FROM haiku_attempt WHERE guild_id = :guildID: AND author_id = :authorID:) AS attempts,
+								1 AS authors`,
+		"stats_top": `SELECT author_id, COUNT(*) AS haiku FROM haiku WHERE guild_id = :guildID:
+					  GROUP BY author_id
+					  ORDER BY haiku DESC, MIN(created_at) ASC
+					  LIMIT :limit:`,
+		"stats_days": `SELECT DISTINCT created_at / 86400 AS day FROM haiku
+					   WHERE guild_id = :guildID: AND author_id = :authorID: AND created_at IS NOT NULL
+					   ORDER BY day`,
+	}
+	register(&StatsDAO, m)
+}
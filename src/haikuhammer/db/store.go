@@ -0,0 +1,348 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+)
+
+// Store holds everything the bot remembers between messages. Lookups which find nothing return the zero value and
+// no error.
+type Store interface {
+	HaikuStore
+	ConfigStore
+	AttemptStore
+	SuggestionStore
+	AwardStore
+	StatsStore
+}
+
+// HaikuStore holds saved haiku, along with the hashes and fingerprints used to spot haiku which were copied.
+type HaikuStore interface {
+	// SaveHaiku saves a haiku, replacing the content and form of any haiku saved for the same message.
+	SaveHaiku(ctx context.Context, h Haiku) error
+	FindHaiku(ctx context.Context, messageID int) (Haiku, error)
+	RandomHaiku(ctx context.Context, guildID int) (Haiku, error)
+	// EachHaiku calls fn with every saved haiku, in no particular order.
+	EachHaiku(ctx context.Context, fn func(Haiku)) error
+
+	// CheckHash stores the hash of a haiku, returning ErrDuplicate if another message already has the same hash.
+	CheckHash(ctx context.Context, messageID int, hash [16]byte) error
+	SaveHash(ctx context.Context, messageID int, hash [16]byte) error
+
+	SaveFingerprint(ctx context.Context, messageID int, fingerprint int64) error
+	// GuildFingerprints lists the fingerprints of every haiku saved in a guild.
+	GuildFingerprints(ctx context.Context, guildID int) ([]Fingerprint, error)
+	// SaveCopy records a copied haiku, replacing the original and distance of any copy saved for the same message.
+	SaveCopy(ctx context.Context, c Copy) error
+	FindCopy(ctx context.Context, messageID int) (Copy, error)
+}
+
+// ConfigStore holds the features, settings and dictionaries configured for each guild and channel.
+type ConfigStore interface {
+	GuildConfig(ctx context.Context, guildID int) (GuildConfig, error)
+	SaveGuildConfig(ctx context.Context, c GuildConfig) error
+	ChannelConfig(ctx context.Context, channelID int) (ChannelConfig, error)
+	SaveChannelConfig(ctx context.Context, c ChannelConfig) error
+	// LookupFlags returns the features enabled for either a guild or one of its channels.
+	LookupFlags(ctx context.Context, guildID int, channelID int) (ConfigFlag, error)
+
+	Setting(ctx context.Context, scope string, targetID int, name string) (Setting, error)
+	SaveSetting(ctx context.Context, s Setting) error
+	// LookupSetting returns the value of a setting for a channel, falling back to the value for its guild.
+	LookupSetting(ctx context.Context, guildID int, channelID int, name string) (string, error)
+
+	// GuildWords lists the words in a guild's dictionary, in alphabetical order.
+	GuildWords(ctx context.Context, guildID int) ([]GuildWord, error)
+	SaveGuildWord(ctx context.Context, w GuildWord) error
+	// DeleteGuildWord removes a word from a guild's dictionary, returning false if it wasn't there.
+	DeleteGuildWord(ctx context.Context, guildID int, word string) (bool, error)
+}
+
+// AttemptStore holds messages which were not accepted as haiku, and the unknown words which blocked them.
+type AttemptStore interface {
+	// SaveAttempt records an attempt along with the unknown words which blocked it, replacing the reasons previously
+	// recorded for the same message.
+	SaveAttempt(ctx context.Context, a Attempt, words []string) error
+	// DeleteAttempt forgets an attempt, used when a message is edited into a proper haiku.
+	DeleteAttempt(ctx context.Context, messageID int) error
+	// RecentAttempts lists the latest attempts in a guild, newest first.
+	RecentAttempts(ctx context.Context, guildID int, limit int) ([]Attempt, error)
+	// TopWords lists the unknown words which blocked the most attempts in a guild.
+	TopWords(ctx context.Context, guildID int, limit int) ([]WordCount, error)
+	// BlockedBy lists the latest attempts in a guild which were blocked by an unknown word, newest first. If guildID
+	// is 0, attempts in every guild are listed.
+	BlockedBy(ctx context.Context, guildID int, word string, limit int) ([]Attempt, error)
+}
+
+// SuggestionStore holds words suggested by users for review by moderators.
+type SuggestionStore interface {
+	// SaveSuggestion records a suggestion, replacing any earlier suggestion by the same user for the same word.
+	SaveSuggestion(ctx context.Context, s Suggestion) error
+	FindSuggestion(ctx context.Context, guildID int, id int) (Suggestion, error)
+	// PendingSuggestions lists the suggestions in a guild which haven't been reviewed, oldest first.
+	PendingSuggestions(ctx context.Context, guildID int, limit int) ([]Suggestion, error)
+	// ReviewSuggestion sets the status of a pending suggestion, returning false if it has already been reviewed.
+	ReviewSuggestion(ctx context.Context, guildID int, id int, status string) (bool, error)
+}
+
+// AwardStore holds votes for saved haiku, and the awards decided by them.
+type AwardStore interface {
+	// AddVote records a reaction to a saved haiku as a vote. Reactions to messages which are not saved haiku, and
+	// reactions by the author of the haiku are ignored.
+	AddVote(ctx context.Context, messageID int, userID string, emoji string, votedAt int64) error
+	RemoveVote(ctx context.Context, messageID int, userID string, emoji string) error
+
+	// InsertAward records an award, returning false if an award has already been recorded for the same period.
+	InsertAward(ctx context.Context, a Award) (bool, error)
+	FindAward(ctx context.Context, guildID int, period string, startsAt int64) (Award, error)
+	// AwardWinner finds the haiku created in [start, end) with the most votes cast before end.
+	AwardWinner(ctx context.Context, guildID int, start int64, end int64) (VotedHaiku, error)
+	// ListAwards returns the most recent winners of the provided period, newest first.
+	ListAwards(ctx context.Context, guildID int, period string, limit int) ([]AwardedHaiku, error)
+
+	AwardChannels(ctx context.Context) ([]AwardChannel, error)
+	SaveAwardChannel(ctx context.Context, c AwardChannel) error
+	DeleteAwardChannel(ctx context.Context, guildID int) error
+}
+
+// StatsStore summarises the haiku written in each guild.
+type StatsStore interface {
+	GuildSummary(ctx context.Context, guildID int) (Summary, error)
+	AuthorSummary(ctx context.Context, guildID int, authorID string) (Summary, error)
+	// TopAuthors lists the authors who have written the most haiku in a guild, most prolific first.
+	TopAuthors(ctx context.Context, guildID int, limit int) ([]AuthorCount, error)
+	// AuthorDays lists every day an author wrote a haiku in a guild, in ascending order.
+	AuthorDays(ctx context.Context, guildID int, authorID string) ([]AuthorDay, error)
+}
+
+// sqlStore is a Store backed by the DAOs, built for the current dialect.
+type sqlStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore returns a Store which keeps everything in the provided database.
+func NewSQLStore(DB *sql.DB) Store {
+	return sqlStore{db: DB}
+}
+
+func (s sqlStore) SaveHaiku(ctx context.Context, h Haiku) error {
+	_, err := HaikuDAO.Upsert(ctx, s.db, h)
+	return err
+}
+
+func (s sqlStore) FindHaiku(ctx context.Context, messageID int) (Haiku, error) {
+	return HaikuDAO.FindByID(ctx, s.db, messageID)
+}
+
+func (s sqlStore) RandomHaiku(ctx context.Context, guildID int) (Haiku, error) {
+	return HaikuDAO.Random(ctx, s.db, strconv.Itoa(guildID))
+}
+
+func (s sqlStore) EachHaiku(ctx context.Context, fn func(Haiku)) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT guild_id, channel_id, message_id, author_id, content,
+										  COALESCE(created_at, 0), form FROM haiku`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var h Haiku
+		err = rows.Scan(&h.GuildID, &h.ChannelID, &h.MessageID, &h.AuthorID, &h.Content, &h.CreatedAt, &h.Form)
+		if err != nil {
+			return err
+		}
+		fn(h)
+	}
+	return rows.Err()
+}
+
+func (s sqlStore) CheckHash(ctx context.Context, messageID int, hash [16]byte) error {
+	return CheckHash(ctx, s.db, messageID, hash)
+}
+
+func (s sqlStore) SaveHash(ctx context.Context, messageID int, hash [16]byte) error {
+	_, err := HaikuHashDAO.Upsert(ctx, s.db, messageID, hash[:])
+	return err
+}
+
+func (s sqlStore) SaveFingerprint(ctx context.Context, messageID int, fingerprint int64) error {
+	_, err := FingerprintDAO.Upsert(ctx, s.db, messageID, fingerprint)
+	return err
+}
+
+func (s sqlStore) GuildFingerprints(ctx context.Context, guildID int) ([]Fingerprint, error) {
+	return FingerprintDAO.FindByGuild(ctx, s.db, guildID)
+}
+
+func (s sqlStore) SaveCopy(ctx context.Context, c Copy) error {
+	_, err := CopyDAO.Upsert(ctx, s.db, c)
+	return err
+}
+
+func (s sqlStore) FindCopy(ctx context.Context, messageID int) (Copy, error) {
+	return CopyDAO.FindByID(ctx, s.db, messageID)
+}
+
+func (s sqlStore) GuildConfig(ctx context.Context, guildID int) (GuildConfig, error) {
+	return GuildConfigDAO.FindByID(ctx, s.db, guildID)
+}
+
+func (s sqlStore) SaveGuildConfig(ctx context.Context, c GuildConfig) error {
+	_, err := GuildConfigDAO.Upsert(ctx, s.db, c)
+	return err
+}
+
+func (s sqlStore) ChannelConfig(ctx context.Context, channelID int) (ChannelConfig, error) {
+	return ChannelConfigDAO.FindByID(ctx, s.db, channelID)
+}
+
+func (s sqlStore) SaveChannelConfig(ctx context.Context, c ChannelConfig) error {
+	_, err := ChannelConfigDAO.Upsert(ctx, s.db, c.ChannelID, c.Flags)
+	return err
+}
+
+func (s sqlStore) LookupFlags(ctx context.Context, guildID int, channelID int) (ConfigFlag, error) {
+	return LookupFlags(ctx, s.db, guildID, channelID)
+}
+
+func (s sqlStore) Setting(ctx context.Context, scope string, targetID int, name string) (Setting, error) {
+	return SettingDAO.Find(ctx, s.db, scope, targetID, name)
+}
+
+func (s sqlStore) SaveSetting(ctx context.Context, setting Setting) error {
+	_, err := SettingDAO.Upsert(ctx, s.db, setting)
+	return err
+}
+
+func (s sqlStore) LookupSetting(ctx context.Context, guildID int, channelID int, name string) (string, error) {
+	return LookupSetting(ctx, s.db, guildID, channelID, name)
+}
+
+func (s sqlStore) GuildWords(ctx context.Context, guildID int) ([]GuildWord, error) {
+	return GuildDictionaryDAO.List(ctx, s.db, guildID)
+}
+
+func (s sqlStore) SaveGuildWord(ctx context.Context, w GuildWord) error {
+	_, err := GuildDictionaryDAO.Upsert(ctx, s.db, w)
+	return err
+}
+
+func (s sqlStore) DeleteGuildWord(ctx context.Context, guildID int, word string) (bool, error) {
+	deleted, err := GuildDictionaryDAO.Delete(ctx, s.db, guildID, word)
+	return deleted > 0, err
+}
+
+func (s sqlStore) SaveAttempt(ctx context.Context, a Attempt, words []string) error {
+	_, err := AttemptDAO.Upsert(ctx, s.db, a)
+	if err != nil {
+		return err
+	}
+	_, err = AttemptDAO.DeleteWords(ctx, s.db, a.MessageID) // the message may have been edited
+	if err != nil {
+		return err
+	}
+	for _, word := range words {
+		_, err = AttemptDAO.AddWord(ctx, s.db, a.MessageID, a.GuildID, word)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s sqlStore) DeleteAttempt(ctx context.Context, messageID int) error {
+	_, err := AttemptDAO.Delete(ctx, s.db, messageID)
+	if err != nil {
+		return err
+	}
+	_, err = AttemptDAO.DeleteWords(ctx, s.db, messageID)
+	return err
+}
+
+func (s sqlStore) RecentAttempts(ctx context.Context, guildID int, limit int) ([]Attempt, error) {
+	return AttemptDAO.Recent(ctx, s.db, guildID, limit)
+}
+
+func (s sqlStore) TopWords(ctx context.Context, guildID int, limit int) ([]WordCount, error) {
+	return AttemptDAO.TopWords(ctx, s.db, guildID, limit)
+}
+
+func (s sqlStore) BlockedBy(ctx context.Context, guildID int, word string, limit int) ([]Attempt, error) {
+	return AttemptDAO.BlockedBy(ctx, s.db, guildID, word, limit)
+}
+
+func (s sqlStore) SaveSuggestion(ctx context.Context, suggestion Suggestion) error {
+	_, err := SuggestionDAO.Upsert(ctx, s.db, suggestion)
+	return err
+}
+
+func (s sqlStore) FindSuggestion(ctx context.Context, guildID int, id int) (Suggestion, error) {
+	return SuggestionDAO.Find(ctx, s.db, guildID, id)
+}
+
+func (s sqlStore) PendingSuggestions(ctx context.Context, guildID int, limit int) ([]Suggestion, error) {
+	return SuggestionDAO.Pending(ctx, s.db, guildID, limit)
+}
+
+func (s sqlStore) ReviewSuggestion(ctx context.Context, guildID int, id int, status string) (bool, error) {
+	reviewed, err := SuggestionDAO.Review(ctx, s.db, guildID, id, status)
+	return reviewed > 0, err
+}
+
+func (s sqlStore) AddVote(ctx context.Context, messageID int, userID string, emoji string, votedAt int64) error {
+	_, err := VoteDAO.Add(ctx, s.db, messageID, userID, emoji, votedAt)
+	return err
+}
+
+func (s sqlStore) RemoveVote(ctx context.Context, messageID int, userID string, emoji string) error {
+	_, err := VoteDAO.Remove(ctx, s.db, messageID, userID, emoji)
+	return err
+}
+
+func (s sqlStore) InsertAward(ctx context.Context, a Award) (bool, error) {
+	inserted, err := AwardDAO.Insert(ctx, s.db, a)
+	return inserted > 0, err
+}
+
+func (s sqlStore) FindAward(ctx context.Context, guildID int, period string, startsAt int64) (Award, error) {
+	return AwardDAO.Find(ctx, s.db, guildID, period, startsAt)
+}
+
+func (s sqlStore) AwardWinner(ctx context.Context, guildID int, start int64, end int64) (VotedHaiku, error) {
+	return AwardDAO.Winner(ctx, s.db, guildID, start, end)
+}
+
+func (s sqlStore) ListAwards(ctx context.Context, guildID int, period string, limit int) ([]AwardedHaiku, error) {
+	return AwardDAO.List(ctx, s.db, guildID, period, limit)
+}
+
+func (s sqlStore) AwardChannels(ctx context.Context) ([]AwardChannel, error) {
+	return AwardChannelDAO.FindAll(ctx, s.db)
+}
+
+func (s sqlStore) SaveAwardChannel(ctx context.Context, c AwardChannel) error {
+	_, err := AwardChannelDAO.Upsert(ctx, s.db, c)
+	return err
+}
+
+func (s sqlStore) DeleteAwardChannel(ctx context.Context, guildID int) error {
+	_, err := AwardChannelDAO.Delete(ctx, s.db, guildID)
+	return err
+}
+
+func (s sqlStore) GuildSummary(ctx context.Context, guildID int) (Summary, error) {
+	return StatsDAO.GuildSummary(ctx, s.db, guildID)
+}
+
+func (s sqlStore) AuthorSummary(ctx context.Context, guildID int, authorID string) (Summary, error) {
+	return StatsDAO.AuthorSummary(ctx, s.db, guildID, authorID)
+}
+
+func (s sqlStore) TopAuthors(ctx context.Context, guildID int, limit int) ([]AuthorCount, error) {
+	return StatsDAO.TopAuthors(ctx, s.db, guildID, limit)
+}
+
+func (s sqlStore) AuthorDays(ctx context.Context, guildID int, authorID string) ([]AuthorDay, error) {
+	return StatsDAO.AuthorDays(ctx, s.db, guildID, authorID)
+}
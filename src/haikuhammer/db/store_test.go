@@ -0,0 +1,277 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLStore(t *testing.T) {
+	testStore(t, db.NewSQLStore(DB))
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, db.NewMemoryStore())
+}
+
+// testStore checks that a store answers the way the bot expects. IDs are unique to this test, since the SQL store
+// shares its database with the other tests.
+func testStore(t *testing.T, store db.Store) {
+	t.Run("haiku", func(t *testing.T) { testStoreHaiku(t, store) })
+	t.Run("config", func(t *testing.T) { testStoreConfig(t, store) })
+	t.Run("attempts", func(t *testing.T) { testStoreAttempts(t, store) })
+	t.Run("suggestions", func(t *testing.T) { testStoreSuggestions(t, store) })
+	t.Run("awards", func(t *testing.T) { testStoreAwards(t, store) })
+	t.Run("stats", func(t *testing.T) { testStoreStats(t, store) })
+}
+
+func testStoreHaiku(t *testing.T, store db.Store) {
+	ctx := context.Background()
+	haiku, err := store.RandomHaiku(ctx, 5700)
+	assert.NoError(t, err)
+	assert.Empty(t, haiku.Content, "guild has no haiku yet")
+
+	original := db.Haiku{GuildID: 5700, ChannelID: 5701, MessageID: 5702, AuthorID: "a", Content: "first", CreatedAt: 100, Form: "haiku"}
+	require.NoError(t, store.SaveHaiku(ctx, original))
+	require.NoError(t, store.SaveHaiku(ctx, db.Haiku{GuildID: 5700, ChannelID: 5701, MessageID: 5702, AuthorID: "a", Content: "edited", CreatedAt: 200, Form: "tanka"}))
+
+	haiku, err = store.FindHaiku(ctx, 5702)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Haiku{GuildID: 5700, ChannelID: 5701, MessageID: 5702, AuthorID: "a", Content: "edited", CreatedAt: 100, Form: "tanka"}, haiku)
+
+	haiku, err = store.RandomHaiku(ctx, 5700)
+	assert.NoError(t, err)
+	assert.Equal(t, 5702, haiku.MessageID)
+
+	found := false
+	assert.NoError(t, store.EachHaiku(ctx, func(h db.Haiku) {
+		found = found || h.MessageID == 5702 && h.Content == "edited"
+	}))
+	assert.True(t, found)
+
+	assert.NoError(t, store.CheckHash(ctx, 5702, [16]byte{57, 2}))
+	assert.NoError(t, store.CheckHash(ctx, 5702, [16]byte{57, 2}), "a message can't copy itself")
+	assert.ErrorIs(t, store.CheckHash(ctx, 5703, [16]byte{57, 2}), db.ErrDuplicate)
+	assert.NoError(t, store.SaveHash(ctx, 5702, [16]byte{57, 3}))
+	assert.NoError(t, store.CheckHash(ctx, 5703, [16]byte{57, 2}))
+
+	require.NoError(t, store.SaveFingerprint(ctx, 5702, -5702))
+	require.NoError(t, store.SaveFingerprint(ctx, 5799, 5799)) // not a saved haiku
+	fingerprints, err := store.GuildFingerprints(ctx, 5700)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.Fingerprint{{MessageID: 5702, AuthorID: "a", CreatedAt: 100, Fingerprint: -5702}}, fingerprints)
+
+	require.NoError(t, store.SaveCopy(ctx, db.Copy{GuildID: 5700, MessageID: 5704, AuthorID: "b", OriginalID: 5702, Distance: 3, CreatedAt: 300}))
+	require.NoError(t, store.SaveCopy(ctx, db.Copy{GuildID: 5700, MessageID: 5704, AuthorID: "b", OriginalID: 5705, Distance: 1, CreatedAt: 400}))
+	c, err := store.FindCopy(ctx, 5704)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Copy{GuildID: 5700, MessageID: 5704, AuthorID: "b", OriginalID: 5705, Distance: 1, CreatedAt: 300}, c)
+}
+
+func testStoreConfig(t *testing.T, store db.Store) {
+	ctx := context.Background()
+	flags, err := store.LookupFlags(ctx, 5710, 5711)
+	assert.NoError(t, err)
+	assert.Zero(t, flags)
+
+	guild := db.GuildConfig{GuildID: 5710, Flags: db.ConfigReactToHaiku, PositiveReacts: "+", NegativeReacts: "-"}
+	require.NoError(t, store.SaveGuildConfig(ctx, guild))
+	require.NoError(t, store.SaveChannelConfig(ctx, db.ChannelConfig{ChannelID: 5711, Flags: db.ConfigDeleteNonHaiku}))
+	found, err := store.GuildConfig(ctx, 5710)
+	assert.NoError(t, err)
+	assert.Equal(t, guild, found)
+	channel, err := store.ChannelConfig(ctx, 5711)
+	assert.NoError(t, err)
+	assert.Equal(t, db.ConfigDeleteNonHaiku, channel.Flags)
+	flags, err = store.LookupFlags(ctx, 5710, 5711)
+	assert.NoError(t, err)
+	assert.Equal(t, db.ConfigReactToHaiku|db.ConfigDeleteNonHaiku, flags)
+
+	require.NoError(t, store.SaveSetting(ctx, db.Setting{Scope: db.ScopeGuild, TargetID: 5710, Name: db.SettingForms, Value: "haiku"}))
+	value, err := store.LookupSetting(ctx, 5710, 5711, db.SettingForms)
+	assert.NoError(t, err)
+	assert.Equal(t, "haiku", value, "channels fall back to their guild")
+	require.NoError(t, store.SaveSetting(ctx, db.Setting{Scope: db.ScopeChannel, TargetID: 5711, Name: db.SettingForms, Value: "tanka"}))
+	value, err = store.LookupSetting(ctx, 5710, 5711, db.SettingForms)
+	assert.NoError(t, err)
+	assert.Equal(t, "tanka", value)
+	require.NoError(t, store.SaveSetting(ctx, db.Setting{Scope: db.ScopeChannel, TargetID: 5710, Name: db.SettingForms, Value: "cinquain"}))
+	value, err = store.LookupSetting(ctx, 5710, 5712, db.SettingForms)
+	assert.NoError(t, err)
+	assert.Equal(t, "haiku", value, "a channel sharing its guild's ID is kept apart from the guild")
+	setting, err := store.Setting(ctx, db.ScopeGuild, 5710, db.SettingLanguage)
+	assert.NoError(t, err)
+	assert.Empty(t, setting.Name)
+
+	require.NoError(t, store.SaveGuildWord(ctx, db.GuildWord{GuildID: 5710, Word: "yeet", Syllables: 1}))
+	require.NoError(t, store.SaveGuildWord(ctx, db.GuildWord{GuildID: 5710, Word: "pokemon", Syllables: 2}))
+	require.NoError(t, store.SaveGuildWord(ctx, db.GuildWord{GuildID: 5710, Word: "pokemon", Syllables: 3}))
+	words, err := store.GuildWords(ctx, 5710)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.GuildWord{{GuildID: 5710, Word: "pokemon", Syllables: 3}, {GuildID: 5710, Word: "yeet", Syllables: 1}}, words)
+	deleted, err := store.DeleteGuildWord(ctx, 5710, "yeet")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = store.DeleteGuildWord(ctx, 5710, "yeet")
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func testStoreAttempts(t *testing.T, store db.Store) {
+	ctx := context.Background()
+	require.NoError(t, store.SaveAttempt(ctx, db.Attempt{GuildID: 5720, ChannelID: 5721, MessageID: 5722, AuthorID: "a", CreatedAt: 100,
+		Content: "first", Syllables: "5/8/5", UnknownWords: "zorp"}, []string{"zorp"}))
+	require.NoError(t, store.SaveAttempt(ctx, db.Attempt{GuildID: 5720, ChannelID: 5721, MessageID: 5723, AuthorID: "b", CreatedAt: 200,
+		Content: "second", Syllables: "5/7/6", UnknownWords: "zorp, blep"}, []string{"zorp", "blep", "blep"}))
+	require.NoError(t, store.SaveAttempt(ctx, db.Attempt{GuildID: 5725, ChannelID: 5726, MessageID: 5727, AuthorID: "a", CreatedAt: 300,
+		Content: "elsewhere", Syllables: "5/7/6", UnknownWords: "zorp"}, []string{"zorp"}))
+
+	words, err := store.TopWords(ctx, 5720, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.WordCount{{Word: "zorp", Attempts: 2}, {Word: "blep", Attempts: 1}}, words)
+
+	// editing a message replaces its reasons, but not when it was sent
+	require.NoError(t, store.SaveAttempt(ctx, db.Attempt{GuildID: 5720, ChannelID: 5721, MessageID: 5722, AuthorID: "a", CreatedAt: 400,
+		Content: "edited", Syllables: "5/7/4", UnknownWords: "blep"}, []string{"blep"}))
+	recent, err := store.RecentAttempts(ctx, 5720, 5)
+	assert.NoError(t, err)
+	require.Len(t, recent, 2)
+	assert.Equal(t, 5723, recent[0].MessageID)
+	assert.Equal(t, db.Attempt{GuildID: 5720, ChannelID: 5721, MessageID: 5722, AuthorID: "a", CreatedAt: 100,
+		Content: "edited", Syllables: "5/7/4", UnknownWords: "blep"}, recent[1])
+
+	blocked, err := store.BlockedBy(ctx, 5720, "zorp", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5723}, attemptIDs(blocked))
+	blocked, err = store.BlockedBy(ctx, 0, "zorp", 5)
+	assert.NoError(t, err)
+	assert.Subset(t, attemptIDs(blocked), []int{5727, 5723})
+
+	require.NoError(t, store.DeleteAttempt(ctx, 5723))
+	words, err = store.TopWords(ctx, 5720, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.WordCount{{Word: "blep", Attempts: 1}}, words)
+}
+
+func attemptIDs(attempts []db.Attempt) []int {
+	var result []int
+	for _, a := range attempts {
+		result = append(result, a.MessageID)
+	}
+	return result
+}
+
+func testStoreSuggestions(t *testing.T, store db.Store) {
+	ctx := context.Background()
+	require.NoError(t, store.SaveSuggestion(ctx, db.Suggestion{GuildID: 5730, UserID: "a", Word: "yeet", Syllables: 2, CreatedAt: 100}))
+	require.NoError(t, store.SaveSuggestion(ctx, db.Suggestion{GuildID: 5730, UserID: "b", Word: "blep", Syllables: 1, CreatedAt: 200}))
+	require.NoError(t, store.SaveSuggestion(ctx, db.Suggestion{GuildID: 5730, UserID: "a", Word: "yeet", Syllables: 1, CreatedAt: 300}))
+
+	pending, err := store.PendingSuggestions(ctx, 5730, 5)
+	assert.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "blep", pending[0].Word)
+	assert.Equal(t, 1, pending[1].Syllables, "a user's later suggestion replaces their earlier one")
+
+	reviewed, err := store.ReviewSuggestion(ctx, 5730, pending[0].ID, db.SuggestionApproved)
+	assert.NoError(t, err)
+	assert.True(t, reviewed)
+	reviewed, err = store.ReviewSuggestion(ctx, 5730, pending[0].ID, db.SuggestionRejected)
+	assert.NoError(t, err)
+	assert.False(t, reviewed)
+
+	found, err := store.FindSuggestion(ctx, 5730, pending[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, db.SuggestionApproved, found.Status)
+	found, err = store.FindSuggestion(ctx, 5731, pending[0].ID)
+	assert.NoError(t, err)
+	assert.Zero(t, found.ID, "suggestions can't be found from other guilds")
+}
+
+func testStoreAwards(t *testing.T, store db.Store) {
+	ctx := context.Background()
+	require.NoError(t, store.SaveHaiku(ctx, db.Haiku{GuildID: 5740, ChannelID: 5741, MessageID: 5742, AuthorID: "a", Content: "one", CreatedAt: 100}))
+	require.NoError(t, store.SaveHaiku(ctx, db.Haiku{GuildID: 5740, ChannelID: 5741, MessageID: 5743, AuthorID: "b", Content: "two", CreatedAt: 150}))
+
+	winner, err := store.AwardWinner(ctx, 5740, 0, 1000)
+	assert.NoError(t, err)
+	assert.Zero(t, winner.MessageID, "nobody has voted")
+
+	require.NoError(t, store.AddVote(ctx, 5742, "a", "👍", 200)) // authors can't vote for themselves
+	require.NoError(t, store.AddVote(ctx, 5742, "b", "👍", 200))
+	require.NoError(t, store.AddVote(ctx, 5742, "b", "🎉", 200))
+	require.NoError(t, store.AddVote(ctx, 5743, "a", "👍", 200))
+	require.NoError(t, store.AddVote(ctx, 5743, "c", "👍", 200))
+	require.NoError(t, store.AddVote(ctx, 5743, "d", "👍", 2000)) // too late
+	require.NoError(t, store.AddVote(ctx, 5749, "a", "👍", 200))  // not a haiku
+
+	winner, err = store.AwardWinner(ctx, 5740, 0, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, 5743, winner.MessageID)
+	assert.Equal(t, 2, winner.Votes)
+
+	require.NoError(t, store.RemoveVote(ctx, 5743, "c", "👍"))
+	winner, err = store.AwardWinner(ctx, 5740, 0, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, 5742, winner.MessageID, "ties go to the earlier haiku")
+	assert.Equal(t, 1, winner.Votes)
+
+	inserted, err := store.InsertAward(ctx, db.Award{GuildID: 5740, Period: "weekly", StartsAt: 0, MessageID: 5742, Votes: 1})
+	assert.NoError(t, err)
+	assert.True(t, inserted)
+	inserted, err = store.InsertAward(ctx, db.Award{GuildID: 5740, Period: "weekly", StartsAt: 0, MessageID: 5743, Votes: 1})
+	assert.NoError(t, err)
+	assert.False(t, inserted)
+	_, err = store.InsertAward(ctx, db.Award{GuildID: 5740, Period: "weekly", StartsAt: 1000})
+	assert.NoError(t, err)
+	_, err = store.InsertAward(ctx, db.Award{GuildID: 5740, Period: "weekly", StartsAt: 2000, MessageID: 5743, Votes: 3})
+	assert.NoError(t, err)
+
+	award, err := store.FindAward(ctx, 5740, "weekly", 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Award{GuildID: 5740, Period: "weekly", StartsAt: 1000}, award)
+
+	awards, err := store.ListAwards(ctx, 5740, "weekly", 5)
+	assert.NoError(t, err)
+	require.Len(t, awards, 2, "periods nobody won aren't listed")
+	assert.Equal(t, db.AwardedHaiku{Haiku: db.Haiku{GuildID: 5740, ChannelID: 5741, MessageID: 5743, AuthorID: "b", Content: "two", CreatedAt: 150},
+		Period: "weekly", StartsAt: 2000, Votes: 3}, awards[0])
+	assert.Equal(t, 5742, awards[1].MessageID)
+
+	require.NoError(t, store.SaveAwardChannel(ctx, db.AwardChannel{GuildID: 5740, ChannelID: 5741}))
+	channels, err := store.AwardChannels(ctx)
+	assert.NoError(t, err)
+	assert.Contains(t, channels, db.AwardChannel{GuildID: 5740, ChannelID: 5741})
+	require.NoError(t, store.DeleteAwardChannel(ctx, 5740))
+	channels, err = store.AwardChannels(ctx)
+	assert.NoError(t, err)
+	assert.NotContains(t, channels, db.AwardChannel{GuildID: 5740, ChannelID: 5741})
+}
+
+func testStoreStats(t *testing.T, store db.Store) {
+	ctx := context.Background()
+	for i, author := range []string{"a", "b", "b", "a", "c"} {
+		require.NoError(t, store.SaveHaiku(ctx, db.Haiku{GuildID: 5750, ChannelID: 5751, MessageID: 5760 + i, AuthorID: author,
+			Content: "haiku", CreatedAt: int64(i) * 43200}))
+	}
+	require.NoError(t, store.SaveAttempt(ctx, db.Attempt{GuildID: 5750, ChannelID: 5751, MessageID: 5770, AuthorID: "a", Content: "nope"}, nil))
+
+	summary, err := store.GuildSummary(ctx, 5750)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Summary{Haiku: 5, Attempts: 1, Authors: 3}, summary)
+
+	summary, err = store.AuthorSummary(ctx, 5750, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, db.Summary{Haiku: 2, Attempts: 1, Authors: 1}, summary)
+
+	top, err := store.TopAuthors(ctx, 5750, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.AuthorCount{{AuthorID: "a", Haiku: 2}, {AuthorID: "b", Haiku: 2}}, top, "ties go to whoever wrote first")
+
+	days, err := store.AuthorDays(ctx, 5750, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, []db.AuthorDay{{Day: 0}, {Day: 1}}, days)
+}
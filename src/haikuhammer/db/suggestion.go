@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"github.com/jonbodner/proteus"
+)
+
+// Statuses of a Suggestion.
+const (
+	SuggestionPending  = "pending"
+	SuggestionApproved = "approved"
+	SuggestionRejected = "rejected"
+)
+
+// Suggestion is a user's suggestion for the number of syllables in a word, waiting for review by a moderator.
+type Suggestion struct {
+	ID        int    `prof:"suggestion_id"`
+	GuildID   int    `prof:"guild_id"`
+	UserID    string `prof:"user_id"`
+	Word      string `prof:"word"`
+	Syllables int    `prof:"syllables"`
+	CreatedAt int64  `prof:"created_at"` // unix seconds
+	Status    string `prof:"status"`
+}
+
+var SuggestionDAO SuggestionDaoImpl
+
+type SuggestionDaoImpl struct {
+	// Upsert records a suggestion, replacing any earlier suggestion by the same user for the same word.
+	Upsert func(ctx context.Context, e proteus.ContextExecutor, s Suggestion) (int64, error)            `proq:"q:suggestion_upsert" prop:"s"`
+	Find   func(ctx context.Context, e proteus.ContextQuerier, guildID int, id int) (Suggestion, error) `proq:"q:suggestion_find" prop:"guildID,id"`
+	// Pending lists the suggestions in a guild which haven't been reviewed, oldest first.
+	Pending func(ctx context.Context, e proteus.ContextQuerier, guildID int, limit int) ([]Suggestion, error) `proq:"q:suggestion_pending" prop:"guildID,limit"`
+	// Review sets the status of a pending suggestion, returning 0 if it has already been reviewed.
+	Review func(ctx context.Context, e proteus.ContextExecutor, guildID int, id int, status string) (int64, error) `proq:"q:suggestion_review" prop:"guildID,id,status"`
+}
+
+func init() {
+	m := proteus.MapMapper{
+		"suggestion_upsert": `INSERT INTO word_suggestion (guild_id, user_id, word, syllables, created_at, status)
+							  VALUES (:s.GuildID:, :s.UserID:, :s.Word:, :s.Syllables:, :s.CreatedAt:, 'pending')
+							  ON CONFLICT (guild_id, user_id, word)
+							  DO UPDATE SET syllables = excluded.syllables, created_at = excluded.created_at, status = 'pending'`,
+		"suggestion_find": `SELECT * FROM word_suggestion WHERE guild_id = :guildID: AND suggestion_id = :id:`,
+		"suggestion_pending": `SELECT * FROM word_suggestion WHERE guild_id = :guildID: AND status = 'pending'
+							   ORDER BY created_at ASC, suggestion_id ASC LIMIT :limit:`,
+		"suggestion_review": `UPDATE word_suggestion SET status = :status:
+							  WHERE guild_id = :guildID: AND suggestion_id = :id: AND status = 'pending'`,
+	}
+	register(&SuggestionDAO, m)
+}
@@ -0,0 +1,108 @@
+package haikuhammer
+
+import (
+	"context"
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/dict"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// maxWordSyllables is the most syllables an admin can add a word to the guild dictionary with.
+const maxWordSyllables = 20
+
+// guildWords returns the words added to the dictionary for the guild a message was sent to, along with the words
+// added for every guild, keyed by the word as cleaned by cleanWord. Words added to the guild take precedence.
+func (h *HaikuHammer) guildWords(m *Message) map[string]db.GuildWord {
+	gid, err := strconv.Atoi(m.GuildID)
+	if err != nil {
+		return nil
+	}
+	result := make(map[string]db.GuildWord)
+	for _, id := range []int{0, gid} {
+		words, err := h.store.GuildWords(context.Background(), id)
+		if err != nil {
+			log.Println("could not retrieve guild dictionary for guildID:", id, err)
+			continue
+		}
+		for _, word := range words {
+			result[word.Word] = word
+		}
+	}
+	return result
+}
+
+// parseGuildWord checks that a word can be added to the guild dictionary, returning it as it would be looked up.
+func parseGuildWord(word string) (string, error) {
+	cleaned := cleanWord(word)
+	if cleaned == "" || cleaned != dict.Fold(word) {
+		return "", fmt.Errorf("could not understand '%s' as a word; words may only contain letters and apostrophes", word)
+	}
+	return cleaned, nil
+}
+
+func parseWordSyllables(syllables string) (int, error) {
+	n, err := strconv.Atoi(syllables)
+	if err != nil || n < 1 || n > maxWordSyllables {
+		return 0, fmt.Errorf("could not understand '%s' as a number of syllables between 1 and %d", syllables, maxWordSyllables)
+	}
+	return n, nil
+}
+
+// addGuildWord adds a word to the guild dictionary, or changes its syllables if it has already been added.
+func (h *HaikuHammer) addGuildWord(guildID string, command Command) string {
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return "I couldn't update the dictionary, please try again later"
+	}
+	err = h.store.SaveGuildWord(context.Background(), db.GuildWord{GuildID: gid, Word: command.Word, Syllables: command.Syllables})
+	if err != nil {
+		log.Println("could not add word to guild dictionary,", err)
+		return "I couldn't update the dictionary, please try again later"
+	}
+	return fmt.Sprintf("I'll count %s as %s here", strings.ToLower(command.Word), pluralize(command.Syllables, "syllable"))
+}
+
+// removeGuildWord removes a word from the guild dictionary.
+func (h *HaikuHammer) removeGuildWord(guildID string, command Command) string {
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return "I couldn't update the dictionary, please try again later"
+	}
+	deleted, err := h.store.DeleteGuildWord(context.Background(), gid, command.Word)
+	if err != nil {
+		log.Println("could not remove word from guild dictionary,", err)
+		return "I couldn't update the dictionary, please try again later"
+	}
+	if !deleted {
+		return fmt.Sprintf("%s isn't in this server's dictionary", strings.ToLower(command.Word))
+	}
+	return fmt.Sprintf("Removed %s from this server's dictionary", strings.ToLower(command.Word))
+}
+
+// guildWordList lists every word in the guild dictionary.
+func (h *HaikuHammer) guildWordList(guildID string) string {
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return "I couldn't look up the dictionary, please try again later"
+	}
+	words, err := h.store.GuildWords(context.Background(), gid)
+	if err != nil {
+		log.Println("could not list guild dictionary,", err)
+		return "I couldn't look up the dictionary, please try again later"
+	}
+	if len(words) == 0 {
+		return "No words have been added to this server's dictionary yet"
+	}
+	var result strings.Builder
+	result.WriteString("Words in this server's dictionary:")
+	for _, word := range words {
+		result.WriteString(fmt.Sprintf("\n- %s - %s", strings.ToLower(word.Word), pluralize(word.Syllables, "syllable")))
+	}
+	return result.String()
+}
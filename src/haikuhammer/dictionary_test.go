@@ -0,0 +1,71 @@
+package haikuhammer
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const zxqvHaiku = "Rain taps the window\nThe old zxqv hums so softly\nThe tea has gone cold"
+
+func TestParseCommand_Dictionary(t *testing.T) {
+	command, err := parseCommand("dictionary add Don't 1")
+	assert.NoError(t, err)
+	assert.Equal(t, Command{Operation: OpDictionaryAdd, Word: "DON'T", Syllables: 1}, command)
+
+	for _, bad := range []string{"dictionary add zxqv", "dictionary add zxqv 0", "dictionary add zx-qv 1", "dictionary remove"} {
+		_, err = parseCommand(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestGuildDictionary(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	otherGID, otherCID := h.addGuild("1")
+	h.addMember(gid, "2", "member")
+	h.setGuildFlags(gid, db.ConfigReactToHaiku|db.ConfigExplainNonHaiku)
+	h.setGuildFlags(otherGID, db.ConfigReactToHaiku)
+
+	listID := h.post(gid, cid, "2", "!haiku dictionary list")
+	assert.Equal(t, []string{"No words have been added to this server's dictionary yet"}, h.replies(listID))
+
+	addID := h.post(gid, cid, "1", "!haiku dictionary add zxqv 1")
+	assert.Equal(t, []string{"I'll count zxqv as 1 syllable here"}, h.replies(addID))
+
+	zxqvID := h.post(gid, cid, "2", zxqvHaiku)
+	assert.Equal(t, []string{"💯"}, h.reactions(zxqvID))
+
+	otherID := h.post(otherGID, otherCID, "2", zxqvHaiku)
+	assert.Empty(t, h.reactions(otherID), "words are only added to the guild's dictionary")
+
+	h.post(gid, cid, "1", "!haiku dictionary add pond 2")
+	pondID := h.post(gid, cid, "2", "An old silent pond\nA frog jumps into the pond\nSplash! Silence again")
+	assert.Equal(t, []string{"Hmmm, this doesn't seem like a traditional English Haiku; here's why:\n" +
+		"- I used this server's dictionary for: pond (2)\n" +
+		"- I used this server's dictionary for: pond (2)\n" +
+		"- I counted a syllable structure of 6/8/5, but I expected 5/7/5"}, h.replies(pondID))
+
+	listID = h.post(gid, cid, "2", "!haiku dictionary list")
+	assert.Equal(t, []string{"Words in this server's dictionary:\n- pond - 2 syllables\n- zxqv - 1 syllable"}, h.replies(listID))
+
+	resp := h.command(gid, cid, "1", dictionaryGroup(subcommand("remove", stringOption("word", "pond"))))
+	assert.Equal(t, "Removed pond from this server's dictionary", resp.Data.Content)
+	resp = h.command(gid, cid, "1", dictionaryGroup(subcommand("remove", stringOption("word", "pond"))))
+	assert.Equal(t, "pond isn't in this server's dictionary", resp.Data.Content)
+
+	resp = h.command(gid, cid, "2", dictionaryGroup(subcommand("add", stringOption("word", "yeet"), intOption("syllables", 1))))
+	assert.Equal(t, "You do not have permissions to manage HaikuHammer in "+channelMention(cid), resp.Data.Content)
+	resp = h.command(gid, cid, "1", dictionaryGroup(subcommand("add", stringOption("word", "yeet"), intOption("syllables", 1))))
+	assert.Equal(t, "I'll count yeet as 1 syllable here", resp.Data.Content)
+}
+
+func dictionaryGroup(sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: "dictionary", Type: discordgo.ApplicationCommandOptionSubCommandGroup,
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{sub}}
+}
+
+func intOption(name string, value int) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionInteger, Value: float64(value)}
+}
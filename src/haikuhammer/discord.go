@@ -0,0 +1,388 @@
+package haikuhammer
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+)
+
+// adminCommandPerms is a bitmask for the min permissions required to send admin commands. If any flag is set, the
+// user can send HaikuHammer admin commands.
+const adminCommandPerms = discordgo.PermissionAdministrator | discordgo.PermissionManageChannels | discordgo.PermissionManageServer
+
+// discordSession is the subset of the discordgo REST API used by DiscordPlatform.
+type discordSession interface {
+	ChannelMessage(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendReply(channelID string, content string, reference *discordgo.MessageReference, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error
+	MessageReactionAdd(channelID, messageID, emojiID string, options ...discordgo.RequestOption) error
+	MessageReactionRemove(channelID, messageID, emojiID, userID string, options ...discordgo.RequestOption) error
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error)
+	GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error)
+	GuildRoles(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Role, error)
+	User(userID string, options ...discordgo.RequestOption) (*discordgo.User, error)
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+}
+
+// DiscordPlatform is the ChatPlatform implementation backed by a Discord gateway session.
+type DiscordPlatform struct {
+	gateway *discordgo.Session
+	session discordSession
+	state   *discordgo.State // cache kept up to date by the gateway, consulted before the REST API; nil if there's none
+	hammer  *HaikuHammer
+
+	debug bool
+	botID string
+
+	dmCache        map[string]bool   // maps from channelIDs to whether they're DM channels or not
+	dmChannelCache map[string]string // maps from userIDs to their DM channel ID
+}
+
+// NewDiscordPlatform creates a Discord session using the provided config which forwards messages to hammer.
+func NewDiscordPlatform(config Config, hammer *HaikuHammer) (*DiscordPlatform, error) {
+	session, err := discordgo.New("Bot " + config.Token)
+	if err != nil {
+		return nil, err
+	}
+	if config.Debug {
+		session.LogLevel = discordgo.LogDebug
+	}
+	session.StateEnabled = true
+
+	d := newDiscordPlatform(session, hammer, config.Debug)
+	d.gateway = session
+	d.state = session.State
+
+	session.AddHandler(d.receiveMessageCreate)
+	session.AddHandler(d.receiveMessageEdit)
+	session.AddHandler(d.receiveInteractionCreate)
+	session.AddHandler(d.receiveReactionAdd)
+	session.AddHandler(d.receiveReactionRemove)
+
+	session.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages |
+		discordgo.IntentsGuildMessageReactions | discordgo.IntentsDirectMessageReactions // reactions are counted as votes
+	return d, nil
+}
+
+func newDiscordPlatform(session discordSession, hammer *HaikuHammer, debug bool) *DiscordPlatform {
+	return &DiscordPlatform{
+		session:        session,
+		hammer:         hammer,
+		debug:          debug,
+		dmCache:        make(map[string]bool),
+		dmChannelCache: make(map[string]string),
+	}
+}
+
+// Open connects to the Discord gateway and looks up the bot's own user.
+func (d *DiscordPlatform) Open() error {
+	err := d.gateway.Open()
+	if err != nil {
+		log.Println("error opening connection,", err)
+		return err
+	}
+
+	user, err := d.gateway.User("@me")
+	if err != nil {
+		log.Println("error looking up bot user", err)
+		return err
+	}
+	d.botID = user.ID
+	log.Println("Bot running as username: ", user.Username+"#"+user.Discriminator)
+
+	err = d.registerCommands()
+	if err != nil {
+		log.Println("error registering slash commands,", err)
+		return err
+	}
+	return nil
+}
+
+func (d *DiscordPlatform) Close() error {
+	return d.gateway.Close()
+}
+
+func (d *DiscordPlatform) receiveMessageEdit(s *discordgo.Session, m *discordgo.MessageUpdate) {
+	d.hammer.HandleMessage(d.toMessage(m.Message))
+}
+
+func (d *DiscordPlatform) receiveMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
+	d.hammer.ReceiveMessage(d.toMessage(m.Message))
+}
+
+func (d *DiscordPlatform) receiveReactionAdd(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	d.hammer.HandleReactionAdd(d.toReaction(r.MessageReaction, r.Member))
+}
+
+func (d *DiscordPlatform) receiveReactionRemove(s *discordgo.Session, r *discordgo.MessageReactionRemove) {
+	d.hammer.HandleReactionRemove(d.toReaction(r.MessageReaction, nil))
+}
+
+func (d *DiscordPlatform) FetchMessage(channelID, messageID string) (*Message, error) {
+	m, err := d.session.ChannelMessage(channelID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	return d.toMessage(m), nil
+}
+
+func (d *DiscordPlatform) React(channelID, messageID, emoji string) error {
+	return d.session.MessageReactionAdd(channelID, messageID, emoji)
+}
+
+func (d *DiscordPlatform) Unreact(channelID, messageID, emoji string) error {
+	return d.session.MessageReactionRemove(channelID, messageID, emoji, d.botID)
+}
+
+func (d *DiscordPlatform) Delete(channelID, messageID string) error {
+	return d.session.ChannelMessageDelete(channelID, messageID)
+}
+
+func (d *DiscordPlatform) Reply(channelID, messageID, content string) error {
+	_, err := d.session.ChannelMessageSendReply(channelID, content, &discordgo.MessageReference{
+		MessageID: messageID,
+		ChannelID: channelID,
+	})
+	return err
+}
+
+func (d *DiscordPlatform) Send(channelID, content string) error {
+	_, err := d.session.ChannelMessageSend(channelID, content)
+	return err
+}
+
+func (d *DiscordPlatform) DM(userID, content string) error {
+	dmChannelID, err := d.getDMChannelID(userID)
+	if err != nil {
+		return err
+	}
+	_, err = d.session.ChannelMessageSend(dmChannelID, content)
+	return err
+}
+
+func (d *DiscordPlatform) MemberNick(guildID, userID string) (string, error) {
+	member, err := d.member(guildID, userID)
+	if err != nil {
+		return "", err
+	}
+	return memberNick(member), nil
+}
+
+func (d *DiscordPlatform) IsAdmin(guildID, userID string) (bool, error) {
+	perms, err := d.Permissions(guildID, userID)
+	if err != nil {
+		return false, err
+	}
+	if perms&adminCommandPerms == 0 {
+		if d.debug {
+			log.Printf("could not verify admin permissions, found perms %d, expected %d", perms, adminCommandPerms)
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// Permissions computes the guild-level permissions held by the provided user.
+func (d *DiscordPlatform) Permissions(guildID, userID string) (int64, error) {
+	g, err := d.session.Guild(guildID)
+	if err != nil {
+		return 0, err
+	}
+	if g.OwnerID == userID {
+		return discordgo.PermissionAll, nil
+	}
+	member, err := d.session.GuildMember(guildID, userID)
+	if err != nil {
+		return 0, err
+	}
+	roles, err := d.session.GuildRoles(guildID)
+	if err != nil {
+		return 0, err
+	}
+	roleMap := make(map[string]int64) // member roles are listed by ID, and @everyone shares its ID with the guild.
+	for _, role := range roles {
+		roleMap[role.ID] = role.Permissions
+	}
+	permissions := roleMap[guildID]
+	for _, role := range member.Roles {
+		permissions |= roleMap[role]
+	}
+	if permissions&discordgo.PermissionAdministrator == discordgo.PermissionAdministrator {
+		return discordgo.PermissionAll, nil
+	}
+	return permissions, nil
+}
+
+func (d *DiscordPlatform) IsDM(channelID string) (bool, error) {
+	if result, ok := d.dmCache[channelID]; ok {
+		return result, nil
+	}
+	c, err := d.session.Channel(channelID)
+	if err != nil {
+		return false, err
+	}
+	log.Println("looked up channel", channelID)
+	result := c.Type == discordgo.ChannelTypeDM && len(c.Recipients) == 1
+	d.dmCache[channelID] = result
+	return result, nil
+}
+
+func (d *DiscordPlatform) getDMChannelID(authorID string) (string, error) {
+	if c, ok := d.dmChannelCache[authorID]; ok {
+		return c, nil
+	}
+	c, err := d.session.UserChannelCreate(authorID)
+	if err != nil {
+		return "", err
+	}
+	log.Println("retrieved new DM channel for user", authorID)
+	d.dmCache[c.ID] = true
+	d.dmChannelCache[authorID] = c.ID
+	return c.ID, nil
+}
+
+// toMessage converts a discordgo message into a platform-agnostic Message.
+func (d *DiscordPlatform) toMessage(m *discordgo.Message) *Message {
+	if m == nil {
+		return nil
+	}
+	result := &Message{
+		ID:        m.ID,
+		ChannelID: m.ChannelID,
+		GuildID:   m.GuildID,
+		Content:   m.Content,
+		Timestamp: m.Timestamp,
+	}
+	if m.Author != nil {
+		result.AuthorID = m.Author.ID
+		result.AuthorBot = m.Author.Bot
+	}
+	for _, u := range m.Mentions {
+		if u.ID == d.botID {
+			result.MentionsBot = true
+		}
+	}
+	for _, reaction := range m.Reactions {
+		if reaction.Me {
+			result.MyReaction = reaction.Emoji.Name
+			break
+		}
+	}
+	return result
+}
+
+// MentionNames looks up the name each user, role and channel mentioned in content is displayed as, keyed by the
+// markup which mentions it. Names are read from the gateway's cache where possible, so the REST API is only used for
+// mentions which haven't been seen yet. Mentions which can't be looked up are left out.
+func (d *DiscordPlatform) MentionNames(guildID, content string) map[string]string {
+	result := make(map[string]string)
+	for _, match := range mentionRegex.FindAllStringSubmatch(content, -1) {
+		kind, id := strings.TrimSuffix(match[1], "!"), match[2]
+		key := "<" + kind + id + ">"
+		if _, ok := result[key]; ok {
+			continue
+		}
+		name, err := d.mentionName(guildID, kind, id)
+		if err != nil {
+			log.Println("could not look up mention", key+",", err)
+			continue
+		}
+		result[key] = name
+	}
+	return result
+}
+
+func (d *DiscordPlatform) mentionName(guildID, kind, id string) (string, error) {
+	switch kind {
+	case "#":
+		c, err := d.channel(id)
+		if err != nil {
+			return "", err
+		}
+		return c.Name, nil
+	case "@&":
+		role, err := d.role(guildID, id)
+		if err != nil {
+			return "", err
+		}
+		return role.Name, nil
+	}
+	if guildID == "" {
+		u, err := d.session.User(id)
+		if err != nil {
+			return "", err
+		}
+		return u.Username, nil
+	}
+	member, err := d.member(guildID, id)
+	if err != nil {
+		return "", err
+	}
+	return memberNick(member), nil
+}
+
+// member looks up a guild member in the gateway's cache, falling back to the REST API.
+func (d *DiscordPlatform) member(guildID, userID string) (*discordgo.Member, error) {
+	if d.state != nil {
+		if member, err := d.state.Member(guildID, userID); err == nil {
+			return member, nil
+		}
+	}
+	return d.session.GuildMember(guildID, userID)
+}
+
+// role looks up a guild role in the gateway's cache, falling back to the REST API.
+func (d *DiscordPlatform) role(guildID, roleID string) (*discordgo.Role, error) {
+	if d.state != nil {
+		if role, err := d.state.Role(guildID, roleID); err == nil {
+			return role, nil
+		}
+	}
+	roles, err := d.session.GuildRoles(guildID)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if role.ID == roleID {
+			return role, nil
+		}
+	}
+	return nil, discordgo.ErrStateNotFound
+}
+
+// channel looks up a channel in the gateway's cache, falling back to the REST API.
+func (d *DiscordPlatform) channel(channelID string) (*discordgo.Channel, error) {
+	if d.state != nil {
+		if c, err := d.state.Channel(channelID); err == nil {
+			return c, nil
+		}
+	}
+	return d.session.Channel(channelID)
+}
+
+// toReaction converts a discordgo reaction into a platform-agnostic Reaction. The member who reacted is only known
+// when reactions are added in a guild.
+func (d *DiscordPlatform) toReaction(r *discordgo.MessageReaction, member *discordgo.Member) *Reaction {
+	result := &Reaction{
+		MessageID: r.MessageID,
+		ChannelID: r.ChannelID,
+		GuildID:   r.GuildID,
+		UserID:    r.UserID,
+		UserBot:   r.UserID == d.botID,
+		Emoji:     r.Emoji.Name,
+	}
+	if member != nil && member.User != nil && member.User.Bot {
+		result.UserBot = true
+	}
+	return result
+}
+
+func memberNick(m *discordgo.Member) string {
+	if m.Nick != "" {
+		return m.Nick
+	}
+	return m.User.Username
+}
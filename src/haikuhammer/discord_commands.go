@@ -0,0 +1,723 @@
+package haikuhammer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// slashCommandName is the name of the top-level application command all admin commands are registered under.
+const slashCommandName = "haiku"
+
+var allowedInDMs = false
+
+// featureCommandOptions are the options shared by the `/haiku feature on` and `/haiku feature off` commands.
+var featureCommandOptions = []*discordgo.ApplicationCommandOption{
+	{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         "features",
+		Description:  "Comma-separated list of features",
+		Required:     true,
+		Autocomplete: true,
+	},
+	targetCommandOption,
+}
+
+var targetCommandOption = &discordgo.ApplicationCommandOption{
+	Type:         discordgo.ApplicationCommandOptionChannel,
+	Name:         "channel",
+	Description:  "Channel to configure; leave empty to configure every channel in the guild",
+	ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+}
+
+// formCommandOptions are the options shared by the `/haiku form on` and `/haiku form off` commands.
+var formCommandOptions = []*discordgo.ApplicationCommandOption{
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "forms",
+		Description: "Comma-separated list of forms, e.g. haiku, tanka, cinquain or a pattern like 5/7/5",
+		Required:    true,
+	},
+	targetCommandOption,
+}
+
+var wordCommandOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionString,
+	Name:        "word",
+	Description: "Word to add or remove",
+	Required:    true,
+}
+
+// minPositive is the MinValue of integer options which must be positive.
+var minPositive = 1.0
+
+// wordCommandOptions are the options shared by the `/haiku teach` and `/haiku dictionary add` commands.
+var wordCommandOptions = []*discordgo.ApplicationCommandOption{
+	wordCommandOption,
+	{
+		Type:        discordgo.ApplicationCommandOptionInteger,
+		Name:        "syllables",
+		Description: "Number of syllables in the word",
+		Required:    true,
+		MinValue:    &minPositive,
+		MaxValue:    maxWordSyllables,
+	},
+}
+
+var suggestionCommandOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionInteger,
+	Name:        "number",
+	Description: "Number of the suggestion, from `/haiku suggestions list`",
+	Required:    true,
+	MinValue:    &minPositive,
+}
+
+func languageChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var result []*discordgo.ApplicationCommandOptionChoice
+	for _, language := range Languages {
+		result = append(result, &discordgo.ApplicationCommandOptionChoice{Name: language.Name(), Value: language.Name()})
+	}
+	return result
+}
+
+func helpTopicChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var result []*discordgo.ApplicationCommandOptionChoice
+	for _, topic := range helpTopics {
+		result = append(result, &discordgo.ApplicationCommandOptionChoice{Name: topic.Name, Value: topic.Name})
+	}
+	return result
+}
+
+// SlashCommands are the application commands HaikuHammer registers with Discord.
+var SlashCommands = []*discordgo.ApplicationCommand{
+	{
+		Name:         slashCommandName,
+		Description:  "Manage HaikuHammer",
+		DMPermission: &allowedInDMs,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "feature",
+				Description: "Enable, disable or list HaikuHammer features",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "on",
+						Description: "Enable features",
+						Options:     featureCommandOptions,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "off",
+						Description: "Disable features",
+						Options:     featureCommandOptions,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "List enabled features",
+						Options:     []*discordgo.ApplicationCommandOption{targetCommandOption},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "form",
+				Description: "Choose which poetic forms are accepted",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "on",
+						Description: "Accept forms",
+						Options:     formCommandOptions,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "off",
+						Description: "Stop accepting forms",
+						Options:     formCommandOptions,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "List accepted forms",
+						Options:     []*discordgo.ApplicationCommandOption{targetCommandOption},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "enforce",
+				Description: "Choose which poetic forms DeleteNonHaiku keeps",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "on",
+						Description: "Enforce forms",
+						Options:     formCommandOptions,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "off",
+						Description: "Stop enforcing forms",
+						Options:     formCommandOptions,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "List enforced forms",
+						Options:     []*discordgo.ApplicationCommandOption{targetCommandOption},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "awards",
+				Description: "Haiku of the Week, Month and Year",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "channel",
+						Description: "Announce awards in a channel",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:         discordgo.ApplicationCommandOptionChannel,
+								Name:         "channel",
+								Description:  "Channel to announce awards in; leave empty to stop announcing awards",
+								ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "List past winners",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "period",
+								Description: "Which award to list",
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{Name: "week", Value: string(AwardWeek)},
+									{Name: "month", Value: string(AwardMonth)},
+									{Name: "year", Value: string(AwardYear)},
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "attempts",
+				Description: "Review messages which weren't haiku",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "List recent three-line messages which weren't haiku, and why",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "words",
+						Description: "List the unknown words which most often stopped a haiku",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "dictionary",
+				Description: "Teach HaikuHammer words used in this server",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "add",
+						Description: "Add a word, or change how many syllables it has",
+						Options:     wordCommandOptions,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "remove",
+						Description: "Remove a word",
+						Options:     []*discordgo.ApplicationCommandOption{wordCommandOption},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "List the words added to this server's dictionary",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "teach",
+				Description: "Suggest how many syllables a word has",
+				Options:     wordCommandOptions,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "suggestions",
+				Description: "Review words suggested with `/haiku teach`",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "List suggestions waiting for review",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "approve",
+						Description: "Add a suggested word to the dictionary",
+						Options: []*discordgo.ApplicationCommandOption{
+							suggestionCommandOption,
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "global",
+								Description: "Add the word for every server; only global moderators may do this",
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "reject",
+						Description: "Reject a suggested word",
+						Options:     []*discordgo.ApplicationCommandOption{suggestionCommandOption},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "estimates",
+				Description: "Choose what happens to words whose syllables I can only guess",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "mode",
+						Description: "What to do with low-confidence guesses; leave empty to show the current mode",
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "accept", Value: string(EstimateAccept)},
+							{Name: "flag", Value: string(EstimateFlag)},
+							{Name: "reject", Value: string(EstimateReject)},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "language",
+				Description: "Choose the language messages are written in",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "language",
+						Description: "Language to read messages in; leave empty to show the current language",
+						Choices:     languageChoices(),
+					},
+					targetCommandOption,
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "stats",
+				Description: "Show the most prolific authors, or stats for a single user",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "user",
+						Description: "User to show stats for; leave empty to show the top authors",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "help",
+				Description: "Explain how to configure HaikuHammer",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "topic",
+						Description: "Which commands to explain; leave empty to explain features",
+						Choices:     helpTopicChoices(),
+					},
+				},
+			},
+		},
+	},
+}
+
+// registerCommands overwrites the bot's global application commands with SlashCommands.
+func (d *DiscordPlatform) registerCommands() error {
+	_, err := d.gateway.ApplicationCommandBulkOverwrite(d.botID, "", SlashCommands)
+	return err
+}
+
+func (d *DiscordPlatform) receiveInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand && i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		return
+	}
+	data := i.ApplicationCommandData()
+	if data.Name != slashCommandName {
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		d.autocomplete(i.Interaction, data)
+		return
+	}
+	if i.GuildID == "" || i.Member == nil || i.Member.User == nil {
+		d.respond(i.Interaction, "All commands must be sent in the guild they are meant to apply to.")
+		return
+	}
+
+	command, err := parseInteraction(data)
+	if err != nil {
+		d.respond(i.Interaction, err.Error())
+		return
+	}
+	command.SenderID = i.Member.User.ID
+	if command.RequiresAdmin() {
+		isAdmin, err := d.IsAdmin(i.GuildID, i.Member.User.ID)
+		if err != nil {
+			log.Println("could not retrieve permissions for user, ignoring admin command,", err)
+			d.respond(i.Interaction, "I couldn't check your permissions, please try again later")
+			return
+		}
+		if !isAdmin {
+			d.respond(i.Interaction, fmt.Sprintf("You do not have permissions to manage HaikuHammer in <#%s>", i.ChannelID))
+			return
+		}
+	}
+	d.respond(i.Interaction, d.hammer.RunCommand(i.GuildID, command))
+}
+
+// respond replies to an interaction with a message only the user who sent it can see.
+func (d *DiscordPlatform) respond(i *discordgo.Interaction, content string) {
+	err := d.session.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Println("could not respond to interaction,", err)
+	}
+}
+
+// autocomplete suggests feature names for the last entry in a partially typed list of features.
+func (d *DiscordPlatform) autocomplete(i *discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) {
+	var typed string
+	if opt := focusedOption(data.Options); opt != nil && opt.Type == discordgo.ApplicationCommandOptionString {
+		typed = opt.StringValue()
+	}
+	err := d.session.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: featureChoices(typed),
+		},
+	})
+	if err != nil {
+		log.Println("could not respond to autocomplete interaction,", err)
+	}
+}
+
+// maxChoiceLength is the longest name or value Discord accepts for an autocomplete choice.
+const maxChoiceLength = 100
+
+// featureChoices completes the last feature in a comma-separated list, leaving the features before it as-is. Features
+// already in the list aren't suggested again, and lists too long for Discord to accept are left out.
+func featureChoices(typed string) []*discordgo.ApplicationCommandOptionChoice {
+	var chosen []string
+	prefix, partial := "", strings.TrimSpace(typed)
+	if idx := strings.LastIndex(typed, ","); idx >= 0 {
+		prefix, partial = typed[:idx+1]+" ", strings.TrimSpace(typed[idx+1:])
+		for _, feature := range strings.Split(typed[:idx], ",") {
+			chosen = append(chosen, strings.TrimSpace(feature))
+		}
+	}
+	var result []*discordgo.ApplicationCommandOptionChoice
+	for _, name := range FeatureNames {
+		if !strings.HasPrefix(strings.ToLower(name), strings.ToLower(partial)) || containsFold(chosen, name) {
+			continue
+		}
+		value := strings.TrimSpace(prefix + name)
+		if utf8.RuneCountInString(value) > maxChoiceLength {
+			continue
+		}
+		result = append(result, &discordgo.ApplicationCommandOptionChoice{Name: value, Value: value})
+	}
+	return result
+}
+
+// containsFold returns true if strs contains s, ignoring case.
+func containsFold(strs []string, s string) bool {
+	for _, str := range strs {
+		if strings.EqualFold(str, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func focusedOption(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, opt := range options {
+		if opt.Focused {
+			return opt
+		}
+		if found := focusedOption(opt.Options); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// parseInteraction converts the options sent with a `/haiku` command into a Command.
+func parseInteraction(data discordgo.ApplicationCommandInteractionData) (Command, error) {
+	if len(data.Options) == 0 {
+		return Command{}, errors.New("expected a subcommand after `/haiku`")
+	}
+	sub := data.Options[0]
+	switch sub.Name {
+	case "help":
+		result := Command{Operation: OpHelp}
+		for _, opt := range sub.Options {
+			if opt.Name == "topic" {
+				topic, err := findHelpTopic(opt.Value.(string))
+				if err != nil {
+					return Command{}, err
+				}
+				result.HelpTopic = topic.Name
+			}
+		}
+		return result, nil
+	case "stats":
+		result := Command{Operation: OpStats}
+		for _, opt := range sub.Options {
+			if opt.Name == "user" {
+				result.UserID = opt.Value.(string)
+			}
+		}
+		return result, nil
+	case "estimates":
+		result := Command{Operation: OpEstimates}
+		for _, opt := range sub.Options {
+			if opt.Name == "mode" {
+				var err error
+				result.Estimates, err = parseEstimateMode(opt.StringValue())
+				if err != nil {
+					return Command{}, err
+				}
+			}
+		}
+		return result, nil
+	case "language":
+		result := Command{Operation: OpLanguage, Target: "global"}
+		for _, opt := range sub.Options {
+			switch opt.Name {
+			case "channel":
+				result.Target = opt.Value.(string)
+			case "language":
+				var err error
+				result.Language, err = parseLanguage(opt.StringValue())
+				if err != nil {
+					return Command{}, err
+				}
+			}
+		}
+		return result, nil
+	case "feature":
+		if len(sub.Options) == 0 {
+			return Command{}, errors.New("expected one of `on`, `off` or `list` after `/haiku feature`")
+		}
+		sub = sub.Options[0]
+	case "form":
+		if len(sub.Options) == 0 {
+			return Command{}, errors.New("expected one of `on`, `off` or `list` after `/haiku form`")
+		}
+		return parseFormInteraction(sub.Options[0], OpFormOn, OpFormOff, OpFormList)
+	case "enforce":
+		if len(sub.Options) == 0 {
+			return Command{}, errors.New("expected one of `on`, `off` or `list` after `/haiku enforce`")
+		}
+		return parseFormInteraction(sub.Options[0], OpEnforceOn, OpEnforceOff, OpEnforceList)
+	case "teach":
+		return parseWordOptions(Command{Operation: OpTeach}, sub.Options)
+	case "suggestions":
+		if len(sub.Options) == 0 {
+			return Command{}, errors.New("expected one of `list`, `approve` or `reject` after `/haiku suggestions`")
+		}
+		return parseSuggestionsInteraction(sub.Options[0])
+	case "dictionary":
+		if len(sub.Options) == 0 {
+			return Command{}, errors.New("expected one of `add`, `remove` or `list` after `/haiku dictionary`")
+		}
+		return parseDictionaryInteraction(sub.Options[0])
+	case "awards":
+		if len(sub.Options) == 0 {
+			return Command{}, errors.New("expected one of `channel` or `list` after `/haiku awards`")
+		}
+		return parseAwardsInteraction(sub.Options[0])
+	case "attempts":
+		if len(sub.Options) == 0 {
+			return Command{}, errors.New("expected one of `list` or `words` after `/haiku attempts`")
+		}
+		switch sub.Options[0].Name {
+		case "list":
+			return Command{Operation: OpAttemptsList}, nil
+		case "words":
+			return Command{Operation: OpAttemptsWords}, nil
+		default:
+			return Command{}, fmt.Errorf("could not understand command attempts %s", sub.Options[0].Name)
+		}
+	default:
+		return Command{}, fmt.Errorf("could not understand command %s", sub.Name)
+	}
+
+	result := Command{Target: "global"}
+	switch sub.Name {
+	case "on":
+		result.Operation = OpFeatureOn
+	case "off":
+		result.Operation = OpFeatureOff
+	case "list":
+		result.Operation = OpFeatureList
+	default:
+		return Command{}, fmt.Errorf("could not understand command feature %s", sub.Name)
+	}
+	for _, opt := range sub.Options {
+		switch opt.Name {
+		case "channel":
+			result.Target = opt.Value.(string)
+		case "features":
+			var err error
+			result.Features, err = parseFeatures(strings.FieldsFunc(opt.StringValue(), func(r rune) bool {
+				return r == ',' || r == ' '
+			}))
+			if err != nil {
+				return Command{}, err
+			}
+		}
+	}
+	if result.Operation != OpFeatureList && result.Features == 0 {
+		return Command{}, errors.New("expected at least one feature")
+	}
+	return result, nil
+}
+
+// parseFormInteraction parses the `/haiku form` and `/haiku enforce` subcommands, which share their options.
+func parseFormInteraction(sub *discordgo.ApplicationCommandInteractionDataOption, on, off, list Operation) (Command, error) {
+	result := Command{Target: "global"}
+	switch sub.Name {
+	case "on":
+		result.Operation = on
+	case "off":
+		result.Operation = off
+	case "list":
+		result.Operation = list
+	default:
+		return Command{}, fmt.Errorf("could not understand command %s", sub.Name)
+	}
+	for _, opt := range sub.Options {
+		switch opt.Name {
+		case "channel":
+			result.Target = opt.Value.(string)
+		case "forms":
+			var err error
+			result.Forms, err = parseForms(strings.FieldsFunc(opt.StringValue(), func(r rune) bool {
+				return r == ',' || r == ' '
+			}))
+			if err != nil {
+				return Command{}, err
+			}
+		}
+	}
+	if result.Operation != list && len(result.Forms) == 0 {
+		return Command{}, errors.New("expected at least one form")
+	}
+	return result, nil
+}
+
+func parseDictionaryInteraction(sub *discordgo.ApplicationCommandInteractionDataOption) (Command, error) {
+	var result Command
+	switch sub.Name {
+	case "add":
+		result.Operation = OpDictionaryAdd
+	case "remove":
+		result.Operation = OpDictionaryRemove
+	case "list":
+		return Command{Operation: OpDictionaryList}, nil
+	default:
+		return Command{}, fmt.Errorf("could not understand command dictionary %s", sub.Name)
+	}
+	return parseWordOptions(result, sub.Options)
+}
+
+// parseWordOptions reads the word and syllables options shared by `/haiku teach` and `/haiku dictionary`.
+func parseWordOptions(result Command, options []*discordgo.ApplicationCommandInteractionDataOption) (Command, error) {
+	var err error
+	for _, opt := range options {
+		switch opt.Name {
+		case "word":
+			result.Word, err = parseGuildWord(opt.StringValue())
+		case "syllables":
+			result.Syllables, err = parseWordSyllables(strconv.FormatInt(opt.IntValue(), 10))
+		}
+		if err != nil {
+			return Command{}, err
+		}
+	}
+	return result, nil
+}
+
+func parseSuggestionsInteraction(sub *discordgo.ApplicationCommandInteractionDataOption) (Command, error) {
+	var result Command
+	switch sub.Name {
+	case "list":
+		return Command{Operation: OpSuggestionsList}, nil
+	case "approve":
+		result.Operation = OpSuggestionsApprove
+	case "reject":
+		result.Operation = OpSuggestionsReject
+	default:
+		return Command{}, fmt.Errorf("could not understand command suggestions %s", sub.Name)
+	}
+	for _, opt := range sub.Options {
+		switch opt.Name {
+		case "number":
+			result.SuggestionID = int(opt.IntValue())
+		case "global":
+			result.Global = opt.BoolValue()
+		}
+	}
+	return result, nil
+}
+
+func parseAwardsInteraction(sub *discordgo.ApplicationCommandInteractionDataOption) (Command, error) {
+	switch sub.Name {
+	case "channel":
+		result := Command{Operation: OpAwardsChannel}
+		for _, opt := range sub.Options {
+			if opt.Name == "channel" {
+				result.Target = opt.Value.(string)
+			}
+		}
+		return result, nil
+	case "list":
+		result := Command{Operation: OpAwardsList, Period: AwardWeek}
+		for _, opt := range sub.Options {
+			if opt.Name == "period" {
+				var err error
+				result.Period, err = ParseAwardPeriod(opt.StringValue())
+				if err != nil {
+					return Command{}, err
+				}
+			}
+		}
+		return result, nil
+	default:
+		return Command{}, fmt.Errorf("could not understand command awards %s", sub.Name)
+	}
+}
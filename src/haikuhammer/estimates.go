@@ -0,0 +1,114 @@
+package haikuhammer
+
+import (
+	"context"
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/dict"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// EstimateMode decides what happens to words which aren't in the dictionary when their syllables can only be
+// estimated with low confidence. Estimates made with high confidence are always counted.
+type EstimateMode string
+
+const (
+	EstimateAccept EstimateMode = "accept" // count low-confidence estimates
+	EstimateFlag   EstimateMode = "flag"   // count low-confidence estimates, and point them out when replying to a haiku
+	EstimateReject EstimateMode = "reject" // treat words with low-confidence estimates as unknown
+)
+
+// EstimateModes lists every valid EstimateMode.
+var EstimateModes = []EstimateMode{EstimateAccept, EstimateFlag, EstimateReject}
+
+// DefaultEstimateMode is used in guilds which haven't chosen an EstimateMode.
+const DefaultEstimateMode = EstimateReject
+
+func parseEstimateMode(s string) (EstimateMode, error) {
+	for _, mode := range EstimateModes {
+		if strings.EqualFold(s, string(mode)) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("could not understand '%s' as a valid estimate mode; expected one of accept, flag or reject", s)
+}
+
+// estimate guesses the syllables in a word which couldn't otherwise be counted, returning false if the guess shouldn't
+// be trusted under this checker's EstimateMode. Checkers without an EstimateMode never estimate.
+func (c checker) estimate(word *Word) bool {
+	if c.estimates == "" {
+		return false
+	}
+	count, confidence := c.lang().Estimate(cleanWord(word.Text))
+	if count == 0 || confidence < dict.LowConfidence && c.estimates == EstimateReject {
+		return false
+	}
+	word.Syllables, word.Source, word.Confidence = []int{count}, SourceEstimate, confidence
+	return true
+}
+
+// FlaggedWords returns every word whose syllables were estimated with low confidence, in order.
+func (a Analysis) FlaggedWords() []Word {
+	var result []Word
+	for _, line := range a.Lines {
+		for _, word := range line.Words {
+			if word.Source == SourceEstimate && word.Confidence < dict.LowConfidence {
+				result = append(result, word)
+			}
+		}
+	}
+	return result
+}
+
+// estimateMode returns the EstimateMode chosen for the guild a message was sent to.
+func (h *HaikuHammer) estimateMode(m *Message) EstimateMode {
+	gid, err := strconv.Atoi(m.GuildID)
+	if err != nil {
+		return DefaultEstimateMode
+	}
+	return h.lookupEstimateMode(gid)
+}
+
+func (h *HaikuHammer) lookupEstimateMode(guildID int) EstimateMode {
+	setting, err := h.store.Setting(context.Background(), db.ScopeGuild, guildID, db.SettingEstimates)
+	if err != nil {
+		log.Println("could not retrieve estimate mode for guildID:", guildID, err)
+		return DefaultEstimateMode
+	}
+	mode, err := parseEstimateMode(setting.Value)
+	if err != nil {
+		return DefaultEstimateMode
+	}
+	return mode
+}
+
+// updateEstimateMode sets the EstimateMode for a guild, or reports the current mode if the command doesn't set one.
+func (h *HaikuHammer) updateEstimateMode(guildID string, command Command) string {
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return "I couldn't update how I treat guessed words, please try again later"
+	}
+	if command.Estimates == "" {
+		return fmt.Sprintf("Words I have to guess with low confidence are: %s", h.lookupEstimateMode(gid).describe())
+	}
+	err = h.store.SaveSetting(context.Background(), db.Setting{Scope: db.ScopeGuild, TargetID: gid, Name: db.SettingEstimates, Value: string(command.Estimates)})
+	if err != nil {
+		log.Println("could not update estimate mode,", err)
+		return "I couldn't update how I treat guessed words, please try again later"
+	}
+	return fmt.Sprintf("Words I have to guess with low confidence will be: %s", command.Estimates.describe())
+}
+
+func (mode EstimateMode) describe() string {
+	switch mode {
+	case EstimateAccept:
+		return "accepted"
+	case EstimateFlag:
+		return "accepted, and pointed out when I react to a haiku"
+	default:
+		return "rejected"
+	}
+}
@@ -0,0 +1,72 @@
+package haikuhammer
+
+import (
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const (
+	blorpHaiku = "Rain taps the window\nThe kettle sings of the blorp\nThe tea has gone cold"
+	asdfHaiku  = "Rain taps the window\nThe kettle sings of the asdf\nThe tea has gone cold"
+)
+
+func TestAnalyse_Estimates(t *testing.T) {
+	assert.Error(t, analyse(blorpHaiku, FormHaiku).Err(), "words are only estimated when the checker has a mode")
+
+	c := checker{forms: []Form{FormHaiku}, estimates: EstimateReject}
+	a := c.analyse(blorpHaiku)
+	assert.NoError(t, a.Err())
+	blorp := a.Lines[1].Words[5]
+	assert.Equal(t, SourceEstimate, blorp.Source)
+	assert.Equal(t, []int{1}, blorp.Syllables)
+	assert.Empty(t, a.FlaggedWords(), "confident estimates aren't flagged")
+
+	a = c.analyse(asdfHaiku)
+	assert.EqualError(t, a.Err(), "Hmmm, this doesn't seem like a traditional English Haiku; here's why:\n"+
+		"- I don't know the words: asdf\n"+
+		"- I counted a syllable structure of 5/0/5, but I expected 5/7/5")
+
+	c.estimates = EstimateFlag
+	a = c.analyse(asdfHaiku)
+	assert.NoError(t, a.Err())
+	assert.Equal(t, "asdf (1)", describeWords(a.FlaggedWords()))
+
+	a = c.analyse("Rain taps the window\nThe kettle sings of the blorp blorp\nThe tea has gone cold")
+	assert.EqualError(t, a.Err(), "Hmmm, this doesn't seem like a traditional English Haiku; here's why:\n"+
+		"- I had to guess the syllables in: blorp (1), blorp (1)\n"+
+		"- I counted a syllable structure of 5/8/5, but I expected 5/7/5")
+}
+
+func TestEstimates(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.setGuildFlags(gid, db.ConfigReactToHaiku)
+
+	blorpID := h.post(gid, cid, "2", blorpHaiku)
+	assert.Equal(t, []string{"💯"}, h.reactions(blorpID))
+	assert.Empty(t, h.replies(blorpID))
+
+	asdfID := h.post(gid, cid, "2", asdfHaiku)
+	assert.Empty(t, h.reactions(asdfID), "low-confidence estimates are rejected by default")
+
+	modeID := h.post(gid, cid, "1", "!haiku estimates")
+	assert.Equal(t, []string{"Words I have to guess with low confidence are: rejected"}, h.replies(modeID))
+
+	flagID := h.post(gid, cid, "1", "!haiku estimates flag")
+	assert.Equal(t, []string{"Words I have to guess with low confidence will be: accepted, and pointed out when I react to a haiku"}, h.replies(flagID))
+
+	asdfID = h.post(gid, cid, "2", asdfHaiku)
+	assert.Equal(t, []string{"💯"}, h.reactions(asdfID))
+	assert.Equal(t, []string{"I had to guess how to say some of these words: asdf (1)"}, h.replies(asdfID))
+
+	resp := h.command(gid, cid, "1", subcommand("estimates", stringOption("mode", "accept")))
+	assert.Equal(t, "Words I have to guess with low confidence will be: accepted", resp.Data.Content)
+
+	asdfID = h.post(gid, cid, "2", asdfHaiku)
+	assert.Equal(t, []string{"💯"}, h.reactions(asdfID))
+	assert.Empty(t, h.replies(asdfID))
+
+	badID := h.post(gid, cid, "1", "!haiku estimates sometimes")
+	assert.Equal(t, []string{"could not understand 'sometimes' as a valid estimate mode; expected one of accept, flag or reject"}, h.replies(badID))
+}
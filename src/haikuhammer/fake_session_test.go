@@ -0,0 +1,365 @@
+package haikuhammer
+
+import (
+	"context"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"unicode/utf8"
+)
+
+const fakeBotID = "999"
+
+// fakeSession is an in-memory stand-in for the Discord REST API which records every side effect requested of it.
+type fakeSession struct {
+	guilds   map[string]*discordgo.Guild
+	channels map[string]*discordgo.Channel
+	members  map[string]map[string]*discordgo.Member // maps from guildIDs to userIDs to members
+	roles    map[string][]*discordgo.Role            // maps from guildIDs to roles
+	messages map[string]*discordgo.Message
+
+	reactions map[string][]string // maps from messageIDs to the emoji currently left by the bot
+	deleted   []string            // IDs of deleted messages, in order
+	sent      []fakeSent          // every message sent, in order
+	responses []*discordgo.InteractionResponse
+
+	nextID int
+}
+
+// fakeSent records a message sent by the bot.
+type fakeSent struct {
+	ChannelID string
+	ReplyTo   string // ID of the message replied to, or empty if this was not a reply
+	Content   string
+}
+
+func newFakeSession() *fakeSession {
+	return &fakeSession{
+		guilds:    make(map[string]*discordgo.Guild),
+		channels:  make(map[string]*discordgo.Channel),
+		members:   make(map[string]map[string]*discordgo.Member),
+		roles:     make(map[string][]*discordgo.Role),
+		messages:  make(map[string]*discordgo.Message),
+		reactions: make(map[string][]string),
+		nextID:    1000,
+	}
+}
+
+// discordMessageLimit is the most characters Discord accepts in a message or interaction response.
+const discordMessageLimit = 2000
+
+// checkLength rejects content Discord would reject, so replies which only fit in tests are caught.
+func checkLength(content string) error {
+	if n := utf8.RuneCountInString(content); n > discordMessageLimit {
+		return fmt.Errorf("message is %d characters long, but Discord only allows %d", n, discordMessageLimit)
+	}
+	return nil
+}
+
+func (f *fakeSession) newID() string {
+	f.nextID++
+	return strconv.Itoa(f.nextID)
+}
+
+func (f *fakeSession) ChannelMessage(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	m, ok := f.messages[messageID]
+	if !ok || m.ChannelID != channelID {
+		return nil, fmt.Errorf("unknown message %s in channel %s", messageID, channelID)
+	}
+	result := *m
+	result.GuildID = "" // the REST API does not populate guild IDs
+	result.Reactions = nil
+	for _, emoji := range f.reactions[messageID] {
+		result.Reactions = append(result.Reactions, &discordgo.MessageReactions{Count: 1, Me: true, Emoji: &discordgo.Emoji{Name: emoji}})
+	}
+	return &result, nil
+}
+
+func (f *fakeSession) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if err := checkLength(content); err != nil {
+		return nil, err
+	}
+	f.sent = append(f.sent, fakeSent{ChannelID: channelID, Content: content})
+	return &discordgo.Message{ID: f.newID(), ChannelID: channelID, Content: content}, nil
+}
+
+func (f *fakeSession) ChannelMessageSendReply(channelID string, content string, reference *discordgo.MessageReference, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if err := checkLength(content); err != nil {
+		return nil, err
+	}
+	sent := fakeSent{ChannelID: channelID, Content: content}
+	if reference != nil {
+		sent.ReplyTo = reference.MessageID
+	}
+	f.sent = append(f.sent, sent)
+	return &discordgo.Message{ID: f.newID(), ChannelID: channelID, Content: content}, nil
+}
+
+func (f *fakeSession) ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error {
+	if _, ok := f.messages[messageID]; !ok {
+		return fmt.Errorf("unknown message %s", messageID)
+	}
+	delete(f.messages, messageID)
+	f.deleted = append(f.deleted, messageID)
+	return nil
+}
+
+func (f *fakeSession) MessageReactionAdd(channelID, messageID, emojiID string, options ...discordgo.RequestOption) error {
+	f.reactions[messageID] = append(f.reactions[messageID], emojiID)
+	return nil
+}
+
+func (f *fakeSession) MessageReactionRemove(channelID, messageID, emojiID, userID string, options ...discordgo.RequestOption) error {
+	if userID != fakeBotID {
+		return fmt.Errorf("cannot remove reactions for user %s", userID)
+	}
+	var kept []string
+	for _, emoji := range f.reactions[messageID] {
+		if emoji != emojiID {
+			kept = append(kept, emoji)
+		}
+	}
+	f.reactions[messageID] = kept
+	return nil
+}
+
+func (f *fakeSession) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	for _, c := range f.channels {
+		if c.Type == discordgo.ChannelTypeDM && c.Recipients[0].ID == recipientID {
+			return c, nil
+		}
+	}
+	c := &discordgo.Channel{ID: f.newID(), Type: discordgo.ChannelTypeDM, Recipients: []*discordgo.User{{ID: recipientID}}}
+	f.channels[c.ID] = c
+	return c, nil
+}
+
+func (f *fakeSession) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	c, ok := f.channels[channelID]
+	if !ok {
+		return nil, fmt.Errorf("unknown channel %s", channelID)
+	}
+	return c, nil
+}
+
+func (f *fakeSession) Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error) {
+	g, ok := f.guilds[guildID]
+	if !ok {
+		return nil, fmt.Errorf("unknown guild %s", guildID)
+	}
+	return g, nil
+}
+
+func (f *fakeSession) GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error) {
+	m, ok := f.members[guildID][userID]
+	if !ok {
+		return nil, fmt.Errorf("unknown member %s in guild %s", userID, guildID)
+	}
+	return m, nil
+}
+
+func (f *fakeSession) GuildRoles(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Role, error) {
+	if _, ok := f.guilds[guildID]; !ok {
+		return nil, fmt.Errorf("unknown guild %s", guildID)
+	}
+	return f.roles[guildID], nil
+}
+
+func (f *fakeSession) User(userID string, options ...discordgo.RequestOption) (*discordgo.User, error) {
+	for _, members := range f.members {
+		if m, ok := members[userID]; ok {
+			return m.User, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown user %s", userID)
+}
+
+func (f *fakeSession) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	if resp.Data != nil {
+		if err := checkLength(resp.Data.Content); err != nil {
+			return err
+		}
+	}
+	f.responses = append(f.responses, resp)
+	return nil
+}
+
+// harness wires a HaikuHammer to a fakeSession and an empty in-memory store so tests can script conversations and assert
+// on everything the bot did in response.
+type harness struct {
+	t       *testing.T
+	session *fakeSession
+	discord *DiscordPlatform
+	hammer  *HaikuHammer
+	store   db.Store
+}
+
+// allFlags enables every action globally; tests narrow them down per guild and channel.
+const allFlags = db.ConfigReactToHaiku | db.ConfigReactToNonHaiku | db.ConfigDeleteNonHaiku | db.ConfigExplainNonHaiku | db.ConfigServeRandomHaiku |
+	db.ConfigInferLineBreaks | db.ConfigCountInterjections | db.ConfigReplyToCopies
+
+func newHarness(t *testing.T) *harness {
+	session := newFakeSession()
+	h := &harness{t: t, session: session, store: db.NewMemoryStore()}
+	h.hammer = NewHaikuHammerWithPlatform(Config{
+		ActionFlags:     allFlags,
+		PositiveReacts:  []string{"💯"},
+		NegativeReacts:  []string{"🚫"},
+		QuotationReacts: []string{"📜"},
+	}, nil, h.store)
+	h.discord = newDiscordPlatform(session, h.hammer, false)
+	h.discord.botID = fakeBotID
+	h.hammer.platform = h.discord
+	return h
+}
+
+// addGuild creates a guild owned by ownerID with a single text channel, returning the guild and channel IDs.
+func (h *harness) addGuild(ownerID string) (guildID, channelID string) {
+	guildID, channelID = h.session.newID(), h.session.newID()
+	h.session.guilds[guildID] = &discordgo.Guild{ID: guildID, OwnerID: ownerID}
+	h.session.channels[channelID] = &discordgo.Channel{ID: channelID, GuildID: guildID, Type: discordgo.ChannelTypeGuildText}
+	h.session.members[guildID] = make(map[string]*discordgo.Member)
+	h.session.roles[guildID] = []*discordgo.Role{{ID: guildID, Name: "@everyone"}}
+	h.addMember(guildID, ownerID, "owner")
+	return guildID, channelID
+}
+
+// addChannel creates another text channel in the provided guild.
+func (h *harness) addChannel(guildID string) string {
+	channelID := h.session.newID()
+	h.session.channels[channelID] = &discordgo.Channel{ID: channelID, GuildID: guildID, Type: discordgo.ChannelTypeGuildText}
+	return channelID
+}
+
+func (h *harness) addMember(guildID, userID, nick string, roleIDs ...string) {
+	h.session.members[guildID][userID] = &discordgo.Member{GuildID: guildID, Nick: nick, Roles: roleIDs,
+		User: &discordgo.User{ID: userID, Username: "user" + userID}}
+}
+
+// addRole creates a role with the provided permissions, returning its ID.
+func (h *harness) addRole(guildID string, perms int64) string {
+	roleID := h.session.newID()
+	h.session.roles[guildID] = append(h.session.roles[guildID], &discordgo.Role{ID: roleID, Name: "role" + roleID, Permissions: perms})
+	return roleID
+}
+
+func (h *harness) setGuildFlags(guildID string, flags db.ConfigFlag) {
+	gid, err := strconv.Atoi(guildID)
+	require.NoError(h.t, err)
+	err = h.store.SaveGuildConfig(context.Background(), db.GuildConfig{GuildID: gid, Flags: flags})
+	require.NoError(h.t, err)
+}
+
+func (h *harness) setChannelFlags(channelID string, flags db.ConfigFlag) {
+	cid, err := strconv.Atoi(channelID)
+	require.NoError(h.t, err)
+	err = h.store.SaveChannelConfig(context.Background(), db.ChannelConfig{ChannelID: cid, Flags: flags})
+	require.NoError(h.t, err)
+}
+
+// post simulates authorID sending content to a channel, returning the ID of the new message.
+func (h *harness) post(guildID, channelID, authorID, content string, mentions ...string) string {
+	m := &discordgo.Message{
+		ID:        h.session.newID(),
+		ChannelID: channelID,
+		GuildID:   guildID,
+		Content:   content,
+		Author:    &discordgo.User{ID: authorID, Bot: authorID == fakeBotID},
+	}
+	for _, userID := range mentions {
+		m.Mentions = append(m.Mentions, &discordgo.User{ID: userID})
+	}
+	h.session.messages[m.ID] = m
+	h.discord.receiveMessageCreate(nil, &discordgo.MessageCreate{Message: m})
+	return m.ID
+}
+
+// command simulates userID sending a `/haiku` slash command with the provided options, returning the response.
+func (h *harness) command(guildID, channelID, userID string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionResponse {
+	return h.interact(discordgo.InteractionApplicationCommand, guildID, channelID, userID, options)
+}
+
+func (h *harness) interact(typ discordgo.InteractionType, guildID, channelID, userID string, options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionResponse {
+	i := &discordgo.Interaction{
+		ID:        h.session.newID(),
+		Type:      typ,
+		GuildID:   guildID,
+		ChannelID: channelID,
+		Member:    &discordgo.Member{User: &discordgo.User{ID: userID}},
+		Data:      discordgo.ApplicationCommandInteractionData{Name: slashCommandName, Options: options},
+	}
+	before := len(h.session.responses)
+	h.discord.receiveInteractionCreate(nil, &discordgo.InteractionCreate{Interaction: i})
+	require.Len(h.t, h.session.responses, before+1)
+	return h.session.responses[before]
+}
+
+// edit simulates the author of messageID changing its content.
+func (h *harness) edit(messageID, content string) {
+	m := h.session.messages[messageID]
+	m.Content = content
+	h.discord.receiveMessageEdit(nil, &discordgo.MessageUpdate{Message: m})
+}
+
+// react simulates userID adding an emoji reaction to messageID.
+func (h *harness) react(guildID, channelID, messageID, userID, emoji string) {
+	h.discord.receiveReactionAdd(nil, &discordgo.MessageReactionAdd{MessageReaction: &discordgo.MessageReaction{
+		UserID: userID, MessageID: messageID, ChannelID: channelID, GuildID: guildID, Emoji: discordgo.Emoji{Name: emoji},
+	}})
+}
+
+// unreact simulates userID removing an emoji reaction from messageID.
+func (h *harness) unreact(guildID, channelID, messageID, userID, emoji string) {
+	h.discord.receiveReactionRemove(nil, &discordgo.MessageReactionRemove{MessageReaction: &discordgo.MessageReaction{
+		UserID: userID, MessageID: messageID, ChannelID: channelID, GuildID: guildID, Emoji: discordgo.Emoji{Name: emoji},
+	}})
+}
+
+func (h *harness) reactions(messageID string) []string {
+	return h.session.reactions[messageID]
+}
+
+// replies returns the content of every reply the bot sent to messageID.
+func (h *harness) replies(messageID string) []string {
+	var result []string
+	for _, sent := range h.session.sent {
+		if sent.ReplyTo == messageID {
+			result = append(result, sent.Content)
+		}
+	}
+	return result
+}
+
+// messages returns the content of every message the bot sent to channelID which was not a reply.
+func (h *harness) messages(channelID string) []string {
+	var result []string
+	for _, sent := range h.session.sent {
+		if sent.ChannelID == channelID && sent.ReplyTo == "" {
+			result = append(result, sent.Content)
+		}
+	}
+	return result
+}
+
+// dms returns the content of every direct message the bot sent to userID.
+func (h *harness) dms(userID string) []string {
+	var result []string
+	for _, sent := range h.session.sent {
+		c := h.session.channels[sent.ChannelID]
+		if c != nil && c.Type == discordgo.ChannelTypeDM && c.Recipients[0].ID == userID {
+			result = append(result, sent.Content)
+		}
+	}
+	return result
+}
+
+func (h *harness) savedHaiku(messageID string) db.Haiku {
+	mid, err := strconv.Atoi(messageID)
+	require.NoError(h.t, err)
+	haiku, err := h.store.FindHaiku(context.Background(), mid)
+	require.NoError(h.t, err)
+	return haiku
+}
@@ -0,0 +1,257 @@
+package haikuhammer
+
+import (
+	"context"
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Form is a poetic form which messages can be checked against.
+type Form struct {
+	Name      string
+	Title     string // name of the form as it appears in explanations, e.g. "Haiku"
+	Lines     int
+	Pattern   []int // syllables expected on each line
+	Tolerance int   // number of syllables each line may be off by
+}
+
+var (
+	FormHaiku    = Form{Name: "haiku", Title: "Haiku", Lines: 3, Pattern: []int{5, 7, 5}}
+	FormSenryu   = Form{Name: "senryu", Title: "Senryu", Lines: 3, Pattern: []int{5, 7, 5}}
+	FormTanka    = Form{Name: "tanka", Title: "Tanka", Lines: 5, Pattern: []int{5, 7, 5, 7, 7}}
+	FormCinquain = Form{Name: "cinquain", Title: "Cinquain", Lines: 5, Pattern: []int{2, 4, 6, 8, 2}}
+)
+
+// Forms is the registry of built-in forms. Haiku and senryu share a pattern, so a message matching both is recorded
+// as whichever is listed first.
+var Forms = []Form{FormHaiku, FormSenryu, FormTanka, FormCinquain}
+
+// DefaultForms are the forms accepted in guilds which haven't chosen any.
+var DefaultForms = []Form{FormHaiku}
+
+// matches returns true if every line could be pronounced with a number of syllables which fits the pattern of this
+// form.
+func (f Form) matches(counts [][]int) bool {
+	if len(counts) != f.Lines {
+		return false
+	}
+	for i, expected := range f.Pattern {
+		if !f.lineMatches(counts[i], expected) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Form) lineMatches(counts []int, expected int) bool {
+	for _, count := range counts {
+		diff := count - expected
+		if diff <= f.Tolerance && -diff <= f.Tolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// describe renders the pattern of this form, e.g. 5/7/5, or 5/7/5 ±1 if it has a tolerance.
+func (f Form) describe() string {
+	if f.Tolerance == 0 {
+		return joinCounts(f.Pattern)
+	}
+	return fmt.Sprintf("%s ±%d", joinCounts(f.Pattern), f.Tolerance)
+}
+
+// parseForm looks up a built-in form by name, or parses a custom pattern such as 5/5/5, optionally followed by a
+// tolerance, as in 5/7/5~1.
+func parseForm(s string) (Form, error) {
+	for _, form := range Forms {
+		if strings.EqualFold(s, form.Name) {
+			return form, nil
+		}
+	}
+	pattern, tolerance := s, "0"
+	if idx := strings.Index(s, "~"); idx >= 0 {
+		pattern, tolerance = s[:idx], s[idx+1:]
+	}
+	result := Form{Name: s, Title: "Poem"}
+	var err error
+	result.Tolerance, err = strconv.Atoi(tolerance)
+	if err != nil || result.Tolerance < 0 {
+		return Form{}, fmt.Errorf("could not understand '%s' as a valid form; expected one of %s or a pattern like 5/7/5", s, formNames(Forms))
+	}
+	for _, token := range strings.Split(pattern, "/") {
+		count, err := strconv.Atoi(token)
+		if err != nil || count <= 0 {
+			return Form{}, fmt.Errorf("could not understand '%s' as a valid form; expected one of %s or a pattern like 5/7/5", s, formNames(Forms))
+		}
+		result.Pattern = append(result.Pattern, count)
+	}
+	result.Lines = len(result.Pattern)
+	return result, nil
+}
+
+func parseForms(names []string) ([]Form, error) {
+	var result []Form
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		form, err := parseForm(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, form)
+	}
+	return result, nil
+}
+
+func formNames(forms []Form) string {
+	names := make([]string, len(forms))
+	for i, form := range forms {
+		names[i] = form.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// forms returns the forms accepted in the channel a message was sent to.
+func (h *HaikuHammer) forms(m *Message) []Form {
+	guildID, channelID, _, err := idToInt(m)
+	if err != nil {
+		return DefaultForms
+	}
+	return h.lookupForms(guildID, channelID)
+}
+
+// enforcedForms returns the forms which DeleteNonHaiku keeps in the channel a message was sent to.
+func (h *HaikuHammer) enforcedForms(m *Message) []Form {
+	guildID, channelID, _, err := idToInt(m)
+	if err != nil {
+		return DefaultForms
+	}
+	return h.lookupEnforcedForms(guildID, channelID)
+}
+
+func (h *HaikuHammer) lookupForms(guildID, channelID int) []Form {
+	return h.lookupFormSetting(guildID, channelID, db.SettingForms, DefaultForms)
+}
+
+// lookupEnforcedForms returns the forms enforced in a channel, which are the forms it accepts unless admins chose
+// otherwise.
+func (h *HaikuHammer) lookupEnforcedForms(guildID, channelID int) []Form {
+	return h.lookupFormSetting(guildID, channelID, db.SettingEnforcedForms, h.lookupForms(guildID, channelID))
+}
+
+func (h *HaikuHammer) lookupFormSetting(guildID, channelID int, name string, defaults []Form) []Form {
+	value, err := h.store.LookupSetting(context.Background(), guildID, channelID, name)
+	if err != nil {
+		log.Println("could not retrieve", name, "for guildID:", guildID, "channelID:", channelID)
+		return defaults
+	}
+	forms, err := parseForms(strings.Split(value, ","))
+	if err != nil {
+		log.Println("could not parse", name, "for guildID:", guildID, "channelID:", channelID, err)
+		return defaults
+	}
+	if len(forms) == 0 {
+		return defaults
+	}
+	return forms
+}
+
+// violatesEnforcedForms returns true if a message matches none of the forms enforced in its channel.
+func (h *HaikuHammer) violatesEnforcedForms(m *Message) bool {
+	c := h.checker(m)
+	c.forms = h.enforcedForms(m)
+	return c.analyse(m.Content).Err() != nil
+}
+
+// formSetting returns the name of the setting a form command configures, and how replies describe its forms.
+func formSetting(command Command) (name, verb string) {
+	if command.EnforcesForms() {
+		return db.SettingEnforcedForms, "enforced"
+	}
+	return db.SettingForms, "accepted"
+}
+
+// commandForms returns the forms currently held by the setting a form command configures.
+func (h *HaikuHammer) commandForms(command Command, guildID, channelID int) []Form {
+	if command.EnforcesForms() {
+		return h.lookupEnforcedForms(guildID, channelID)
+	}
+	return h.lookupForms(guildID, channelID)
+}
+
+// updateForms enables or disables forms for the command target, starting from the forms it currently accepts or
+// enforces.
+func (h *HaikuHammer) updateForms(guildID string, command Command, enable bool) string {
+	ctx := context.Background()
+	failure := fmt.Sprintf("I couldn't update the forms for target %s, please try again later", command.MentionTarget())
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return failure
+	}
+	scope, targetID, cid := db.ScopeGuild, gid, 0
+	if command.Target != "global" {
+		cid, err = strconv.Atoi(command.Target)
+		if err != nil {
+			log.Println("could not parse channelID as integer,", command.Target)
+			return failure
+		}
+		scope, targetID = db.ScopeChannel, cid
+	}
+	name, verb := formSetting(command)
+	var updated []Form
+	for _, form := range h.commandForms(command, gid, cid) {
+		if !containsForm(command.Forms, form) {
+			updated = append(updated, form)
+		}
+	}
+	if enable {
+		updated = append(updated, command.Forms...)
+	}
+	if len(updated) == 0 {
+		return "At least one form must be enabled"
+	}
+	err = h.store.SaveSetting(ctx, db.Setting{Scope: scope, TargetID: targetID, Name: name, Value: formNames(updated)})
+	if err != nil {
+		log.Println("could not update forms,", err)
+		return failure
+	}
+	return fmt.Sprintf("Forms %s for target %s: %s", verb, command.MentionTarget(), formNames(updated))
+}
+
+func (h *HaikuHammer) formList(guildID string, command Command) string {
+	_, verb := formSetting(command)
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return fmt.Sprintf("I couldn't look up the forms %s for target %s, please try again later", verb, command.MentionTarget())
+	}
+	cid := 0
+	if command.Target != "global" {
+		cid, err = strconv.Atoi(command.Target)
+		if err != nil {
+			log.Println("could not parse channelID as integer,", command.Target)
+			return fmt.Sprintf("I couldn't look up the forms %s for target %s, please try again later", verb, command.MentionTarget())
+		}
+	}
+	var descriptions []string
+	for _, form := range h.commandForms(command, gid, cid) {
+		descriptions = append(descriptions, fmt.Sprintf("%s (%s)", form.Name, form.describe()))
+	}
+	return fmt.Sprintf("Forms %s for target %s: %s", verb, command.MentionTarget(), strings.Join(descriptions, ", "))
+}
+
+func containsForm(forms []Form, form Form) bool {
+	for _, f := range forms {
+		if f.Name == form.Name {
+			return true
+		}
+	}
+	return false
+}
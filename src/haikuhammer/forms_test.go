@@ -0,0 +1,155 @@
+package haikuhammer
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const (
+	testTanka    = testHaiku + "\nFor the automated bot\nAnother seven right here"
+	testCinquain = "Hello\nthe old green pond\nthe frog jumps into it\nand now it is a wet frog now\nthe end"
+)
+
+func TestParseForm(t *testing.T) {
+	form, err := parseForm("Tanka")
+	assert.NoError(t, err)
+	assert.Equal(t, FormTanka, form)
+
+	form, err = parseForm("4/6/4~1")
+	assert.NoError(t, err)
+	assert.Equal(t, Form{Name: "4/6/4~1", Title: "Poem", Lines: 3, Pattern: []int{4, 6, 4}, Tolerance: 1}, form)
+
+	for _, bad := range []string{"sonnet", "5/x/5", "5/7/5~", "5/7/5~-1", "0/7/5"} {
+		_, err = parseForm(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestAnalyse_Forms(t *testing.T) {
+	a := analyse(testTanka, FormHaiku, FormTanka, FormCinquain)
+	assert.NoError(t, a.Err())
+	assert.Equal(t, FormTanka, a.Form)
+
+	a = analyse(testCinquain, FormHaiku, FormTanka, FormCinquain)
+	assert.NoError(t, a.Err())
+	assert.Equal(t, FormCinquain, a.Form)
+
+	a = analyse(testHaiku, FormSenryu, FormHaiku)
+	assert.NoError(t, a.Err())
+	assert.Equal(t, FormSenryu, a.Form, "the first matching form is used")
+
+	a = analyse(testTanka, FormHaiku, FormCinquain)
+	assert.EqualError(t, a.Err(), "Hmmm, this doesn't seem like a traditional English Cinquain; here's why:\n"+
+		"- I counted a syllable structure of 5/7/5/7/7, but I expected 2/4/6/8/2")
+
+	a = analyse(testHaiku, FormTanka, FormCinquain)
+	assert.EqualError(t, a.Err(), "This doesn't seem to me like a poem I know; it doesn't have 5 lines.")
+
+	a = analyse("one line", FormHaiku, FormTanka)
+	assert.EqualError(t, a.Err(), "This doesn't seem to me like a poem I know; it doesn't have 3 or 5 lines.")
+
+	loose := Form{Name: "loose", Title: "Poem", Lines: 3, Pattern: []int{4, 8, 4}, Tolerance: 1}
+	assert.NoError(t, analyse(testHaiku, loose).Err())
+	assert.EqualError(t, analyse(testHaiku, Form{Name: "strict", Title: "Poem", Lines: 3, Pattern: []int{4, 8, 4}}).Err(),
+		"Hmmm, this doesn't seem like a traditional English Poem; here's why:\n"+
+			"- I counted a syllable structure of 5/7/5, but I expected 4/8/4")
+}
+
+func TestForms(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	otherCID := h.addChannel(gid)
+	h.setGuildFlags(gid, db.ConfigReactToHaiku)
+
+	listID := h.post(gid, cid, "1", "!haiku form list global")
+	assert.Equal(t, []string{"Forms accepted for target global: haiku (5/7/5)"}, h.replies(listID))
+
+	onID := h.post(gid, cid, "1", "!haiku form on "+channelMention(cid)+" tanka 3/3/3~1")
+	assert.Equal(t, []string{"Forms accepted for target " + channelMention(cid) + ": haiku, tanka, 3/3/3~1"}, h.replies(onID))
+
+	tankaID := h.post(gid, cid, "2", testTanka)
+	assert.Equal(t, []string{"💯"}, h.reactions(tankaID))
+	assert.Equal(t, "tanka", h.savedHaiku(tankaID).Form)
+
+	haikuID := h.post(gid, cid, "2", testHaiku)
+	assert.Equal(t, "haiku", h.savedHaiku(haikuID).Form)
+
+	otherID := h.post(gid, otherCID, "2", "Another test case\nFor the automated bot\nHere’s a new haiku\nFor the automated bot\nAnother seven right here")
+	assert.Empty(t, h.reactions(otherID), "other channels still only accept haiku")
+
+	offID := h.post(gid, cid, "1", "!haiku form off "+channelMention(cid)+" haiku tanka 3/3/3~1")
+	assert.Equal(t, []string{"At least one form must be enabled"}, h.replies(offID))
+
+	resp := h.command(gid, cid, "1", formGroup(subcommand("on", stringOption("forms", "tanka, cinquain"))))
+	assert.Equal(t, "Forms accepted for target global: haiku, tanka, cinquain", resp.Data.Content)
+
+	cinquainID := h.post(gid, otherCID, "2", testCinquain)
+	assert.Equal(t, []string{"💯"}, h.reactions(cinquainID))
+	assert.Equal(t, "cinquain", h.savedHaiku(cinquainID).Form)
+}
+
+func TestForms_DefaultChannel(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.session.channels[gid] = &discordgo.Channel{ID: gid, GuildID: gid, Type: discordgo.ChannelTypeGuildText} // older guilds' default channel
+	h.setGuildFlags(gid, db.ConfigReactToHaiku)
+
+	h.post(gid, cid, "1", "!haiku form on global tanka")
+	onID := h.post(gid, cid, "1", "!haiku form off "+channelMention(gid)+" haiku")
+	assert.Equal(t, []string{"Forms accepted for target " + channelMention(gid) + ": tanka"}, h.replies(onID))
+
+	listID := h.post(gid, cid, "1", "!haiku form list global")
+	assert.Equal(t, []string{"Forms accepted for target global: haiku (5/7/5), tanka (5/7/5/7/7)"}, h.replies(listID),
+		"the default channel's forms don't replace the guild's")
+	haikuID := h.post(gid, cid, "2", testHaiku)
+	assert.Equal(t, []string{"💯"}, h.reactions(haikuID))
+	haikuID = h.post(gid, gid, "2", testHaiku)
+	assert.Empty(t, h.reactions(haikuID))
+}
+
+func TestEnforcedForms(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.setGuildFlags(gid, db.ConfigReactToHaiku|db.ConfigDeleteNonHaiku)
+
+	listID := h.post(gid, cid, "1", "!haiku enforce list global")
+	assert.Equal(t, []string{"Forms enforced for target global: haiku (5/7/5)"}, h.replies(listID), "accepted forms are enforced by default")
+
+	onID := h.post(gid, cid, "1", "!haiku enforce on global 5/7/5~1")
+	assert.Equal(t, []string{"Forms enforced for target global: haiku, 5/7/5~1"}, h.replies(onID))
+	offID := h.post(gid, cid, "1", "!haiku enforce off global haiku")
+	assert.Equal(t, []string{"Forms enforced for target global: 5/7/5~1"}, h.replies(offID))
+
+	looseID := h.post(gid, cid, "2", "Another test case\nFor the automated bot now\nHere’s a nice haiku")
+	assert.Empty(t, h.reactions(looseID), "only accepted forms are celebrated")
+	assert.NotContains(t, h.session.deleted, looseID, "enforced forms aren't deleted")
+
+	badID := h.post(gid, cid, "2", "Another test case\nFor the automated bot right now\nHere’s a nice haiku")
+	assert.Contains(t, h.session.deleted, badID)
+
+	resp := h.command(gid, cid, "1", formGroup(subcommand("on", stringOption("forms", "tanka"))))
+	assert.Equal(t, "Forms accepted for target global: haiku, tanka", resp.Data.Content)
+	tankaID := h.post(gid, cid, "2", testTanka)
+	assert.Contains(t, h.session.deleted, tankaID, "accepted forms which aren't enforced are deleted")
+
+	resp = h.command(gid, cid, "1", enforceGroup(subcommand("on", stringOption("forms", "tanka"), channelOption(cid))))
+	assert.Equal(t, "Forms enforced for target "+channelMention(cid)+": 5/7/5~1, tanka", resp.Data.Content)
+	tankaID = h.post(gid, cid, "2", testTanka)
+	assert.Equal(t, []string{"💯"}, h.reactions(tankaID))
+	assert.NotContains(t, h.session.deleted, tankaID)
+
+	resp = h.command(gid, cid, "1", enforceGroup(subcommand("list")))
+	assert.Equal(t, "Forms enforced for target global: 5/7/5~1 (5/7/5 ±1)", resp.Data.Content)
+}
+
+func formGroup(sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: "form", Type: discordgo.ApplicationCommandOptionSubCommandGroup,
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{sub}}
+}
+
+func enforceGroup(sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: "enforce", Type: discordgo.ApplicationCommandOptionSubCommandGroup,
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{sub}}
+}
@@ -3,73 +3,88 @@ package haikuhammer
 import (
 	"errors"
 	"fmt"
-	"regexp"
+	"github.com/kalexmills/haiku-enforcer/src/dict"
+	"strconv"
 	"strings"
 )
 
 var (
-	ErrThreeLines = errors.New("This doesn't seem to me like a traditional haiku; it doesn't have three lines.")
+	ErrThreeLines = errors.New(English.Phrases().ThreeLines)
 )
 
 // IsHaiku returns nil if the provided string is a properly formed 3-line haiku with
-// 5/7/5 structure, otherwise it returns an error explaining any issues it has found.
+// 5/7/5 structure, otherwise it returns an error explaining any issues it has found. Messages with three lines which
+// are not haiku result in a *HaikuError.
 func IsHaiku(str string) error {
-	trimmed := strings.Trim(str, " \n\t")
-	cleaned := cleanEmoji(trimmed)
-	lines := strings.Split(cleaned, "\n")
-	if len(lines) != 3 {
-		return ErrThreeLines
-	}
-	line1, err1 := lineSyllableCount(lines[0])
-	line2, err2 := lineSyllableCount(lines[1])
-	line3, err3 := lineSyllableCount(lines[2])
-	return evaluateHaiku([3]int{line1,line2,line3}, []error{err1, err2, err3})
+	return analyse(str, FormHaiku).Err()
 }
 
-// evaluateHaiku returns nil if the provided data represents a Haiku, otherwise it explains why the provided string is
-// not a haiku
-func evaluateHaiku(lines [3]int, errs []error) error {
-	if lines != [3]int{5,7,5} {
-		errs = append(errs, fmt.Errorf("I counted a syllable structure of %d/%d/%d, but I expected 5/7/5", lines[0], lines[1], lines[2]))
-	}
-	errStr := "Hmmm, this doesn't seem like a traditional English Haiku; here's why:"
-	var hasErr bool
-	for _, err := range errs {
-		if err == nil {
-			continue
+// lineCountError explains, using the provided phrases, that a message doesn't have the right number of lines for any of
+// the provided forms.
+func lineCountError(phrases dict.Phrases, forms []Form) error {
+	var counts []string
+	for _, form := range forms {
+		count := strconv.Itoa(form.Lines)
+		if !containsString(counts, count) {
+			counts = append(counts, count)
 		}
-		hasErr = true
-		errStr += "\n- " + err.Error()
 	}
-	if hasErr {
-		return errors.New(errStr)
+	if len(counts) == 1 && counts[0] == "3" {
+		if phrases.ThreeLines == ErrThreeLines.Error() {
+			return ErrThreeLines
+		}
+		return errors.New(phrases.ThreeLines)
 	}
-	return nil
+	return fmt.Errorf(phrases.LineCount, strings.Join(counts, phrases.Or))
 }
 
-var EmojiRegex *regexp.Regexp
-func cleanEmoji(s string) string {
-	return strings.TrimSpace(EmojiRegex.ReplaceAllString(s, ""))
+func containsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
 }
 
-func lineSyllableCount(line string) (int, error) {
-	words := strings.Split(line, " ")
-	count := 0
+// HaikuError explains why a message with the right number of lines is not a haiku, or whichever form it was checked
+// against.
+type HaikuError struct {
+	Analysis
+}
 
-	var unknownWords []string
-	for _, word := range words {
-		if len(word) == 0 {
-			continue
+func (e *HaikuError) Error() string {
+	phrases := e.phrases()
+	errStr := fmt.Sprintf(phrases.NotAPoem, e.Form.Title)
+	for _, line := range e.Lines {
+		var unknown []string
+		var estimated, overridden []Word
+		for _, word := range line.Words {
+			if word.Source == SourceUnknown {
+				unknown = append(unknown, word.Text)
+			}
+			if word.Source == SourceEstimate {
+				estimated = append(estimated, word)
+			}
+			if word.Source == SourceGuildDictionary {
+				overridden = append(overridden, word)
+			}
+			if word.Source == SourceNumber {
+				errStr += "\n- " + fmt.Sprintf(phrases.ReadNumber, word.Text, word.Spoken)
+			}
 		}
-		syllables, ok := CountSyllables(word)
-		if !ok {
-			unknownWords = append(unknownWords, word)
-
+		if len(unknown) != 0 {
+			errStr += "\n- " + phrases.UnknownWords + strings.Join(unknown, ", ")
+		}
+		if len(overridden) != 0 {
+			errStr += "\n- " + phrases.GuildWords + describeWords(overridden)
+		}
+		if len(estimated) != 0 {
+			errStr += "\n- " + phrases.GuessedWords + describeWords(estimated)
 		}
-		count += syllables
 	}
-	if len(unknownWords) != 0 {
-		return 0, fmt.Errorf("I don't know the words: %s", strings.Join(unknownWords, ", "))
+	if !e.Form.matches(e.Counts()) {
+		errStr += "\n- " + fmt.Sprintf(phrases.Structure, e.Structure(), e.Form.describe())
 	}
-	return count, nil
-}
\ No newline at end of file
+	return errStr
+}
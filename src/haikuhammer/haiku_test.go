@@ -52,4 +52,30 @@ func TestIsHaiku(t *testing.T) {
 	for _, nonHaiku := range notHaikus {
 		assert.Error(t, IsHaiku(nonHaiku), nonHaiku)
 	}
-}
\ No newline at end of file
+}
+
+func TestIsHaiku_HaikuError(t *testing.T) {
+	err := IsHaiku("Banana man why\nmake so many haiku\nDo you have job")
+	var haikuErr *HaikuError
+	if assert.ErrorAs(t, err, &haikuErr) {
+		assert.Equal(t, [][]int{{5}, {6}, {4}}, haikuErr.Counts())
+		assert.Empty(t, haikuErr.UnknownWords())
+		assert.Equal(t, "Hmmm, this doesn't seem like a traditional English Haiku; here's why:\n- I counted a syllable structure of 5/6/4, but I expected 5/7/5", err.Error())
+	}
+
+	err = IsHaiku("asdf\nsdfg\ngadf")
+	if assert.ErrorAs(t, err, &haikuErr) {
+		assert.Equal(t, []string{"asdf", "sdfg", "gadf"}, wordTexts(haikuErr.UnknownWords()))
+		assert.Equal(t, "0/0/0", haikuErr.Structure())
+	}
+
+	assert.Equal(t, ErrThreeLines, IsHaiku("it's not a haiku"))
+}
+
+func TestIsHaiku_Pronunciations(t *testing.T) {
+	assert.NoError(t, IsHaiku("Every fire burns\nthe old pond is quiet still\na frog jumps in now"))
+
+	err := IsHaiku("Every fire burns bright tonight\nthe old pond is quiet still\na frog jumps in now")
+	assert.EqualError(t, err, "Hmmm, this doesn't seem like a traditional English Haiku; here's why:\n"+
+		"- I counted a syllable structure of 7–9/7/5, but I expected 5/7/5")
+}
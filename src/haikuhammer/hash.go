@@ -3,7 +3,6 @@ package haikuhammer
 import (
 	"context"
 	"crypto/md5"
-	"database/sql"
 	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
 	"log"
 	"strings"
@@ -30,9 +29,9 @@ func hashStrip(s string) string {
 	})
 }
 
-// UpdateHashes ensures all haiku have their hashes loaded into the table. It's intended
+// UpdateHashes ensures all haiku have their hashes and fingerprints loaded into their tables. It's intended
 // to be run on a separate thread on startup.
-func UpdateHashes(sqlDB *sql.DB) {
+func UpdateHashes(store db.Store) {
 	defer func() {
 		if err := recover(); err != nil {
 			log.Printf("recovered from panic in UpdateHashes: %v", err)
@@ -41,30 +40,19 @@ func UpdateHashes(sqlDB *sql.DB) {
 	}()
 	log.Println("beginning UpdateHashes.")
 	ctx := context.Background()
-	rows, err := sqlDB.QueryContext(ctx, `SELECT message_id, content FROM haiku`)
-	if err == sql.ErrNoRows {
-		return
-	}
-	if err != nil {
-		log.Println("encountered error while updating hashes,", err)
-	}
-	defer rows.Close()
-	var (
-		messageID int
-		content string
-	)
 	insertCount := 0
-	for rows.Next() {
-		err = rows.Scan(&messageID, &content)
-		if err != nil {
-			log.Println("encountered error while scanning hashes,", err)
-			return
-		}
-		hash := DuplicateHash(content)
-		count, _ := db.HaikuHashDAO.Upsert(ctx, sqlDB, messageID, hash[:])
-		if count != 0 {
+	err := store.EachHaiku(ctx, func(haiku db.Haiku) {
+		err := store.SaveHash(ctx, haiku.MessageID, DuplicateHash(haiku.Content))
+		if err == nil {
 			insertCount++
 		}
+		err = store.SaveFingerprint(ctx, haiku.MessageID, int64(Fingerprint(haiku.Content)))
+		if err != nil {
+			log.Println("encountered error while updating fingerprints,", err)
+		}
+	})
+	if err != nil {
+		log.Println("encountered error while updating hashes,", err)
 	}
 	log.Printf("upserted %d new haiku hashes", insertCount)
-}
\ No newline at end of file
+}
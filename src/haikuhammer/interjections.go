@@ -0,0 +1,97 @@
+package haikuhammer
+
+// Interjections are common chat interjections and abbreviations, along with the number of syllables they're usually
+// said with. Most are missing from the dictionary, or would be counted letter by letter.
+var Interjections = map[string]int{
+	"LOL":  1,
+	"LMAO": 2,
+	"ROFL": 2,
+	"OMG":  3,
+	"HMM":  1,
+	"UGH":  1,
+	"BRB":  3,
+	"BTW":  5,
+	"IDK":  3,
+	"IKR":  3,
+	"IMO":  3,
+	"SMH":  3,
+	"TBH":  3,
+	"TTYL": 4,
+}
+
+// maxElongatedRuns limits how many stretched runs of letters a single word can have, since every run doubles the
+// number of spellings to search.
+const maxElongatedRuns = 4
+
+// countSyllables counts the syllables in a word, trying the guild dictionary and then Interjections first if this
+// checker counts them, before counting it in the checker's language.
+func (c checker) countSyllables(word string) ([]int, Source) {
+	if w, ok := c.words[cleanWord(word)]; ok {
+		if w.GuildID == 0 {
+			return []int{w.Syllables}, SourceDictionary // approved for every guild, so treated like any other word
+		}
+		return []int{w.Syllables}, SourceGuildDictionary
+	}
+	if c.interjections {
+		if count, ok := countInterjection(word); ok {
+			return []int{count}, SourceInterjection
+		}
+	}
+	return c.lang().CountWord(word)
+}
+
+func countInterjection(word string) (int, bool) {
+	cleaned := cleanWord(word)
+	for _, spelling := range append([]string{cleaned}, elongations(cleaned)...) {
+		if count, ok := Interjections[spelling]; ok {
+			return count, true
+		}
+	}
+	return 0, false
+}
+
+// countElongated counts words stretched for emphasis by repeating a letter, like SOOOOO or HMMMM, by shortening each
+// run of three or more of the same letter until a known word is found.
+func countElongated(cleaned string) ([]int, bool) {
+	for _, spelling := range elongations(cleaned) {
+		counts, source := countWord(spelling)
+		if source != SourceUnknown {
+			return counts, true
+		}
+	}
+	return nil, false
+}
+
+// elongations returns every spelling of a word with each run of three or more of the same letter shortened to two
+// letters or one, preferring two. Returns nil if the word has no such runs.
+func elongations(cleaned string) []string {
+	type run struct{ start, end int }
+	var runs []run
+	for i := 0; i < len(cleaned); {
+		j := i
+		for j < len(cleaned) && cleaned[j] == cleaned[i] {
+			j++
+		}
+		if j-i >= 3 {
+			runs = append(runs, run{i, j})
+		}
+		i = j
+	}
+	if len(runs) == 0 || len(runs) > maxElongatedRuns {
+		return nil
+	}
+	var result []string
+	for mask := 0; mask < 1<<len(runs); mask++ {
+		spelling, prev := "", 0
+		for i, r := range runs {
+			keep := 2
+			if mask&(1<<i) != 0 {
+				keep = 1
+			}
+			spelling += cleaned[prev : r.start+keep]
+			prev = r.end
+		}
+		result = append(result, spelling+cleaned[prev:])
+	}
+	return result
+}
@@ -0,0 +1,41 @@
+package haikuhammer
+
+import (
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const omgHaiku = "omg the frog\njumped into the pond again\nlmaooo brb"
+
+func TestElongations(t *testing.T) {
+	assert.Nil(t, elongations("HELLO"))
+	assert.Equal(t, []string{"GOOD", "GOD"}, elongations("GOOOOD"))
+	assert.Equal(t, []string{"LMAOO", "LMAO"}, elongations("LMAOOO"))
+	assert.Nil(t, elongations("AAABBBCCCDDDEEE"), "too many runs")
+}
+
+func TestAnalyse_Interjections(t *testing.T) {
+	c := checker{forms: []Form{FormHaiku}, interjections: true}
+	a := c.analyse(omgHaiku)
+	assert.NoError(t, a.Err())
+	assert.Equal(t, []int{3}, a.Lines[0].Words[0].Syllables)
+	assert.Equal(t, SourceInterjection, a.Lines[0].Words[0].Source)
+	assert.Equal(t, SourceInterjection, a.Lines[2].Words[0].Source, "elongated interjections are counted")
+
+	assert.Error(t, analyse(omgHaiku, FormHaiku).Err())
+}
+
+func TestHandleMessage_CountInterjections(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	otherGID, otherCID := h.addGuild("1")
+	h.setGuildFlags(gid, db.ConfigReactToHaiku|db.ConfigCountInterjections)
+	h.setGuildFlags(otherGID, db.ConfigReactToHaiku)
+
+	omgID := h.post(gid, cid, "2", omgHaiku)
+	assert.Equal(t, []string{"💯"}, h.reactions(omgID))
+
+	otherID := h.post(otherGID, otherCID, "2", omgHaiku)
+	assert.Empty(t, h.reactions(otherID), "interjections are only counted where enabled")
+}
@@ -0,0 +1,142 @@
+package haikuhammer
+
+import (
+	"context"
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/dict"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Language is a dict.Language which also reports how it counted each word, so explanations can point out numbers,
+// guesses and the like.
+type Language interface {
+	dict.Language
+	// CountWord counts the syllables in a word like CountSyllables, along with how they were counted.
+	CountWord(word string) ([]int, Source)
+}
+
+// English reads numbers aloud and counts affixes, compounds, abbreviations and elongated words around the words found
+// by dict.English.
+var English Language = english{dict.English}
+
+type english struct {
+	dict.Language
+}
+
+func (english) CountSyllables(word string) ([]int, bool) {
+	counts, source := countSyllables(word)
+	return counts, source != SourceUnknown
+}
+
+func (english) CountWord(word string) ([]int, Source) {
+	return countSyllables(word)
+}
+
+// Spanish counts words using only the rules of Spanish spelling.
+var Spanish Language = spelled{dict.Spanish}
+
+// spelled is a language whose words are counted only by the rules of its spelling.
+type spelled struct {
+	dict.Language
+}
+
+func (l spelled) CountWord(word string) ([]int, Source) {
+	if cleanWord(word) == "" && !strings.ContainsAny(word, "0123456789") {
+		return []int{0}, SourcePunctuation
+	}
+	counts, ok := l.CountSyllables(word)
+	if !ok {
+		return nil, SourceUnknown
+	}
+	return counts, SourceLanguage
+}
+
+// Languages lists every supported language, starting with the default.
+var Languages = []Language{English, Spanish}
+
+// lang returns the language this checker reads messages in.
+func (c checker) lang() Language {
+	if c.language == nil {
+		return English
+	}
+	return c.language
+}
+
+// phrases returns the sentences used to explain the analysis, in the language the message was read in.
+func (a Analysis) phrases() dict.Phrases {
+	if a.Language == nil {
+		return English.Phrases()
+	}
+	return a.Language.Phrases()
+}
+
+func parseLanguage(s string) (Language, error) {
+	for _, language := range Languages {
+		if strings.EqualFold(s, language.Name()) {
+			return language, nil
+		}
+	}
+	return nil, fmt.Errorf("could not understand '%s' as a language; expected one of %s", s, languageNames())
+}
+
+func languageNames() string {
+	names := make([]string, len(Languages))
+	for i, language := range Languages {
+		names[i] = language.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// language returns the language messages in the channel a message was sent to are written in.
+func (h *HaikuHammer) language(m *Message) Language {
+	guildID, channelID, _, err := idToInt(m)
+	if err != nil {
+		return English
+	}
+	return h.lookupLanguage(guildID, channelID)
+}
+
+func (h *HaikuHammer) lookupLanguage(guildID, channelID int) Language {
+	value, err := h.store.LookupSetting(context.Background(), guildID, channelID, db.SettingLanguage)
+	if err != nil {
+		log.Println("could not retrieve language for guildID:", guildID, "channelID:", channelID, err)
+		return English
+	}
+	language, err := parseLanguage(value)
+	if err != nil {
+		return English
+	}
+	return language
+}
+
+// updateLanguage sets the language for the command target, or reports the current language if the command doesn't
+// set one.
+func (h *HaikuHammer) updateLanguage(guildID string, command Command) string {
+	failure := fmt.Sprintf("I couldn't update the language for target %s, please try again later", command.MentionTarget())
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return failure
+	}
+	scope, targetID, cid := db.ScopeGuild, gid, 0
+	if command.Target != "global" {
+		cid, err = strconv.Atoi(command.Target)
+		if err != nil {
+			log.Println("could not parse channelID as integer,", command.Target)
+			return failure
+		}
+		scope, targetID = db.ScopeChannel, cid
+	}
+	if command.Language == nil {
+		return fmt.Sprintf("Messages in target %s are read as %s", command.MentionTarget(), h.lookupLanguage(gid, cid).Name())
+	}
+	err = h.store.SaveSetting(context.Background(), db.Setting{Scope: scope, TargetID: targetID, Name: db.SettingLanguage, Value: command.Language.Name()})
+	if err != nil {
+		log.Println("could not update language,", err)
+		return failure
+	}
+	return fmt.Sprintf("Messages in target %s will be read as %s", command.MentionTarget(), command.Language.Name())
+}
@@ -0,0 +1,106 @@
+package haikuhammer
+
+import (
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const ranaHaiku = "La rana salta\nen el viejo estanque\nsuena el agua"
+
+func TestAnalyse_Language(t *testing.T) {
+	assert.Error(t, analyse(ranaHaiku, FormHaiku).Err())
+
+	c := checker{forms: []Form{FormHaiku}, language: Spanish, estimates: EstimateAccept}
+	a := c.analyse(ranaHaiku)
+	assert.NoError(t, a.Err())
+	assert.Equal(t, SourceLanguage, a.Lines[0].Words[1].Source)
+
+	a = c.analyse("La rana salta\nen el viejo estanque\nsuena el agua, 5")
+	assert.EqualError(t, a.Err(), "Mmm, esto no parece un Haiku tradicional en español; te explico por qué:\n"+
+		"- No conozco las palabras: 5\n"+
+		"- Conté una estructura de sílabas de 5/7/0, pero esperaba 5/7/5")
+
+	a = c.analyse("La rana salta\nen el viejo estanque\nsuena el agua fría")
+	assert.EqualError(t, a.Err(), "Mmm, esto no parece un Haiku tradicional en español; te explico por qué:\n"+
+		"- Conté una estructura de sílabas de 5/7/7, pero esperaba 5/7/5")
+
+	c.forms = []Form{FormHaiku, FormCinquain}
+	a = c.analyse("La rana salta\nen el viejo estanque")
+	assert.EqualError(t, a.Err(), "Esto no me parece ningún poema que conozca; no tiene 3 o 5 versos.")
+}
+
+func TestEnglish_CountSyllables(t *testing.T) {
+	counts, ok := English.CountSyllables("25")
+	assert.True(t, ok)
+	assert.Equal(t, []int{3}, counts, "English counts numbers as they're read aloud")
+
+	counts, source := English.CountWord("frog")
+	assert.Equal(t, []int{1}, counts)
+	assert.Equal(t, SourceDictionary, source)
+
+	_, ok = English.CountSyllables("zxqv")
+	assert.False(t, ok)
+}
+
+func TestParseCommand_Language(t *testing.T) {
+	command, err := parseCommand("language <#123> Spanish")
+	assert.NoError(t, err)
+	assert.Equal(t, Command{Operation: OpLanguage, Target: "123", Language: Spanish}, command)
+
+	command, err = parseCommand("language")
+	assert.NoError(t, err)
+	assert.Equal(t, Command{Operation: OpLanguage, Target: "global"}, command)
+
+	_, err = parseCommand("language global klingon")
+	assert.EqualError(t, err, "could not understand 'klingon' as a language; expected one of english, spanish")
+}
+
+func TestLanguage(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	otherCID := h.addChannel(gid)
+	h.setGuildFlags(gid, db.ConfigReactToHaiku)
+
+	ranaID := h.post(gid, cid, "2", ranaHaiku)
+	assert.Empty(t, h.reactions(ranaID), "messages are read as English by default")
+
+	setID := h.post(gid, cid, "1", "!haiku language "+channelMention(cid)+" spanish")
+	assert.Equal(t, []string{"Messages in target " + channelMention(cid) + " will be read as spanish"}, h.replies(setID))
+
+	ranaID = h.post(gid, cid, "2", ranaHaiku)
+	assert.Equal(t, []string{"💯"}, h.reactions(ranaID))
+	pondID := h.post(gid, cid, "2", "An old silent pond\nA frog jumps into the pond\nSplash! Silence again")
+	assert.Empty(t, h.reactions(pondID), "English isn't read with Spanish rules")
+
+	ranaID = h.post(gid, otherCID, "2", ranaHaiku)
+	assert.Empty(t, h.reactions(ranaID), "other channels are still read as English")
+
+	resp := h.command(gid, cid, "1", subcommand("language", stringOption("language", "spanish")))
+	assert.Equal(t, "Messages in target global will be read as spanish", resp.Data.Content)
+	ranaID = h.post(gid, otherCID, "2", ranaHaiku)
+	assert.Equal(t, []string{"💯"}, h.reactions(ranaID))
+
+	resp = h.command(gid, cid, "1", subcommand("language", channelOption(otherCID)))
+	assert.Equal(t, "Messages in target "+channelMention(otherCID)+" are read as spanish", resp.Data.Content)
+}
+
+func TestLanguage_Phrases(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.setGuildFlags(gid, db.ConfigExplainNonHaiku)
+	h.post(gid, cid, "1", "!haiku language global spanish")
+
+	unknownID := h.post(gid, cid, "2", "La rana salta\nen el viejo zxqv\nsuena el agua")
+	assert.Equal(t, []string{"Mmm, esto no parece un Haiku tradicional en español; te explico por qué:\n" +
+		"- No conozco las palabras: zxqv\n" +
+		"- Conté una estructura de sílabas de 5/0/5, pero esperaba 5/7/5\n" +
+		"Si sabes cuántas sílabas tiene una palabra, puedes enseñármelo con `/haiku teach`."}, h.replies(unknownID))
+
+	twoLinesID := h.post(gid, cid, "2", "La rana salta\nen el viejo estanque")
+	assert.Equal(t, []string{"Esto no me parece un haiku tradicional; no tiene tres versos."}, h.replies(twoLinesID))
+
+	h.setGuildFlags(gid, db.ConfigDeleteNonHaiku)
+	h.post(gid, cid, "2", "La rana salta")
+	assert.Equal(t, []string{"Borré el mensaje que acabas de enviar a " + channelMention(cid) + " porque no me pareció un Haiku de verdad:\n > La rana salta"}, h.dms("2"))
+}
@@ -0,0 +1,57 @@
+package haikuhammer
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	codeBlockRegex   = regexp.MustCompile("(?s)```(?:[\\w+#-]*\n)?(.*?)```")
+	inlineCodeRegex  = regexp.MustCompile("`+([^`]*)`+")
+	mentionRegex     = regexp.MustCompile(`<(@!?|@&|#)(\d+)>`)
+	commandRegex     = regexp.MustCompile(`</([\w -]+):\d+>`)
+	timestampRegex   = regexp.MustCompile(`<t:-?\d+(?::[tTdDfFR])?>`)
+	customEmojiRegex = regexp.MustCompile(`<a?:\w+:\d+>`)
+	emojiRegex       = regexp.MustCompile(`:[\w+-]*[a-zA-Z][\w+-]*:`)
+	maskedLinkRegex  = regexp.MustCompile(`\[([^\]]*)\]\(<?https?://[^)\s]*>?\)`)
+	urlRegex         = regexp.MustCompile(`<?https?://[^\s>]+>?`)
+	blockRegex       = regexp.MustCompile(`^(?:#{1,3} |-# |>>> |> ?|[-*] |\d+\. )`)
+)
+
+// emphasis removes the markers Discord uses for bold, italics, underline, strikethrough and spoilers, keeping the
+// text inside them. Escaped markers are kept as plain text.
+var emphasis = strings.NewReplacer(`\*`, "*", `\_`, "_", `\~`, "~", `\|`, "|", "**", "", "__", "", "~~", "", "||", "",
+	"*", "", "_", "")
+
+// stripMarkup removes Discord markup from a message so that only the words someone would read aloud are left.
+// Mentions are replaced with the names found in mentions, keyed by the markup for the mention as it appears in a
+// message, e.g. "<@123>"; mentions without a name are removed, along with emoji, links, timestamps and formatting.
+// Code is kept as plain text, and lines which held nothing but markup are dropped.
+func stripMarkup(content string, mentions map[string]string) string {
+	content = codeBlockRegex.ReplaceAllString(content, "$1")
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		stripped := stripLine(line, mentions)
+		if stripped == "" && strings.TrimSpace(line) != "" {
+			continue
+		}
+		lines = append(lines, stripped)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func stripLine(line string, mentions map[string]string) string {
+	line = inlineCodeRegex.ReplaceAllString(line, "$1")
+	line = mentionRegex.ReplaceAllStringFunc(line, func(mention string) string {
+		return mentions[strings.Replace(mention, "<@!", "<@", 1)]
+	})
+	line = commandRegex.ReplaceAllString(line, "$1")
+	line = timestampRegex.ReplaceAllString(line, "")
+	line = customEmojiRegex.ReplaceAllString(line, "")
+	line = emojiRegex.ReplaceAllString(line, "")
+	line = maskedLinkRegex.ReplaceAllString(line, "$1")
+	line = urlRegex.ReplaceAllString(line, "")
+	line = blockRegex.ReplaceAllString(strings.TrimSpace(line), "")
+	line = emphasis.Replace(line)
+	return strings.Join(strings.Fields(line), " ")
+}
@@ -0,0 +1,94 @@
+package haikuhammer
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStripMarkup(t *testing.T) {
+	mentions := map[string]string{"<@123>": "Basho", "<@&456>": "Poets", "<#789>": "haiku-corner"}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"An old silent pond", "An old silent pond"},
+		{"Note: the frog jumps in :wink:", "Note: the frog jumps in"}, // the old emoji regex removed everything from "Note"
+		{"10:30 and the pond is still :frog: :pond:", "10:30 and the pond is still"},
+		{"hey <@123> look at this", "hey Basho look at this"},
+		{"hey <@!123> look at this", "hey Basho look at this"},
+		{"calling all <@&456>", "calling all Poets"},
+		{"over in <#789> now", "over in haiku-corner now"},
+		{"who is <@555>?", "who is ?"},
+		{"so good <:pepeHands:123456789012345678> <a:party:42>", "so good"},
+		{"see https://example.com/frog?pond=1 for more", "see for more"},
+		{"see <https://example.com/frog> for more", "see for more"},
+		{"read [the old pond](https://example.com/basho) today", "read the old pond today"},
+		{"the **old** *silent* __pond__", "the old silent pond"},
+		{"~~a frog~~ a toad jumps in", "a frog a toad jumps in"},
+		{"splash! ||silence again||", "splash! silence again"},
+		{"try `/haiku teach` out", "try /haiku teach out"},
+		{"use </haiku teach:123> to help", "use haiku teach to help"},
+		{"meet me <t:1700000000:R>", "meet me"},
+		{"# An old silent pond", "An old silent pond"},
+		{"-# a frog jumps in", "a frog jumps in"},
+		{"> splash! silence again", "splash! silence again"},
+		{"- first\n- second", "first\nsecond"},
+		{`5 \* 5 is twenty-five`, "5 * 5 is twenty-five"},
+		{"An old silent pond\nA frog jumps into the pond\nSplash! Silence again\n:frog:", "An old silent pond\nA frog jumps into the pond\nSplash! Silence again"},
+		{"```\nAn old silent pond\nA frog jumps into the pond\nSplash! Silence again\n```", "An old silent pond\nA frog jumps into the pond\nSplash! Silence again"},
+		{"```haiku\nAn old silent pond\n```", "An old silent pond"},
+		{"first stanza\n\nsecond stanza", "first stanza\n\nsecond stanza"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, stripMarkup(tt.input, mentions), tt.input)
+	}
+}
+
+func TestAnalyse_Markup(t *testing.T) {
+	tests := []string{
+		"An old silent pond :frog:\nA frog jumps into the pond\nSplash! Silence again",
+		"**An old silent pond**\n*A frog jumps into the pond*\n||Splash! Silence again||",
+		"> An old silent pond\n> A frog jumps into the pond\n> Splash! Silence again",
+		"```\nAn old silent pond\nA frog jumps into the pond\nSplash! Silence again\n```",
+		"An old silent pond <:pond:123456789>\nA frog jumps into the pond\nSplash! Silence again https://example.com/splash",
+		"An old silent pond: <a:frog:42>\nA frog jumps into the pond\nSplash! Silence again :sweat_drops:",
+	}
+	for _, text := range tests {
+		assert.NoError(t, analyse(text, FormHaiku).Err(), text)
+	}
+}
+
+func TestHandleMessage_Mentions(t *testing.T) {
+	h := newHarness(t)
+	guildID, channelID := h.addGuild("1")
+	h.addMember(guildID, "2", "")
+	h.addMember(guildID, "3", "Frog")
+	h.session.channels[channelID].Name = "pond"
+	h.setGuildFlags(guildID, db.ConfigReactToHaiku|db.ConfigReactToNonHaiku)
+
+	// "user3" would be two syllables, but the member's nickname is read instead
+	mid := h.post(guildID, channelID, "2", "<@3> jumps in the <#"+channelID+">\nAn old silent pond is still\nSplash! Silence again", "3")
+	assert.Equal(t, []string{"💯"}, h.reactions(mid))
+
+	mid = h.post(guildID, channelID, "2", "<@!3> <@3> <@3> <@3>\nA frog jumps into the pond\nSplash! Silence again", "3")
+	assert.Equal(t, []string{"🚫"}, h.reactions(mid))
+}
+
+func TestHandleMessage_MentionsCached(t *testing.T) {
+	h := newHarness(t)
+	guildID, channelID := h.addGuild("1")
+	h.setGuildFlags(guildID, db.ConfigReactToHaiku|db.ConfigReactToNonHaiku)
+
+	// names only known to the gateway's cache are read without asking the REST API
+	h.discord.state = discordgo.NewState()
+	require.NoError(t, h.discord.state.GuildAdd(&discordgo.Guild{ID: guildID}))
+	require.NoError(t, h.discord.state.MemberAdd(&discordgo.Member{GuildID: guildID, Nick: "Frog", User: &discordgo.User{ID: "3"}}))
+	require.NoError(t, h.discord.state.RoleAdd(guildID, &discordgo.Role{ID: "4", Name: "pond"}))
+	require.NoError(t, h.discord.state.ChannelAdd(&discordgo.Channel{ID: "5", GuildID: guildID, Name: "splash"}))
+
+	mid := h.post(guildID, channelID, "2", "Rain taps the window\n<@!3> sings to the <@&4> all night\n<#5> goes the kettle", "3")
+	assert.Equal(t, []string{"💯"}, h.reactions(mid))
+}
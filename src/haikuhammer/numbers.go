@@ -0,0 +1,227 @@
+package haikuhammer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ones = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten", "eleven",
+		"twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	tens = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+
+	// ordinals maps the last word of a cardinal number to its ordinal form, for words which don't just add "th".
+	ordinals = map[string]string{"one": "first", "two": "second", "three": "third", "five": "fifth", "eight": "eighth",
+		"nine": "ninth", "twelve": "twelfth", "twenty": "twentieth", "thirty": "thirtieth", "forty": "fortieth",
+		"fifty": "fiftieth", "sixty": "sixtieth", "seventy": "seventieth", "eighty": "eightieth", "ninety": "ninetieth"}
+
+	scales = []struct {
+		value int64
+		name  string
+	}{
+		{1_000_000_000, "billion"},
+		{1_000_000, "million"},
+		{1000, "thousand"},
+		{100, "hundred"},
+	}
+)
+
+// currency names the units of each currency symbol, singular then plural, followed by the units of its hundredths.
+var currency = map[string][4]string{
+	"$": {"dollar", "dollars", "cent", "cents"},
+	"£": {"pound", "pounds", "penny", "pence"},
+	"€": {"euro", "euros", "cent", "cents"},
+}
+
+// units names common units of measurement written after a number, singular then plural.
+var units = map[string][2]string{
+	"km":  {"kilometer", "kilometers"},
+	"m":   {"meter", "meters"},
+	"cm":  {"centimeter", "centimeters"},
+	"mm":  {"millimeter", "millimeters"},
+	"mi":  {"mile", "miles"},
+	"ft":  {"foot", "feet"},
+	"kg":  {"kilogram", "kilograms"},
+	"g":   {"gram", "grams"},
+	"lb":  {"pound", "pounds"},
+	"lbs": {"pound", "pounds"},
+	"mph": {"mile per hour", "miles per hour"},
+	"hr":  {"hour", "hours"},
+	"hrs": {"hour", "hours"},
+	"min": {"minute", "minutes"},
+	"°":   {"degree", "degrees"},
+	"°c":  {"degree celsius", "degrees celsius"},
+	"°f":  {"degree fahrenheit", "degrees fahrenheit"},
+	"am":  {"a m", "a m"},
+	"pm":  {"p m", "p m"},
+}
+
+// maxNumber is larger than any number readNumber will spell out.
+const maxNumber = 1_000_000_000_000
+
+var (
+	currencyRegex = regexp.MustCompile(`^([$£€])(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d\d))?$`)
+	timeRegex     = regexp.MustCompile(`^(\d{1,2}):(\d\d)(am|pm)?$`)
+	ordinalRegex  = regexp.MustCompile(`^(\d+)(st|nd|rd|th)$`)
+	numberRegex   = regexp.MustCompile(`^(-?)(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d+))?(%|[a-z°]+)?$`)
+)
+
+// readNumber spells out a word containing a number the way it would be read aloud, returning false if the word isn't
+// a number. Digits, ordinals like 3rd, years like 1999, times like 10:30, percentages, currency and simple units like
+// 5km are understood.
+func readNumber(word string) (string, bool) {
+	word = strings.ToLower(strings.Trim(word, `"'“”‘’.,;:!?()[]`))
+	if word == "" || !strings.ContainsAny(word, "0123456789") {
+		return "", false
+	}
+	if m := currencyRegex.FindStringSubmatch(word); m != nil {
+		return readCurrency(m[1], m[2], m[3])
+	}
+	if m := timeRegex.FindStringSubmatch(word); m != nil {
+		return readTime(m[1], m[2], m[3])
+	}
+	if m := ordinalRegex.FindStringSubmatch(word); m != nil {
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || n >= maxNumber {
+			return "", false
+		}
+		return ordinal(n), true
+	}
+	m := numberRegex.FindStringSubmatch(word)
+	if m == nil {
+		return "", false
+	}
+	sign, digits, fraction, unit := m[1], m[2], m[3], m[4]
+	n, err := strconv.ParseInt(strings.ReplaceAll(digits, ",", ""), 10, 64)
+	if err != nil || n >= maxNumber {
+		return "", false
+	}
+	var spoken string
+	if isYear(digits, sign+fraction+unit) {
+		spoken = year(n)
+	} else {
+		spoken = cardinal(n)
+	}
+	if fraction != "" {
+		spoken += " point"
+		for _, d := range fraction {
+			spoken += " " + ones[d-'0']
+		}
+	}
+	if sign != "" {
+		spoken = "minus " + spoken
+	}
+	switch {
+	case unit == "%":
+		spoken += " percent"
+	case unit != "":
+		names, ok := units[unit]
+		if !ok {
+			return "", false
+		}
+		spoken += " " + plural(names, n == 1 && fraction == "")
+	}
+	return spoken, true
+}
+
+func readCurrency(symbol, digits, hundredths string) (string, bool) {
+	n, err := strconv.ParseInt(strings.ReplaceAll(digits, ",", ""), 10, 64)
+	if err != nil || n >= maxNumber {
+		return "", false
+	}
+	names := currency[symbol]
+	spoken := cardinal(n) + " " + plural([2]string{names[0], names[1]}, n == 1)
+	if cents, _ := strconv.Atoi(hundredths); cents > 0 {
+		spoken += " and " + cardinal(int64(cents)) + " " + plural([2]string{names[2], names[3]}, cents == 1)
+	}
+	return spoken, true
+}
+
+func readTime(hours, minutes, suffix string) (string, bool) {
+	h, _ := strconv.Atoi(hours)
+	min, _ := strconv.Atoi(minutes)
+	if h > 23 || min > 59 {
+		return "", false
+	}
+	spoken := cardinal(int64(h))
+	switch {
+	case min == 0 && suffix == "":
+		spoken += " o'clock"
+	case min == 0:
+	case min < 10:
+		spoken += " oh " + ones[min]
+	default:
+		spoken += " " + cardinal(int64(min))
+	}
+	if suffix != "" {
+		spoken += " " + units[suffix][0]
+	}
+	return spoken, true
+}
+
+// isYear reports whether a number is probably a year, which is read in pairs of digits like "nineteen ninety-nine".
+// Only four digit numbers between 1100 and 2099 written without commas, signs, decimals or units are read as years.
+func isYear(digits, decoration string) bool {
+	if len(digits) != 4 || decoration != "" {
+		return false
+	}
+	n, _ := strconv.Atoi(digits)
+	return n >= 1100 && n < 2100
+}
+
+func year(n int64) string {
+	century, rest := n/100, n%100
+	switch {
+	case n >= 2000 && n < 2010:
+		return cardinal(n) // two thousand five
+	case rest == 0:
+		return cardinal(century) + " hundred"
+	case rest < 10:
+		return cardinal(century) + " oh " + ones[rest]
+	default:
+		return cardinal(century) + " " + cardinal(rest)
+	}
+}
+
+// cardinal spells out a non-negative number, e.g. 1234 is "one thousand two hundred thirty-four".
+func cardinal(n int64) string {
+	if n < 20 {
+		return ones[n]
+	}
+	if n < 100 {
+		if n%10 == 0 {
+			return tens[n/10]
+		}
+		return tens[n/10] + "-" + ones[n%10]
+	}
+	for _, scale := range scales {
+		if n < scale.value {
+			continue
+		}
+		spoken := cardinal(n/scale.value) + " " + scale.name
+		if n%scale.value != 0 {
+			spoken += " " + cardinal(n%scale.value)
+		}
+		return spoken
+	}
+	return "" // unreachable
+}
+
+// ordinal spells out the position of a number in a sequence, e.g. 21 is "twenty-first".
+func ordinal(n int64) string {
+	spoken := cardinal(n)
+	i := strings.LastIndexAny(spoken, " -") + 1
+	last := spoken[i:]
+	if word, ok := ordinals[last]; ok {
+		return spoken[:i] + word
+	}
+	return spoken + "th"
+}
+
+func plural(names [2]string, singular bool) string {
+	if singular {
+		return names[0]
+	}
+	return names[1]
+}
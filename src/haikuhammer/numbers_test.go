@@ -0,0 +1,85 @@
+package haikuhammer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReadNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"7", "seven"},
+		{"42,", "forty-two"},
+		{"1,000,001", "one million one"},
+		{"115", "one hundred fifteen"},
+		{"3rd", "third"},
+		{"21st", "twenty-first"},
+		{"100th", "one hundredth"},
+		{"1999", "nineteen ninety-nine"},
+		{"1905", "nineteen oh five"},
+		{"1900", "nineteen hundred"},
+		{"2005", "two thousand five"},
+		{"2024.", "twenty twenty-four"},
+		{"3000", "three thousand"},
+		{"10:30", "ten thirty"},
+		{"7:05pm", "seven oh five p m"},
+		{"12:00", "twelve o'clock"},
+		{"50%", "fifty percent"},
+		{"$20", "twenty dollars"},
+		{"$1.05", "one dollar and five cents"},
+		{"£3.50", "three pounds and fifty pence"},
+		{"5km", "five kilometers"},
+		{"1kg", "one kilogram"},
+		{"-3.14", "minus three point one four"},
+		{"20°c", "twenty degrees celsius"},
+	}
+	for _, tt := range tests {
+		spoken, ok := readNumber(tt.input)
+		assert.True(t, ok, tt.input)
+		assert.Equal(t, tt.expected, spoken, tt.input)
+	}
+
+	for _, bad := range []string{"hello", "25:00", "5xyz", "1.2.3", "9-5", "$"} {
+		_, ok := readNumber(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestCountSyllables_Numbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []int
+	}{
+		{"7", []int{2}},
+		{"1999", []int{5}},
+		{"21st", []int{3}},
+		{"10:30", []int{3}},
+		{"$20", []int{4}},
+		{"50%", []int{4}},
+		{"5km", []int{5}},
+	}
+	for _, tt := range tests {
+		counts, source := countSyllables(tt.input)
+		assert.Equal(t, tt.expected, counts, tt.input)
+		assert.Equal(t, SourceNumber, source, tt.input)
+	}
+
+	for _, unreadable := range []string{"25:00", "1/2", "#1", "9999999999999", "frog2"} {
+		counts, source := countSyllables(unreadable)
+		assert.Nil(t, counts, unreadable)
+		assert.Equal(t, SourceUnknown, source, "numbers which can't be read aloud aren't dropped: %s", unreadable)
+	}
+}
+
+func TestAnalyse_Numbers(t *testing.T) {
+	a := analyse("1999\nwe danced like the world would end\nor not, who can say", FormHaiku)
+	assert.NoError(t, a.Err())
+	assert.Equal(t, "nineteen ninety-nine", a.Lines[0].Words[0].Spoken)
+
+	a = analyse("An old silent pond\nA frog jumps into the pond 2\nSplash! Silence again", FormHaiku)
+	assert.EqualError(t, a.Err(), "Hmmm, this doesn't seem like a traditional English Haiku; here's why:\n"+
+		"- I read 2 as \"two\"\n"+
+		"- I counted a syllable structure of 5/8/5, but I expected 5/7/5")
+}
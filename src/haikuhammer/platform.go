@@ -0,0 +1,59 @@
+package haikuhammer
+
+import "time"
+
+// ChatPlatform is the set of operations HaikuHammer needs from the chat service it moderates. The haiku rules in
+// HaikuHammer only ever talk to a ChatPlatform, so they run identically on any service with an implementation.
+type ChatPlatform interface {
+	// FetchMessage looks up the latest version of a message, including any reaction left by the bot.
+	FetchMessage(channelID, messageID string) (*Message, error)
+	// React adds an emoji reaction from the bot to a message.
+	React(channelID, messageID, emoji string) error
+	// Unreact removes an emoji reaction the bot previously added to a message.
+	Unreact(channelID, messageID, emoji string) error
+	// Delete removes a message from a channel.
+	Delete(channelID, messageID string) error
+	// Reply publicly responds to a message in the channel it was sent to.
+	Reply(channelID, messageID, content string) error
+	// Send posts a new message to a channel.
+	Send(channelID, content string) error
+	// DM sends a direct message to a user.
+	DM(userID, content string) error
+	// MemberNick returns the name a user is displayed under in a guild.
+	MemberNick(guildID, userID string) (string, error)
+	// IsAdmin reports whether a user has permissions to manage HaikuHammer in a guild.
+	IsAdmin(guildID, userID string) (bool, error)
+	// IsDM reports whether a channel is a direct message channel between a user and the bot.
+	IsDM(channelID string) (bool, error)
+	// MentionNames returns the names of the users, roles and channels mentioned in a message sent to a guild, keyed by
+	// their markup, e.g. "<@123>". Guild is empty for direct messages.
+	MentionNames(guildID, content string) map[string]string
+}
+
+// Message is a chat message as seen by HaikuHammer.
+type Message struct {
+	ID        string
+	ChannelID string
+	GuildID   string
+
+	AuthorID  string
+	AuthorBot bool
+
+	Content   string
+	Timestamp time.Time
+
+	MentionsBot bool   // true if the bot was mentioned in this message
+	MyReaction  string // the emoji the bot reacted to this message with, or empty if it has not reacted
+}
+
+// Reaction is an emoji reaction added to or removed from a message by a user.
+type Reaction struct {
+	MessageID string
+	ChannelID string
+	GuildID   string
+
+	UserID  string
+	UserBot bool
+
+	Emoji string
+}
@@ -0,0 +1,141 @@
+package haikuhammer
+
+import (
+	"context"
+	"database/sql"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path"
+	"testing"
+)
+
+// fakePlatform is an in-memory ChatPlatform which records every side effect requested by HaikuHammer.
+type fakePlatform struct {
+	messages map[string]*Message
+	admins   map[string]bool
+	dms      map[string]bool
+
+	reactions map[string][]string
+	deleted   []string
+	replies   map[string][]string
+	sentDMs   map[string][]string
+	sent      map[string][]string
+}
+
+func newFakePlatform() *fakePlatform {
+	return &fakePlatform{
+		messages:  make(map[string]*Message),
+		admins:    make(map[string]bool),
+		dms:       make(map[string]bool),
+		reactions: make(map[string][]string),
+		replies:   make(map[string][]string),
+		sentDMs:   make(map[string][]string),
+		sent:      make(map[string][]string),
+	}
+}
+
+func (f *fakePlatform) post(m *Message) *Message {
+	f.messages[m.ID] = m
+	return m
+}
+
+func (f *fakePlatform) FetchMessage(channelID, messageID string) (*Message, error) {
+	m, ok := f.messages[messageID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	result := *m
+	if rs := f.reactions[messageID]; len(rs) > 0 {
+		result.MyReaction = rs[0]
+	}
+	return &result, nil
+}
+
+func (f *fakePlatform) React(channelID, messageID, emoji string) error {
+	f.reactions[messageID] = append(f.reactions[messageID], emoji)
+	return nil
+}
+
+func (f *fakePlatform) Unreact(channelID, messageID, emoji string) error {
+	var kept []string
+	for _, r := range f.reactions[messageID] {
+		if r != emoji {
+			kept = append(kept, r)
+		}
+	}
+	f.reactions[messageID] = kept
+	return nil
+}
+
+func (f *fakePlatform) Delete(channelID, messageID string) error {
+	f.deleted = append(f.deleted, messageID)
+	return nil
+}
+
+func (f *fakePlatform) Reply(channelID, messageID, content string) error {
+	f.replies[messageID] = append(f.replies[messageID], content)
+	return nil
+}
+
+func (f *fakePlatform) Send(channelID, content string) error {
+	f.sent[channelID] = append(f.sent[channelID], content)
+	return nil
+}
+
+func (f *fakePlatform) DM(userID, content string) error {
+	f.sentDMs[userID] = append(f.sentDMs[userID], content)
+	return nil
+}
+
+func (f *fakePlatform) MemberNick(guildID, userID string) (string, error) {
+	return "nick-" + userID, nil
+}
+
+func (f *fakePlatform) IsAdmin(guildID, userID string) (bool, error) {
+	return f.admins[userID], nil
+}
+
+func (f *fakePlatform) IsDM(channelID string) (bool, error) {
+	return f.dms[channelID], nil
+}
+
+func (f *fakePlatform) MentionNames(guildID, content string) map[string]string {
+	return nil
+}
+
+// openTestStore opens a store backed by a fresh SQLite database.
+func openTestStore(t *testing.T) db.Store {
+	DB, err := db.Open(db.SQLite, path.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { DB.Close() })
+	require.NoError(t, db.BootstrapDB(DB))
+	return db.NewSQLStore(DB)
+}
+
+func TestHaikuHammer_FakePlatform(t *testing.T) {
+	store := openTestStore(t)
+	platform := newFakePlatform()
+	h := NewHaikuHammerWithPlatform(Config{
+		ActionFlags:    db.ConfigReactToHaiku | db.ConfigReactToNonHaiku,
+		PositiveReacts: []string{"+"},
+		NegativeReacts: []string{"-"},
+	}, platform, store)
+	err := store.SaveGuildConfig(context.Background(), db.GuildConfig{GuildID: 1, Flags: db.ConfigReactToHaiku | db.ConfigReactToNonHaiku})
+	require.NoError(t, err)
+
+	h.ReceiveMessage(platform.post(&Message{ID: "10", ChannelID: "2", GuildID: "1", AuthorID: "3",
+		Content: "Another test case\nFor the automated bot\nHere’s a nice haiku"}))
+	h.ReceiveMessage(platform.post(&Message{ID: "11", ChannelID: "2", GuildID: "1", AuthorID: "3",
+		Content: "not a haiku"}))
+	h.ReceiveMessage(platform.post(&Message{ID: "12", ChannelID: "2", GuildID: "1", AuthorID: "4", AuthorBot: true,
+		Content: "not a haiku"}))
+
+	assert.Equal(t, []string{"+"}, platform.reactions["10"])
+	assert.Equal(t, []string{"-"}, platform.reactions["11"])
+	assert.Empty(t, platform.reactions["12"])
+
+	haiku, err := store.FindHaiku(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "3", haiku.AuthorID)
+}
@@ -0,0 +1,91 @@
+package haikuhammer
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"log"
+	"strings"
+)
+
+//go:embed data/quotations.txt
+var quotationsFile string
+
+// Quotation is a famous haiku, which is recognised when it's posted instead of being taken as someone's own work.
+type Quotation struct {
+	Text        string
+	Author      string
+	Fingerprint uint64 // see Fingerprint
+}
+
+// quotations are the bundled haiku, which are recognised by their fingerprints the same way copies of earlier haiku
+// are, so they're found however they're punctuated, capitalised or broken into lines, and with a word or two changed.
+var quotations []Quotation
+
+func init() {
+	var err error
+	quotations, err = parseQuotations(quotationsFile)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// parseQuotations reads haiku written one after another, each followed by a line naming its author as "- Author".
+// Lines starting with # are ignored.
+func parseQuotations(file string) ([]Quotation, error) {
+	var (
+		result []Quotation
+		lines  []string
+	)
+	for i, line := range strings.Split(file, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			if len(lines) > 0 {
+				return nil, fmt.Errorf("line %d: haiku has no author: %s", i+1, strings.Join(lines, " / "))
+			}
+		case strings.HasPrefix(line, "- "):
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("line %d: author has no haiku", i+1)
+			}
+			text := strings.Join(lines, "\n")
+			result = append(result, Quotation{Text: text, Author: strings.TrimPrefix(line, "- "), Fingerprint: Fingerprint(text)})
+			lines = nil
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > 0 {
+		return nil, fmt.Errorf("haiku has no author: %s", strings.Join(lines, " / "))
+	}
+	return result, nil
+}
+
+// findQuotation finds the bundled haiku which a message looks most like, if it's close enough to count as a copy.
+func findQuotation(content string) (Quotation, bool) {
+	fingerprint := Fingerprint(content)
+	var (
+		best     Quotation
+		bestDist = copyThreshold + 1
+	)
+	for _, q := range quotations {
+		if dist := fingerprintDistance(fingerprint, q.Fingerprint); dist < bestDist {
+			best, bestDist = q, dist
+		}
+	}
+	return best, bestDist <= copyThreshold
+}
+
+// HandleQuotation credits the author of a famous haiku which was posted, rather than treating it as the poster's own.
+// Quotations aren't saved, so they're left out of the guild's collection, stats and awards.
+func (h *HaikuHammer) HandleQuotation(m *Message, q Quotation) {
+	log.Printf("received a quotation of %s: %s\n", q.Author, strings.ReplaceAll(m.Content, "\n", "\\n"))
+	if h.actionsEnabled(m, db.ConfigReactToHaiku) && m.MyReaction == "" {
+		reacts := h.config.QuotationReacts
+		if len(reacts) == 0 {
+			reacts = h.config.PositiveReacts
+		}
+		h.react(m, randomString(reacts))
+		h.reply(m, fmt.Sprintf("I know this one! It's a haiku by %s, so I won't add it to this server's collection.", q.Author))
+	}
+}
@@ -0,0 +1,70 @@
+package haikuhammer
+
+import (
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const bashoHaiku = "An old silent pond\nA frog jumps into the pond\nSplash! Silence again"
+
+func TestParseQuotations(t *testing.T) {
+	parsed, err := parseQuotations("# famous haiku\n\nfirst line\nsecond line\n- Someone\n\nanother line\n - Someone Else \n")
+	assert.NoError(t, err)
+	assert.Equal(t, []Quotation{
+		{Text: "first line\nsecond line", Author: "Someone", Fingerprint: Fingerprint("first line second line")},
+		{Text: "another line", Author: "Someone Else", Fingerprint: Fingerprint("another line")},
+	}, parsed)
+
+	_, err = parseQuotations("first line\n\n- Someone")
+	assert.EqualError(t, err, "line 2: haiku has no author: first line")
+	_, err = parseQuotations("- Someone")
+	assert.EqualError(t, err, "line 1: author has no haiku")
+	_, err = parseQuotations("first line")
+	assert.EqualError(t, err, "haiku has no author: first line")
+}
+
+func TestFindQuotation(t *testing.T) {
+	quoted := []string{
+		bashoHaiku,
+		"an old silent pond / a frog jumps into the pond / splash! silence again",
+		"> *An old silent pond*\n> A frog jumps into the pond\n> Splash—silence again",
+		"An old quiet pond\nA frog jumps into the pond\nSplash! Silence again", // a word changed
+		"An old silent pond\nA frog jumps into the zxqv\nSplash! Silence again",
+		"Haikus are easy\nBut sometimes they don’t make sense\nRefrigerator",
+	}
+	for _, text := range quoted {
+		q, ok := findQuotation(text)
+		assert.True(t, ok, text)
+		assert.NotEmpty(t, q.Author, text)
+	}
+	q, _ := findQuotation(bashoHaiku)
+	assert.Equal(t, Quotation{Text: bashoHaiku, Author: "Matsuo Bashō", Fingerprint: Fingerprint(bashoHaiku)}, q)
+
+	for _, text := range []string{testHaiku, otherHaiku, zxqvHaiku, "An old silent pond\nA frog jumps into the pond"} {
+		_, ok := findQuotation(text)
+		assert.False(t, ok, text)
+	}
+}
+
+func TestHandleQuotation(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.setGuildFlags(gid, db.ConfigReactToHaiku)
+
+	quoteID := h.post(gid, cid, "2", bashoHaiku)
+	assert.Equal(t, []string{"📜"}, h.reactions(quoteID))
+	assert.Equal(t, []string{"I know this one! It's a haiku by Matsuo Bashō, so I won't add it to this server's collection."},
+		h.replies(quoteID))
+	assert.Empty(t, h.savedHaiku(quoteID).Content)
+
+	h.post(gid, cid, "2", "Yesterday it worked\nToday it is not working\nWindows is like that")
+	statsID := h.post(gid, cid, "2", "!haiku stats")
+	assert.Equal(t, []string{"Nobody has written a haiku here yet"}, h.replies(statsID), "quotations don't count towards stats")
+
+	quietGID, quietCID := h.addGuild("1")
+	quietID := h.post(quietGID, quietCID, "2", bashoHaiku)
+	assert.Empty(t, h.reactions(quietID))
+	assert.Empty(t, h.replies(quietID))
+	assert.Empty(t, h.savedHaiku(quietID).Content)
+}
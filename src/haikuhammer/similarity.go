@@ -0,0 +1,124 @@
+package haikuhammer
+
+import (
+	"context"
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/dict"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"hash/fnv"
+	"log"
+	"math/bits"
+	"time"
+)
+
+// copyThreshold is the most bits the fingerprints of two haiku can differ by for one to be counted as a copy of the
+// other. Changing a single word in a haiku typically flips fewer than this, while unrelated haiku differ by about half
+// of the 64 bits.
+const copyThreshold = 12
+
+// Fingerprint returns a SimHash of the words in a haiku, so that haiku which share most of their words and word pairs
+// have fingerprints which differ in only a few bits. Words are compared the way they're looked up in the dictionary,
+// so case, punctuation, markup and line breaks make no difference.
+func Fingerprint(haiku string) uint64 {
+	words := normalisedWords(haiku)
+	features := append([]string(nil), words...)
+	for i := 1; i < len(words); i++ {
+		features = append(features, words[i-1]+" "+words[i])
+	}
+
+	var weights [64]int
+	for _, feature := range features {
+		hash := fnv.New64a()
+		hash.Write([]byte(feature))
+		sum := hash.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var result uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			result |= 1 << bit
+		}
+	}
+	return result
+}
+
+// normalisedWords lists the words in a haiku the way they're looked up in the dictionary, skipping markup.
+func normalisedWords(haiku string) []string {
+	var words []string
+	for _, word := range dict.Tokenize(stripMarkup(haiku, nil)) {
+		if word = cleanWord(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// fingerprintDistance counts the bits which differ between two fingerprints.
+func fingerprintDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// findOriginal finds the earlier haiku in a guild which a haiku looks most like, if it's close enough to count as a
+// copy. Authors are free to rework their own haiku, so those are never counted.
+func (h *HaikuHammer) findOriginal(ctx context.Context, guildID, messageID int, authorID string, fingerprint uint64) (db.Fingerprint, int, bool) {
+	candidates, err := h.store.GuildFingerprints(ctx, guildID)
+	if err != nil {
+		log.Println("could not look up haiku fingerprints for guildID:", guildID, err)
+		return db.Fingerprint{}, 0, false
+	}
+	var (
+		best     db.Fingerprint
+		bestDist = copyThreshold + 1
+	)
+	for _, candidate := range candidates {
+		if candidate.MessageID == messageID || candidate.AuthorID == authorID {
+			continue
+		}
+		dist := fingerprintDistance(fingerprint, uint64(candidate.Fingerprint))
+		if dist < bestDist || dist == bestDist && candidate.CreatedAt < best.CreatedAt {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best, bestDist, bestDist <= copyThreshold
+}
+
+// recordCopy remembers that a message copied an earlier haiku, and lets the channel know if configured to.
+func (h *HaikuHammer) recordCopy(ctx context.Context, m *Message, original db.Fingerprint, distance int) {
+	gid, _, mid, err := idToInt(m)
+	if err != nil {
+		return
+	}
+	log.Println("haiku was found to be copied; message_id:", mid, "original message_id:", original.MessageID, "distance:", distance)
+	previous, err := h.store.FindCopy(ctx, mid)
+	if err != nil {
+		log.Println("could not look up haiku copy in database,", err)
+		return
+	}
+	err = h.store.SaveCopy(ctx, db.Copy{GuildID: gid, MessageID: mid, AuthorID: m.AuthorID, OriginalID: original.MessageID,
+		Distance: distance, CreatedAt: h.timestamp(m).Unix()})
+	if err != nil {
+		log.Println("could not save haiku copy to database,", err)
+		return
+	}
+	if previous.OriginalID == original.MessageID {
+		return // already pointed out when the message was first sent
+	}
+	if h.actionsEnabled(m, db.ConfigReplyToCopies) {
+		date := time.Unix(original.CreatedAt, 0).UTC().Format("Jan 2, 2006")
+		h.reply(m, fmt.Sprintf("This looks a lot like %s's haiku from %s", h.nick(gid, original.AuthorID), date))
+	}
+}
+
+// saveFingerprint stores the fingerprint of a saved haiku so later copies of it can be found.
+func (h *HaikuHammer) saveFingerprint(ctx context.Context, mid int, fingerprint uint64) {
+	err := h.store.SaveFingerprint(ctx, mid, int64(fingerprint))
+	if err != nil {
+		log.Println("could not save haiku fingerprint to database,", err)
+	}
+}
@@ -0,0 +1,87 @@
+package haikuhammer
+
+import (
+	"context"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	const pond = "An old silent pond\nA frog jumps into the pond\nSplash! Silence again"
+	same := []string{
+		"an old silent pond / a frog jumps into the pond / splash! silence again",
+		"**An old silent pond**\nA frog jumps into the pond\nSplash! Silence again :frog:",
+		"An old silent pond\nA frog jumps into the pond\nSplash—silence again",
+	}
+	for _, text := range same {
+		assert.Equal(t, Fingerprint(pond), Fingerprint(text), text)
+	}
+
+	copies := []string{
+		"An old quiet pond\nA frog jumps into the pond\nSplash! Silence again",
+		"An old silent lake\nA frog jumps into the lake\nSplash! Silence again",
+		"The silent old pond\nInto the pond a frog jumps\nSilence again splash",
+	}
+	for _, text := range copies {
+		assert.LessOrEqual(t, fingerprintDistance(Fingerprint(pond), Fingerprint(text)), copyThreshold, text)
+	}
+
+	different := []string{
+		testHaiku,
+		"I love my new pond\nthe frog jumps in every day\nsilence never comes",
+		"Over the wintry\nforest, winds howl in rage\nwith no leaves to blow",
+		"A world of dew\nand within every dewdrop\na world of struggle",
+	}
+	for _, text := range different {
+		assert.Greater(t, fingerprintDistance(Fingerprint(pond), Fingerprint(text)), copyThreshold, text)
+	}
+}
+
+func TestHandleHaiku_Copies(t *testing.T) {
+	h := newHarness(t)
+	h.hammer.now = func() time.Time { return time.Date(2021, time.March, 10, 12, 0, 0, 0, time.UTC) }
+	gid, cid := h.addGuild("1")
+	h.addMember(gid, "2", "Basho")
+	h.addMember(gid, "3", "Copycat")
+	h.setGuildFlags(gid, db.ConfigReactToHaiku|db.ConfigReplyToCopies)
+
+	originalID := h.post(gid, cid, "2", otherHaiku)
+	assert.Equal(t, otherHaiku, h.savedHaiku(originalID).Content)
+
+	h.hammer.now = func() time.Time { return time.Date(2021, time.April, 1, 12, 0, 0, 0, time.UTC) }
+	copyID := h.post(gid, cid, "3", "Crisp winter morning\nA cat naps beside the stove\nThe kettle whistles")
+	assert.Equal(t, []string{"💯"}, h.reactions(copyID))
+	assert.Equal(t, []string{"This looks a lot like Basho's haiku from Mar 10, 2021"}, h.replies(copyID))
+	assert.Empty(t, h.savedHaiku(copyID).Content, "copies aren't added to the collection")
+	assert.Equal(t, originalID, strconv.Itoa(h.savedCopy(copyID).OriginalID))
+
+	h.edit(copyID, "Crisp winter morning\nA cat naps beside the stove\nThe kettle whistles!")
+	assert.Len(t, h.replies(copyID), 1, "copies are only pointed out once")
+
+	reworkID := h.post(gid, cid, "2", "Crisp autumn morning\nA dog naps beside the stove\nThe kettle whistles")
+	assert.Empty(t, h.replies(reworkID), "authors may rework their own haiku")
+	assert.NotEmpty(t, h.savedHaiku(reworkID).Content)
+
+	otherID := h.post(gid, cid, "3", "Rain taps on the roof\nthe old dog sleeps by the door\nnobody is home")
+	assert.Empty(t, h.replies(otherID))
+	assert.NotEmpty(t, h.savedHaiku(otherID).Content)
+
+	quietGID, quietCID := h.addGuild("1")
+	h.setGuildFlags(quietGID, db.ConfigReactToHaiku)
+	quietOriginalID := h.post(quietGID, quietCID, "2", "Morning dew settles\non the petals of the rose\nthe sun warms the day")
+	quietCopyID := h.post(quietGID, quietCID, "3", "Morning dew settles\non the petals of the rose\nthe sun warms the earth")
+	assert.Empty(t, h.replies(quietCopyID), "replies are only sent when enabled")
+	assert.Equal(t, quietOriginalID, strconv.Itoa(h.savedCopy(quietCopyID).OriginalID))
+}
+
+func (h *harness) savedCopy(messageID string) db.Copy {
+	mid, err := strconv.Atoi(messageID)
+	require.NoError(h.t, err)
+	c, err := h.store.FindCopy(context.Background(), mid)
+	require.NoError(h.t, err)
+	return c
+}
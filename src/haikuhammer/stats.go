@@ -0,0 +1,108 @@
+package haikuhammer
+
+import (
+	"context"
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stats describes the haiku written in a guild, or by a single author if command.UserID is set.
+func (h *HaikuHammer) stats(guildID string, command Command) string {
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return "I couldn't look up haiku stats, please try again later"
+	}
+	if command.UserID != "" {
+		return h.authorStats(gid, command.UserID)
+	}
+	return h.guildStats(gid)
+}
+
+func (h *HaikuHammer) guildStats(guildID int) string {
+	ctx := context.Background()
+	summary, err := h.store.GuildSummary(ctx, guildID)
+	if err != nil {
+		log.Println("could not retrieve guild stats,", err)
+		return "I couldn't look up haiku stats, please try again later"
+	}
+	if summary.Haiku == 0 {
+		return "Nobody has written a haiku here yet"
+	}
+	top, err := h.store.TopAuthors(ctx, guildID, 5)
+	if err != nil {
+		log.Println("could not retrieve top authors,", err)
+		return "I couldn't look up haiku stats, please try again later"
+	}
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("%d haiku by %s; %s\nTop authors:",
+		summary.Haiku, pluralize(summary.Authors, "author"), acceptanceRate(summary)))
+	for i, author := range top {
+		result.WriteString(fmt.Sprintf("\n%d. %s - %d haiku", i+1, h.nick(guildID, author.AuthorID), author.Haiku))
+	}
+	return result.String()
+}
+
+func (h *HaikuHammer) authorStats(guildID int, authorID string) string {
+	ctx := context.Background()
+	nick := h.nick(guildID, authorID)
+	summary, err := h.store.AuthorSummary(ctx, guildID, authorID)
+	if err != nil {
+		log.Println("could not retrieve author stats,", err)
+		return "I couldn't look up haiku stats, please try again later"
+	}
+	if summary.Haiku == 0 && summary.Attempts == 0 {
+		return fmt.Sprintf("%s hasn't written a haiku here yet", nick)
+	}
+	days, err := h.store.AuthorDays(ctx, guildID, authorID)
+	if err != nil {
+		log.Println("could not retrieve author streaks,", err)
+		return "I couldn't look up haiku stats, please try again later"
+	}
+	current, longest := streaks(days, h.now())
+	return fmt.Sprintf("%s has written %d haiku; %s\nCurrent streak: %s; longest streak: %s",
+		nick, summary.Haiku, acceptanceRate(summary), pluralize(current, "day"), pluralize(longest, "day"))
+}
+
+// nick returns the name a user is displayed under in a guild, or Unknown if it can't be found.
+func (h *HaikuHammer) nick(guildID int, userID string) string {
+	nick, err := h.platform.MemberNick(strconv.Itoa(guildID), userID)
+	if err != nil {
+		log.Println("could not retrieve member nick for guildID:", guildID, "authorID:", userID)
+		return "Unknown"
+	}
+	return nick
+}
+
+func acceptanceRate(s db.Summary) string {
+	total := s.Haiku + s.Attempts
+	if total == 0 {
+		return "no attempts yet"
+	}
+	return fmt.Sprintf("%d%% of attempts accepted", s.Haiku*100/total)
+}
+
+// streaks returns the number of consecutive days up to now on which a haiku was written, and the longest run of
+// consecutive days ever. A streak is still current if it ended yesterday, since today isn't over yet.
+func streaks(days []db.AuthorDay, now time.Time) (current, longest int) {
+	today := now.Unix() / 86400
+	run := 0
+	for i, day := range days {
+		if i > 0 && day.Day == days[i-1].Day+1 {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+	if len(days) > 0 && days[len(days)-1].Day >= today-1 {
+		current = run
+	}
+	return current, longest
+}
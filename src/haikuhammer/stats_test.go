@@ -0,0 +1,71 @@
+package haikuhammer
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	h := newHarness(t)
+	now := time.Date(2021, time.March, 10, 12, 0, 0, 0, time.UTC)
+	h.hammer.now = func() time.Time { return now }
+	gid, cid := h.addGuild("1")
+	h.addMember(gid, "2", "Basho")
+	h.addMember(gid, "3", "Buson")
+
+	emptyID := h.post(gid, cid, "2", "!haiku stats")
+	assert.Equal(t, []string{"Nobody has written a haiku here yet"}, h.replies(emptyID))
+
+	h.post(gid, cid, "3", otherHaiku)
+	h.hammer.now = func() time.Time { return now.AddDate(0, 0, -2) }
+	h.post(gid, cid, "2", testHaiku)
+	h.hammer.now = func() time.Time { return now.AddDate(0, 0, -1) }
+	h.post(gid, cid, "2", "Another test case\nFor the automated bot\nHere’s a new haiku")
+	h.hammer.now = func() time.Time { return now }
+	h.post(gid, cid, "2", testNonHaiku)
+	h.post(gid, cid, "2", "not\nhaiku") // not an attempt, since it doesn't have three lines
+
+	statsID := h.post(gid, cid, "4", "!haiku stats")
+	assert.Equal(t, []string{"3 haiku by 2 authors; 75% of attempts accepted\nTop authors:\n1. Basho - 2 haiku\n2. Buson - 1 haiku"},
+		h.replies(statsID))
+
+	statsID = h.post(gid, cid, "4", "!haiku stats <@!2>")
+	assert.Equal(t, []string{"Basho has written 2 haiku; 66% of attempts accepted\nCurrent streak: 2 days; longest streak: 2 days"},
+		h.replies(statsID))
+
+	resp := h.command(gid, cid, "4", subcommand("stats", &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: "3",
+	}))
+	assert.Equal(t, "Buson has written 1 haiku; 100% of attempts accepted\nCurrent streak: 1 day; longest streak: 1 day", resp.Data.Content)
+
+	resp = h.command(gid, cid, "4", subcommand("stats", &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: "1",
+	}))
+	assert.Equal(t, "owner hasn't written a haiku here yet", resp.Data.Content)
+}
+
+func TestStreaks(t *testing.T) {
+	now := time.Unix(100*86400+3600, 0)
+	tests := []struct {
+		days             []int64
+		current, longest int
+	}{
+		{nil, 0, 0},
+		{[]int64{100}, 1, 1},
+		{[]int64{98, 99}, 2, 2},
+		{[]int64{97, 98}, 0, 2},
+		{[]int64{90, 91, 92, 99, 100}, 2, 3},
+	}
+	for _, tt := range tests {
+		var days []db.AuthorDay
+		for _, d := range tt.days {
+			days = append(days, db.AuthorDay{Day: d})
+		}
+		current, longest := streaks(days, now)
+		assert.Equal(t, tt.current, current, "current streak for %v", tt.days)
+		assert.Equal(t, tt.longest, longest, "longest streak for %v", tt.days)
+	}
+}
@@ -0,0 +1,150 @@
+package haikuhammer
+
+import (
+	"context"
+	"fmt"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// maxReevaluated limits how many messages blocked by a word are checked again once the word is approved.
+const maxReevaluated = 20
+
+// teach queues a user's suggestion for the number of syllables in a word, for review by a moderator.
+func (h *HaikuHammer) teach(guildID string, command Command) string {
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return "I couldn't record your suggestion, please try again later"
+	}
+	err = h.store.SaveSuggestion(context.Background(), db.Suggestion{
+		GuildID:   gid,
+		UserID:    command.SenderID,
+		Word:      command.Word,
+		Syllables: command.Syllables,
+		CreatedAt: h.now().Unix(),
+	})
+	if err != nil {
+		log.Println("could not record suggestion,", err)
+		return "I couldn't record your suggestion, please try again later"
+	}
+	return fmt.Sprintf("Thanks! I'll ask the moderators whether %s has %s", strings.ToLower(command.Word), pluralize(command.Syllables, "syllable"))
+}
+
+// suggestionList lists the suggestions in a guild waiting for review.
+func (h *HaikuHammer) suggestionList(guildID string) string {
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return "I couldn't look up suggestions, please try again later"
+	}
+	suggestions, err := h.store.PendingSuggestions(context.Background(), gid, 10)
+	if err != nil {
+		log.Println("could not list suggestions,", err)
+		return "I couldn't look up suggestions, please try again later"
+	}
+	if len(suggestions) == 0 {
+		return "There are no suggestions waiting for review"
+	}
+	var result strings.Builder
+	result.WriteString("Suggestions waiting for review:")
+	for _, s := range suggestions {
+		result.WriteString(fmt.Sprintf("\n#%d: %s - %s, suggested by %s", s.ID, strings.ToLower(s.Word), pluralize(s.Syllables, "syllable"), h.nick(gid, s.UserID)))
+	}
+	return result.String()
+}
+
+// reviewSuggestion approves or rejects a suggestion. Approved words are added to the guild dictionary, or the global
+// dictionary if the command asks for it, and any recent messages they blocked are checked again.
+func (h *HaikuHammer) reviewSuggestion(guildID string, command Command, approve bool) string {
+	ctx := context.Background()
+	failure := fmt.Sprintf("I couldn't review suggestion #%d, please try again later", command.SuggestionID)
+	gid, err := strconv.Atoi(guildID)
+	if err != nil {
+		log.Println("could not parse guildID as integer,", guildID)
+		return failure
+	}
+	suggestion, err := h.store.FindSuggestion(ctx, gid, command.SuggestionID)
+	if err != nil {
+		log.Println("could not find suggestion,", err)
+		return failure
+	}
+	if suggestion.ID == 0 {
+		return fmt.Sprintf("I couldn't find suggestion #%d", command.SuggestionID)
+	}
+	if approve && command.Global && !containsString(h.config.GlobalModerators, command.SenderID) {
+		return "Only global moderators can add words to the dictionary for every server"
+	}
+	status := db.SuggestionRejected
+	if approve {
+		status = db.SuggestionApproved
+	}
+	reviewed, err := h.store.ReviewSuggestion(ctx, gid, suggestion.ID, status)
+	if err != nil {
+		log.Println("could not review suggestion,", err)
+		return failure
+	}
+	if !reviewed {
+		return fmt.Sprintf("Suggestion #%d has already been reviewed", suggestion.ID)
+	}
+	word := strings.ToLower(suggestion.Word)
+	if !approve {
+		return fmt.Sprintf("Rejected %s", word)
+	}
+
+	scope, where := gid, "this server"
+	if command.Global {
+		scope, where = 0, "every server"
+	}
+	err = h.store.SaveGuildWord(ctx, db.GuildWord{GuildID: scope, Word: suggestion.Word, Syllables: suggestion.Syllables})
+	if err != nil {
+		log.Println("could not add suggested word to dictionary,", err)
+		return failure
+	}
+	accepted := h.reevaluate(scope, suggestion.Word)
+	response := fmt.Sprintf("Approved %s with %s for %s", word, pluralize(suggestion.Syllables, "syllable"), where)
+	if accepted == 1 {
+		response += "; 1 message it blocked is now a haiku"
+	} else if accepted > 1 {
+		response += fmt.Sprintf("; %d messages it blocked are now haiku", accepted)
+	}
+	return response
+}
+
+// reevaluate checks the latest messages blocked by a word again, treating any which are now haiku as if they had just
+// been sent. If guildID is 0, messages in every guild are checked. Returns the number of messages which are now haiku.
+func (h *HaikuHammer) reevaluate(guildID int, word string) int {
+	attempts, err := h.store.BlockedBy(context.Background(), guildID, word, maxReevaluated)
+	if err != nil {
+		log.Println("could not find attempts blocked by word,", err)
+		return 0
+	}
+	accepted := 0
+	for _, a := range attempts {
+		m, err := h.platform.FetchMessage(strconv.Itoa(a.ChannelID), strconv.Itoa(a.MessageID))
+		if err != nil {
+			log.Println("could not look up message from channel", err)
+			continue
+		}
+		m.GuildID = strconv.Itoa(a.GuildID)
+		analysis := h.checker(m).analyse(m.Content)
+		if analysis.Err() != nil {
+			continue
+		}
+		h.removeReaction(m)
+		m.MyReaction = ""
+		h.HandleHaiku(m, analysis)
+		accepted++
+	}
+	return accepted
+}
+
+func parseSuggestionID(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(s, "#"))
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("could not understand '%s' as a suggestion number", s)
+	}
+	return id, nil
+}
@@ -0,0 +1,98 @@
+package haikuhammer
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseCommand_Suggestions(t *testing.T) {
+	command, err := parseCommand("suggestions approve #3 global")
+	assert.NoError(t, err)
+	assert.Equal(t, Command{Operation: OpSuggestionsApprove, SuggestionID: 3, Global: true}, command)
+
+	command, err = parseCommand("teach zxqv 1")
+	assert.NoError(t, err)
+	assert.Equal(t, Command{Operation: OpTeach, Word: "ZXQV", Syllables: 1}, command)
+	assert.False(t, command.RequiresAdmin())
+
+	for _, bad := range []string{"suggestions approve", "suggestions reject x", "teach zxqv"} {
+		_, err = parseCommand(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	h := newHarness(t)
+	gid, cid := h.addGuild("1")
+	h.addMember(gid, "2", "member")
+	h.setGuildFlags(gid, db.ConfigReactToHaiku|db.ConfigReactToNonHaiku|db.ConfigExplainNonHaiku)
+
+	zxqvID := h.post(gid, cid, "2", zxqvHaiku)
+	assert.Equal(t, []string{"🚫"}, h.reactions(zxqvID))
+	if replies := h.replies(zxqvID); assert.Len(t, replies, 1) {
+		assert.Contains(t, replies[0], "- I don't know the words: zxqv\n")
+		assert.Contains(t, replies[0], "you can teach me with `/haiku teach`")
+	}
+
+	teachID := h.post(gid, cid, "2", "!haiku teach zxqv 1")
+	assert.Equal(t, []string{"Thanks! I'll ask the moderators whether zxqv has 1 syllable"}, h.replies(teachID))
+	resp := h.command(gid, cid, "2", subcommand("teach", stringOption("word", "blorbo"), intOption("syllables", 3)))
+	assert.Equal(t, "Thanks! I'll ask the moderators whether blorbo has 3 syllables", resp.Data.Content)
+
+	listID := h.post(gid, cid, "2", "!haiku suggestions list")
+	assert.Empty(t, h.replies(listID), "only admins can review suggestions")
+
+	listID = h.post(gid, cid, "1", "!haiku suggestions list")
+	assert.Equal(t, []string{"Suggestions waiting for review:\n" +
+		"#1: zxqv - 1 syllable, suggested by member\n" +
+		"#2: blorbo - 3 syllables, suggested by member"}, h.replies(listID))
+
+	globalID := h.post(gid, cid, "1", "!haiku suggestions approve 1 global")
+	assert.Equal(t, []string{"Only global moderators can add words to the dictionary for every server"}, h.replies(globalID))
+
+	approveID := h.post(gid, cid, "1", "!haiku suggestions approve 1")
+	assert.Equal(t, []string{"Approved zxqv with 1 syllable for this server; 1 message it blocked is now a haiku"}, h.replies(approveID))
+	assert.Equal(t, []string{"💯"}, h.reactions(zxqvID), "the blocked message is checked again")
+	assert.Equal(t, zxqvHaiku, h.savedHaiku(zxqvID).Content)
+
+	approveID = h.post(gid, cid, "1", "!haiku suggestions approve 1")
+	assert.Equal(t, []string{"Suggestion #1 has already been reviewed"}, h.replies(approveID))
+
+	resp = h.command(gid, cid, "1", suggestionsGroup(subcommand("reject", intOption("number", 2))))
+	assert.Equal(t, "Rejected blorbo", resp.Data.Content)
+	resp = h.command(gid, cid, "1", suggestionsGroup(subcommand("reject", intOption("number", 7))))
+	assert.Equal(t, "I couldn't find suggestion #7", resp.Data.Content)
+
+	listID = h.post(gid, cid, "1", "!haiku suggestions list")
+	assert.Equal(t, []string{"There are no suggestions waiting for review"}, h.replies(listID))
+}
+
+func TestSuggestions_Global(t *testing.T) {
+	h := newHarness(t)
+	h.hammer.config.GlobalModerators = []string{"1"}
+	gid, cid := h.addGuild("1")
+	otherGID, otherCID := h.addGuild("3")
+	h.addMember(gid, "2", "member")
+	h.setGuildFlags(otherGID, db.ConfigReactToHaiku)
+
+	otherID := h.post(otherGID, otherCID, "2", zxqvHaiku)
+	h.post(gid, cid, "2", "!haiku teach zxqv 1")
+
+	resp := h.command(gid, cid, "1", suggestionsGroup(subcommand("approve", intOption("number", 1), boolOption("global", true))))
+	assert.Equal(t, "Approved zxqv with 1 syllable for every server; 1 message it blocked is now a haiku", resp.Data.Content)
+	assert.Equal(t, []string{"💯"}, h.reactions(otherID))
+
+	anotherID := h.post(otherGID, otherCID, "2", zxqvHaiku+"!")
+	assert.Equal(t, []string{"💯"}, h.reactions(anotherID))
+}
+
+func suggestionsGroup(sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: "suggestions", Type: discordgo.ApplicationCommandOptionSubCommandGroup,
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{sub}}
+}
+
+func boolOption(name string, value bool) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionBoolean, Value: value}
+}
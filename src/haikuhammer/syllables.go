@@ -5,89 +5,231 @@ import (
 	"fmt"
 	"github.com/kalexmills/haiku-enforcer/src/dict"
 	"regexp"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// Source describes how the syllables in a word were counted.
+type Source uint8
+
+const (
+	SourceUnknown      Source = iota // the word could not be counted
+	SourceDictionary                 // the word was found in the dictionary
+	SourceSuffix                     // the word was found in the dictionary after removing a suffix
+	SourceAbbreviation               // the word is an abbreviation, and each letter was counted
+	SourceCompound                   // the word was split into several words found in the dictionary
+	SourcePunctuation                // the word contains no letters, so has no syllables
+	SourceEstimate                   // the word isn't in the dictionary, so its syllables were estimated
+	SourcePrefix                     // the word was found in the dictionary after removing a prefix
+	SourceNumber                     // the word is a number, and was counted as it would be read aloud
+	SourceElongated                  // the word was found in the dictionary after collapsing letters repeated for emphasis
+	SourceInterjection               // the word is one of the Interjections
+	SourceGuildDictionary            // the word was added to the dictionary by an admin of the guild
+	SourceLanguage                   // the word was counted only by the rules of its language's spelling
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceDictionary:
+		return "dictionary"
+	case SourceSuffix:
+		return "suffix"
+	case SourcePrefix:
+		return "prefix"
+	case SourceNumber:
+		return "number"
+	case SourceElongated:
+		return "elongated"
+	case SourceInterjection:
+		return "interjection"
+	case SourceGuildDictionary:
+		return "guild dictionary"
+	case SourceLanguage:
+		return "language rules"
+	case SourceAbbreviation:
+		return "abbreviation"
+	case SourceCompound:
+		return "compound"
+	case SourcePunctuation:
+		return "punctuation"
+	case SourceEstimate:
+		return "estimate"
+	default:
+		return "unknown"
+	}
+}
+
+// CountSyllables returns the fewest syllables word could be pronounced with, or false if it could not be counted.
 func CountSyllables(word string) (int, bool) {
+	counts, source := countSyllables(word)
+	if source == SourceUnknown {
+		return 0, false
+	}
+	return counts[0], true
+}
+
+// countSyllables returns every number of syllables a word could be pronounced with, fewest first, or SourceUnknown if
+// it could not be counted.
+func countSyllables(word string) ([]int, Source) {
+	if spoken, ok := readNumber(word); ok {
+		counts, ok := countSpoken(spoken)
+		if ok {
+			return counts, SourceNumber
+		}
+	}
+	if strings.ContainsAny(word, "0123456789") {
+		return countHyphenated(word) // like 9-5; other numbers which couldn't be read aloud, like 25:00, are unknown
+	}
 	cleaned := cleanWord(word)
-	count, ok := countWord(cleaned)
+	counts, source := countWord(cleaned)
+	if source != SourceUnknown {
+		return counts, source
+	}
+	counts, ok := countElongated(cleaned)
 	if ok {
-		return count, true
+		return counts, SourceElongated
+	}
+	counts, source = countHyphenated(word)
+	if source != SourceUnknown {
+		return counts, source
 	}
-	count, ok = countAbbreviation(word)
+	count, ok := countAbbreviation(word)
 	if ok {
-		return count, true
+		return []int{count}, SourceAbbreviation
 	}
-	count, ok = countCompound(cleaned)
+	if cleaned == "" {
+		return []int{0}, SourcePunctuation
+	}
+	counts, ok = countCompound(cleaned)
 	if ok {
-		return count, true
+		return counts, SourceCompound
 	}
-	return 0, false
+	return nil, SourceUnknown
 }
 
-func countWord(cleaned string) (int, bool) {
+func countWord(cleaned string) ([]int, Source) {
 	n := len(cleaned)
 	if n == 0 {
-		return 0, false
+		return nil, SourceUnknown
+	}
+	counts, ok := dict.English.CountSyllables(cleaned)
+	if ok {
+		return addCounts(counts, []int{0}), SourceDictionary
+	}
+	if trimmed := strings.Trim(cleaned, "'"); trimmed != cleaned {
+		return countWord(trimmed) // a word in single quotes, like 'frog'
+	}
+	return countAffixes(cleaned, 0)
+}
+
+// countHyphenated counts a word made of several words joined by hyphens, like "well-known" or "mother-in-law". The
+// whole word is looked up in the dictionary before each part is counted on its own.
+func countHyphenated(word string) ([]int, Source) {
+	parts := strings.Split(strings.Trim(dict.Fold(word), `"'.,;:!?()[]`), "-")
+	if len(parts) < 2 {
+		return nil, SourceUnknown
+	}
+	cleaned := make([]string, len(parts))
+	for i, part := range parts {
+		if part == "" {
+			return nil, SourceUnknown // a dash rather than a hyphen, or a hyphen at the start or end of a word
+		}
+		cleaned[i] = cleanWord(part)
 	}
-	counts, ok := dict.SyllableCounts(cleaned)
-	if ok && len(counts) > 0 {
-		return counts[0], true
-	}
-	// TODO: check prefixes like 'un-' and 're-' and suffixes like 'able'
-	// prefixes: ANTI, UN, RE, SUB, SEMI, PRO, NON
-	// suffixes: Y, ED, S, ING, ABLE
-	if cleaned[n-1] == 'Y' {
-		counts, ok = dict.SyllableCounts(cleaned[:n-1])
-		if ok && len(counts) > 0 {
-			return counts[0] + 1, true
+	counts, ok := dict.English.CountSyllables(strings.Join(cleaned, "-"))
+	if ok {
+		return addCounts(counts, []int{0}), SourceDictionary
+	}
+	result := []int{0}
+	for _, part := range parts {
+		counts, source := countSyllables(part)
+		if source == SourceUnknown {
+			return nil, SourceUnknown
 		}
+		result = addCounts(result, counts)
 	}
-	if cleaned[n-1] == 'S' {
-		counts, ok = dict.SyllableCounts(cleaned[:n-1])
-		if ok && len(counts) > 0 {
-			return counts[0], true
+	return result, SourceCompound
+}
+
+// countSpoken counts the syllables in a number which has been spelled out by readNumber.
+func countSpoken(spoken string) ([]int, bool) {
+	result := []int{0}
+	for _, word := range strings.FieldsFunc(spoken, func(r rune) bool { return r == ' ' || r == '-' }) {
+		counts, source := countSyllables(word)
+		if source == SourceUnknown {
+			return nil, false
 		}
+		result = addCounts(result, counts)
 	}
-	return 0, false
+	return result, true
 }
 
-func countCompound(cleaned string) (int, bool) {
+// countCompound splits a word into words found in the dictionary, preferring the split with the fewest syllables, and
+// returns every number of syllables that split could be pronounced with.
+func countCompound(cleaned string) ([]int, bool) {
 	if len(cleaned) > 1000 {
-		return 0, false // we're just not allowing compound words this long (preventing DoS), sorry!
+		return nil, false // we're just not allowing compound words this long (preventing DoS), sorry!
 	}
+	return countCompoundIn(dict.Current(), cleaned)
+}
+
+// countCompoundIn splits a word using a single dictionary, so the trie and syllable counts always agree even if the
+// dictionary is reloaded.
+func countCompoundIn(d *dict.Dictionary, cleaned string) ([]int, bool) {
 	// recursively crawls a trie, looking for valid break points in the word.
 	// every possible segmentation of cleaned is tested, using the trie helps to end the search early
 	// in case no other words exist, and helps to ensure only valid breakpoints are recursively checked.
 	if cleaned == "" {
-		return 0, true
+		return []int{0}, true
 	}
-	curr := dict.TrieRoot // all words start from the beginning
-	best := 1000
-	for i := 0; i < len(cleaned); i++ {
-		curr = curr.Child(cleaned[i]) // test the next letter against the next character in the trie
+	curr := d.Root() // all words start from the beginning
+	var best []int
+	for i, r := range cleaned {
+		curr = curr.Child(r) // test the next letter against the next character in the trie
 		if curr == nil {
 			break
 		}
 		if curr.IsWord() { // found a prefix that's a word. Count its syllables and start over with the remainder
-			count, _ := dict.SyllableCounts(cleaned[:i+1])
-			rest, ok := countCompound(cleaned[i+1:])
+			end := i + utf8.RuneLen(r)
+			counts, _ := d.SyllableCounts(cleaned[:end])
+			rest, ok := countCompoundIn(d, cleaned[end:])
 			if ok {
-				// we were able to complete the suffix, add its count to the prefix and check to see
+				// we were able to complete the suffix, add its counts to the prefix and check to see
 				// if it's the best breakdown so far. But keep going to test all the other prefixes in
 				// case we have better options.
-				if best > count[0] + rest {
-					best = count[0] + rest
+				total := addCounts(counts, rest)
+				if best == nil || total[0] < best[0] {
+					best = total
 				}
 			}
 		}
 	}
-	if best == 1000 {
-		return 0, false
+	if best == nil {
+		return nil, false
 	}
 	return best, true
 }
 
+// addCounts returns every possible sum of one count from a and one count from b, in ascending order.
+func addCounts(a, b []int) []int {
+	var result []int
+	for _, x := range a {
+		for _, y := range b {
+			result = append(result, x+y)
+		}
+	}
+	sort.Ints(result)
+	deduped := result[:0]
+	for i, count := range result {
+		if i == 0 || count != result[i-1] {
+			deduped = append(deduped, count)
+		}
+	}
+	return deduped
+}
+
 func countAbbreviation(word string) (int, bool) {
 	if !isAbbreviation(word) {
 		return 0, false
@@ -111,16 +253,18 @@ func isAbbreviation(word string) bool {
 	return AbbrevRegex.MatchString(trimmed)
 }
 
+// cleanWord folds a word into the form it's spelled in the dictionary, keeping only its letters and apostrophes.
 func cleanWord(s string) string {
-	replaced := strings.NewReplacer("’","'","‘","'").Replace(s)
-	return strip(strings.ToUpper(replaced))
+	return strip(dict.Fold(s))
 }
 
 func strip(s string) string {
-	return stripBytes(s, func(b byte) bool {
-		return ('a' <= b && b <= 'z') ||
-			('A' <= b && b <= 'Z') || b == '\''
-	})
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || r == '\'' {
+			return r
+		}
+		return -1
+	}, s)
 }
 
 func stripBytes(s string, predicate func(byte) bool) string {
@@ -138,7 +282,6 @@ var AbbrevRegex *regexp.Regexp
 
 func init() {
 	initAbbrevRegex()
-	initEmojiRegex()
 }
 
 func initAbbrevRegex() {
@@ -148,11 +291,3 @@ func initAbbrevRegex() {
 		panic(fmt.Errorf("could not parse regex: %w", err))
 	}
 }
-
-func initEmojiRegex() {
-	var err error
-	EmojiRegex, err = regexp.Compile("\\:.+\\:")
-	if err != nil {
-		panic(fmt.Errorf("could not parse regex: %w", err))
-	}
-}
\ No newline at end of file
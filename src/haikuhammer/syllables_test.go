@@ -44,4 +44,131 @@ func TestCountSyllables(t *testing.T) {
 			assert.Equal(t, tt.expectedCount, count, tt.input)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestCountSyllables_Pronunciations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []int
+		source   Source
+	}{
+		{"fire", []int{1, 2}, SourceDictionary},
+		{"every", []int{2, 3}, SourceDictionary},
+		{"fires", []int{1, 2}, SourceDictionary},
+		{"firey", []int{2, 3}, SourceSuffix},
+		{"everyhour", []int{3, 4, 5}, SourceCompound},
+		{"W.P.A", []int{5}, SourceAbbreviation},
+		{"!!!", []int{0}, SourcePunctuation},
+		{"sadfhgdh", nil, SourceUnknown},
+	}
+
+	for _, tt := range tests {
+		counts, source := countSyllables(tt.input)
+		assert.Equal(t, tt.expected, counts, tt.input)
+		assert.Equal(t, tt.source, source, tt.input)
+	}
+}
+
+func TestCountSyllables_Affixes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []int
+		source   Source
+	}{
+		{"tweeting", []int{2}, SourceSuffix},
+		{"tweeted", []int{2}, SourceSuffix},
+		{"vibing", []int{2}, SourceSuffix},    // dropped e
+		{"vibed", []int{1}, SourceSuffix},     // dropped e, silent ed
+		{"blogged", []int{1}, SourceSuffix},   // doubled consonant
+		{"stanning", []int{2}, SourceSuffix},  // doubled consonant
+		{"spammer", []int{2}, SourceSuffix},   // doubled consonant
+		{"sussy", []int{2}, SourceSuffix},     // doubled consonant
+		{"cringy", []int{2}, SourceSuffix},    // dropped e
+		{"cringiest", []int{3}, SourceSuffix}, // y changed to i
+		{"nerdily", []int{3}, SourceSuffix},   // y changed to i
+		{"glitchiness", []int{3}, SourceSuffix},
+		{"cutely", []int{2}, SourceSuffix},
+		{"chillness", []int{2}, SourceSuffix},
+		{"chillest", []int{2}, SourceSuffix},
+		{"tweeter", []int{2}, SourceSuffix},
+		{"annoyment", []int{3}, SourceSuffix},
+		{"tweetable", []int{3}, SourceSuffix},
+		{"bingeable", []int{3}, SourceSuffix},
+		{"retweet", []int{2}, SourcePrefix},
+		{"unfriend", []int{2}, SourcePrefix},
+		{"preload", []int{2}, SourcePrefix},
+		{"overshare", []int{3}, SourcePrefix},
+		{"retweeted", []int{3}, SourceSuffix},  // prefix and suffix
+		{"unfriended", []int{3}, SourceSuffix}, // ed after d
+		{"unmuted", []int{3}, SourceSuffix},    // ed after t, dropped e
+		{"oversharing", []int{4}, SourceSuffix},
+		{"regifted", []int{3}, SourceSuffix},
+	}
+
+	for _, tt := range tests {
+		counts, source := countSyllables(tt.input)
+		assert.Equal(t, tt.expected, counts, tt.input)
+		assert.Equal(t, tt.source, source, tt.input)
+	}
+}
+
+func TestCountSyllables_Elongated(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []int
+	}{
+		{"sooooo", []int{1}},
+		{"noooooo", []int{1}},
+		{"NOOOOO", []int{1}},
+		{"hmmmm", []int{1}},
+		{"ohhhh", []int{1}},
+		{"ummmm", []int{1}},
+		{"ahhhhh", []int{1}},
+		{"goooood", []int{1}},
+		{"yesssss!", []int{1}},
+		{"sooooo-ooo", []int{1}},
+	}
+	for _, tt := range tests {
+		counts, source := countSyllables(tt.input)
+		assert.Equal(t, tt.expected, counts, tt.input)
+		assert.Equal(t, SourceElongated, source, tt.input)
+	}
+	_, source := countSyllables("zzzzzzzzq")
+	assert.Equal(t, SourceUnknown, source)
+}
+
+func TestCountSyllables_Unicode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []int
+		source   Source
+	}{
+		{"café", []int{2}, SourceDictionary},
+		{"CAFÉ", []int{2}, SourceDictionary},
+		{"naïve", []int{2}, SourceDictionary},
+		{"résumé", []int{2, 3}, SourceDictionary},
+		{"cafe\u0301", []int{2}, SourceDictionary}, // combining acute accent
+		{"ﬁne", []int{1}, SourceDictionary},        // ligature
+		{"ｃａｆｅ", []int{2}, SourceDictionary},       // full-width letters
+		{"don’t", []int{1}, SourceDictionary},
+		{"‘don't’", []int{1}, SourceDictionary},
+		{"encyclopædia", []int{6}, SourceDictionary},
+		{"well-known", []int{2}, SourceDictionary},
+		{"mother-in-law", []int{4}, SourceDictionary},
+		{"frog-pond", []int{2}, SourceCompound},
+		{"café-frog", []int{3}, SourceCompound},
+		{"9-5", []int{2}, SourceCompound},
+		{"—", []int{0}, SourcePunctuation},
+		{"日本", nil, SourceUnknown},
+	}
+	for _, tt := range tests {
+		counts, source := countSyllables(tt.input)
+		assert.Equal(t, tt.expected, counts, tt.input)
+		assert.Equal(t, tt.source, source, tt.input)
+	}
+}
+
+func TestAnalyse_Dashes(t *testing.T) {
+	a := analyse("An old silent pond—\nA frog jumps into the pond–\nSplash! Silence again", FormHaiku)
+	assert.Equal(t, [][]int{{5}, {7}, {5}}, a.Counts())
+}
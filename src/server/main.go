@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"github.com/kalexmills/haiku-enforcer/src/haikuhammer"
 	"github.com/kalexmills/haiku-enforcer/src/haikuhammer/db"
 	"github.com/spf13/viper"
@@ -9,10 +10,18 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
 	conf := readConfig()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := migrate(conf, os.Args[2:])
+		if err != nil {
+			log.Fatalf("could not migrate database: %v", err)
+		}
+		return
+	}
 	hh := haikuhammer.NewHaikuHammer(conf)
 
 	err := hh.Open()
@@ -32,16 +41,61 @@ func main() {
 	}
 }
 
+const migrateUsage = `usage: migrate <command>
+
+commands:
+  status - lists every migration and whether it has been applied to the database
+  up     - applies every migration which hasn't been applied yet`
+
+// migrate handles the migrate subcommand, which shows or updates the version of the database's schema without
+// connecting to Discord.
+func migrate(conf haikuhammer.Config, args []string) error {
+	if len(args) != 1 || (args[0] != "status" && args[0] != "up") {
+		return fmt.Errorf("%s", migrateUsage)
+	}
+	DB, err := haikuhammer.ConnectDB(conf)
+	if err != nil {
+		return err
+	}
+	defer DB.Close()
+
+	if args[0] == "up" {
+		applied, err := db.Migrate(DB)
+		fmt.Printf("applied %d migrations\n", len(applied))
+		return err
+	}
+	migrations, err := db.Migrations(DB)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.Applied() {
+			fmt.Printf("%-30s applied %s\n", m, time.Unix(m.AppliedAt, 0).UTC().Format(time.RFC3339))
+		} else {
+			fmt.Printf("%-30s pending\n", m)
+		}
+	}
+	return nil
+}
+
 func readConfig() haikuhammer.Config {
 	viper.SetDefault("reactHaiku", true)
 	viper.SetDefault("reactNonHaiku", false)
 	viper.SetDefault("deleteNonHaiku", false)
 	viper.SetDefault("explainNonHaiku", true)
 	viper.SetDefault("serveRandomHaiku", true)
+	viper.SetDefault("inferLineBreaks", true)
+	viper.SetDefault("countInterjections", true)
+	viper.SetDefault("replyToCopies", true)
 	viper.SetDefault("positiveReacts", []string{"💯","🍙","🍵","🍶","🍜"})
 	viper.SetDefault("negativeReacts", []string{"🚫","⛔"})
+	viper.SetDefault("quotationReacts", []string{"📜"})
+	viper.SetDefault("dbDriver", "sqlite3")
 	viper.SetDefault("dbPath", "./haikuDB.sqlite3")
 	viper.SetDefault("debug", false)
+	viper.SetDefault("awardPeriods", []string{"week","month","year"})
+	viper.SetDefault("awardInterval", "1h")
+	viper.SetDefault("dictionaryDir", "")
 
 	viper.SetEnvPrefix("HAIKU_HAMMER")
 	viper.AutomaticEnv()
@@ -69,12 +123,35 @@ func readConfig() haikuhammer.Config {
 	if viper.GetBool("serveRandomHaiku") {
 		flags |= db.ConfigServeRandomHaiku
 	}
+	if viper.GetBool("inferLineBreaks") {
+		flags |= db.ConfigInferLineBreaks
+	}
+	if viper.GetBool("countInterjections") {
+		flags |= db.ConfigCountInterjections
+	}
+	if viper.GetBool("replyToCopies") {
+		flags |= db.ConfigReplyToCopies
+	}
+	var periods []haikuhammer.AwardPeriod
+	for _, p := range viper.GetStringSlice("awardPeriods") {
+		period, err := haikuhammer.ParseAwardPeriod(p)
+		if err != nil {
+			log.Fatalf("invalid awardPeriods config: %v", err)
+		}
+		periods = append(periods, period)
+	}
 	return haikuhammer.Config{
 		Token: viper.GetString("token"),
 		ActionFlags: flags,
 		PositiveReacts: viper.GetStringSlice("positiveReacts"),
 		NegativeReacts: viper.GetStringSlice("negativeReacts"),
+		QuotationReacts: viper.GetStringSlice("quotationReacts"),
 		Debug: viper.GetBool("debug"),
+		DBDriver: viper.GetString("dbDriver"),
 		DBPath: viper.GetString("dbPath"),
+		AwardPeriods: periods,
+		AwardInterval: viper.GetDuration("awardInterval"),
+		GlobalModerators: viper.GetStringSlice("globalModerators"),
+		DictionaryDir: viper.GetString("dictionaryDir"),
 	}
 }
\ No newline at end of file